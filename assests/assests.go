@@ -0,0 +1,9 @@
+// Package assests embeds the bundled font, sprite atlas and bot sprite so the
+// binaries run from any directory. Load them through internal/assets, which
+// also honours an --assets override directory.
+package assests
+
+import "embed"
+
+//go:embed font.ttf sprites/atlas.png sprites/bot.jpg
+var Files embed.FS
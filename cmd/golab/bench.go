@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"slices"
+	"strings"
+	"time"
+
+	"golab/internal/config"
+	"golab/internal/game"
+	"golab/internal/render"
+)
+
+const (
+	defaultBenchTicks     = 50
+	defaultBenchTolerance = 0.15
+	// benchRenderPhase names the render scenario's per-frame board render in
+	// its phase table, next to the tick phases.
+	benchRenderPhase = "render"
+)
+
+// benchScenario is one fixed workload of the bench matrix. Scenarios keep
+// their seed and warmup so results stay comparable with a stored baseline.
+type benchScenario struct {
+	Name   string
+	Seed   int64
+	Warmup int
+	// Bots seeds exactly this many bots on a scale board; 0 runs the normal
+	// command initialization with Config.
+	Bots   int
+	Config func(*config.Config)
+	Render bool
+}
+
+var benchScenarios = []benchScenario{
+	{Name: "idle", Seed: 42, Warmup: 5, Bots: 64},
+	{Name: "scale-10k", Seed: 42, Warmup: 10, Bots: 10000},
+	{Name: "scale-50k", Seed: 42, Warmup: 10, Bots: 50000},
+	{Name: "scale-100k", Seed: 42, Warmup: 10, Bots: 100000},
+	// Seed 2 grows the most colonies of the usual seeds by tick 300.
+	{Name: "colony", Seed: 2, Warmup: 300},
+	{Name: "pheromone", Seed: 1, Warmup: 100, Config: func(conf *config.Config) {
+		conf.PheromoneDecayPeriod = 16
+		conf.PheromoneDecay = 1
+		conf.PheromoneDiffusePeriod = 1
+		conf.PheromoneDiffuseAmount = 4
+		conf.PheromoneEventDeposit *= 4
+		conf.PheromoneHomeDeposit *= 4
+		conf.PheromoneBotDeposit *= 4
+	}},
+	{Name: "render", Seed: 1, Warmup: 100, Render: true},
+}
+
+func benchScenarioNames() []string {
+	names := make([]string, len(benchScenarios))
+	for i, s := range benchScenarios {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// tickCost is a per-tick cost, either of a whole run or of one tick phase.
+type tickCost struct {
+	NsPerTick     float64 `json:"ns_per_tick"`
+	AllocsPerTick float64 `json:"allocs_per_tick"`
+	BytesPerTick  float64 `json:"bytes_per_tick"`
+}
+
+type benchScenarioResult struct {
+	Name        string `json:"name"`
+	Seed        int64  `json:"seed"`
+	Ticks       int    `json:"ticks"`
+	WarmupTicks int    `json:"warmup_ticks"`
+	LiveBots    int    `json:"live_bots"`
+	tickCost
+	HeapMB float64             `json:"heap_mb"`
+	Phases map[string]tickCost `json:"phases"`
+}
+
+// benchRegression is one scenario metric that grew past the tolerance.
+type benchRegression struct {
+	Scenario string  `json:"scenario"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	Change   float64 `json:"change"`
+}
+
+type benchResult struct {
+	Command     string                `json:"command"`
+	GoVersion   string                `json:"go_version"`
+	GOOS        string                `json:"goos"`
+	GOARCH      string                `json:"goarch"`
+	Procs       int                   `json:"procs"`
+	Workers     int                   `json:"workers"`
+	Ticks       int                   `json:"ticks"`
+	Scenarios   []benchScenarioResult `json:"scenarios"`
+	Baseline    string                `json:"baseline,omitempty"`
+	Tolerance   float64               `json:"tolerance,omitempty"`
+	Regressions []benchRegression     `json:"regressions,omitempty"`
+	Passed      bool                  `json:"passed"`
+}
+
+func runBench(args []string) {
+	flags := commandFlagSet("bench")
+	scenarios := flags.String("scenarios", strings.Join(benchScenarioNames(), ","), "Comma list of scenarios to run.")
+	ticks := flags.Int("ticks", defaultBenchTicks, "Measured ticks per scenario.")
+	workers := flags.Int("workers", 1, "Bot stepping workers; above 1 uses the parallel stripe scheduler.")
+	baseline := flags.String("baseline", "", "Baseline bench JSON to compare against; regressions exit 1.")
+	tolerance := flags.Float64("tolerance", defaultBenchTolerance, "Allowed relative growth over the baseline before a metric counts as a regression.")
+	writeBaseline := flags.String("write-baseline", "", "Also write the results to this path for later --baseline runs.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "bench [--scenarios idle,scale-10k,...] [--ticks N] [--workers N] [--baseline path] [--tolerance F] [--write-baseline path] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+
+	selected, err := selectBenchScenarios(*scenarios)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	result := benchResult{
+		Command:   "bench",
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		Procs:     runtime.GOMAXPROCS(0),
+		Workers:   max(*workers, 1),
+		Ticks:     normalizePositiveInt(*ticks),
+		Passed:    true,
+	}
+	var base *benchResult
+	if *baseline != "" {
+		if base, err = loadBenchResult(*baseline); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if err := checkBenchBaseline(*base, result); err != nil {
+			fmt.Fprintf(os.Stderr, "bench baseline %s: %v\n", *baseline, err)
+			os.Exit(2)
+		}
+	}
+	for _, scenario := range selected {
+		run, err := runBenchScenario(scenario, result.Ticks, result.Workers)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bench %s: %v\n", scenario.Name, err)
+			os.Exit(1)
+		}
+		result.Scenarios = append(result.Scenarios, run)
+	}
+	if *writeBaseline != "" {
+		if err := writeBenchResult(*writeBaseline, result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if base != nil {
+		result.Baseline = *baseline
+		result.Tolerance = *tolerance
+		result.Regressions = compareBench(*base, result, *tolerance)
+		result.Passed = len(result.Regressions) == 0
+	}
+	printJSON(result, *pretty)
+	if !result.Passed {
+		exitCommand(1)
+	}
+}
+
+func selectBenchScenarios(value string) ([]benchScenario, error) {
+	var selected []benchScenario
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		i := slices.IndexFunc(benchScenarios, func(s benchScenario) bool { return s.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown bench scenario %q: use %s", name, strings.Join(benchScenarioNames(), ", "))
+		}
+		selected = append(selected, benchScenarios[i])
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no bench scenarios selected")
+	}
+	return selected, nil
+}
+
+// runBenchScenario sets the scenario up, warms it up and measures ticks
+// logic ticks with per-phase timers. Scenario costs come from the process
+// allocation counters, so they include work outside runLogicTick such as
+// the render scenario's board render.
+func runBenchScenario(scenario benchScenario, ticks, workers int) (benchScenarioResult, error) {
+	g, err := newBenchGame(scenario)
+	if err != nil {
+		return benchScenarioResult{}, err
+	}
+	g.SetBotWorkers(workers)
+	if scenario.Warmup > 0 {
+		g.RunHeadlessFrames(scenario.Warmup)
+	}
+	renderOpts := render.Options{CellSize: 1, Style: "flat"}
+	runtime.GC()
+	g.EnablePhaseTimers(true)
+
+	var renderNanos int64
+	var renderAllocs, renderBytes uint64
+	var counters benchAllocCounters
+	startAllocs, startBytes := counters.read()
+	start := time.Now()
+	for range ticks {
+		g.RunHeadlessFrames(1)
+		if scenario.Render {
+			allocs, bytes := counters.read()
+			renderStart := time.Now()
+			if _, err := render.RenderBoard(g.Board, renderOpts); err != nil {
+				return benchScenarioResult{}, err
+			}
+			renderNanos += int64(time.Since(renderStart))
+			afterAllocs, afterBytes := counters.read()
+			renderAllocs += afterAllocs - allocs
+			renderBytes += afterBytes - bytes
+		}
+	}
+	elapsed := time.Since(start)
+	endAllocs, endBytes := counters.read()
+	// ReadMemStats stops the world, so it only runs once timing is over.
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	times, _ := g.PhaseTimes()
+	g.DisablePhaseTimers()
+	result := benchScenarioResult{
+		Name:        scenario.Name,
+		Seed:        scenario.Seed,
+		Ticks:       ticks,
+		WarmupTicks: scenario.Warmup,
+		LiveBots:    g.Board.ActiveBotCount(),
+		tickCost:    perTickCost(int64(elapsed), endAllocs-startAllocs, endBytes-startBytes, ticks),
+		HeapMB:      bytesToMB(mem.HeapInuse),
+		Phases:      tickPhaseCosts(times),
+	}
+	if scenario.Render {
+		result.Phases[benchRenderPhase] = perTickCost(renderNanos, renderAllocs, renderBytes, ticks)
+	}
+	return result, nil
+}
+
+// benchAllocCounters reads the runtime's cumulative heap allocation
+// counters through runtime/metrics, which unlike ReadMemStats does not stop
+// the world, so it can run inside the timed loop.
+type benchAllocCounters struct {
+	samples [2]metrics.Sample
+}
+
+func (c *benchAllocCounters) read() (allocs, bytes uint64) {
+	c.samples[0].Name = "/gc/heap/allocs:objects"
+	c.samples[1].Name = "/gc/heap/allocs:bytes"
+	metrics.Read(c.samples[:])
+	return c.samples[0].Value.Uint64(), c.samples[1].Value.Uint64()
+}
+
+func newBenchGame(scenario benchScenario) (*game.Game, error) {
+	if scenario.Bots > 0 {
+		g := newScaleGame(scenario.Seed)
+		if err := g.InitializeForScale(scenario.Bots, scenario.Seed); err != nil {
+			return nil, err
+		}
+		return g, nil
+	}
+	conf := config.NewConfig()
+	if scenario.Config != nil {
+		scenario.Config(&conf)
+	}
+	g := newDeterministicGameWithConfig(scenario.Seed, conf)
+	g.InitializeForCommands()
+	return g, nil
+}
+
+// tickPhaseCosts lists each tick phase's average cost by phase name.
+func tickPhaseCosts(times game.PhaseTimes) map[string]tickCost {
+	costs := make(map[string]tickCost, game.NumTickPhases+1)
+	for phase := range game.NumTickPhases {
+		costs[phase.String()] = perTickCost(times.Nanos[phase], times.Allocs[phase], times.Bytes[phase], times.Ticks)
+	}
+	return costs
+}
+
+func perTickCost(nanos int64, allocs, bytes uint64, ticks int) tickCost {
+	if ticks <= 0 {
+		return tickCost{}
+	}
+	n := float64(ticks)
+	return tickCost{
+		NsPerTick:     float64(nanos) / n,
+		AllocsPerTick: float64(allocs) / n,
+		BytesPerTick:  float64(bytes) / n,
+	}
+}
+
+// compareBench reports every scenario whose ns, allocs or heap grew by more
+// than tolerance over the baseline. Allocation counts get one allocation of
+// slack so a scenario that allocated nothing does not fail on a stray one.
+// Scenarios missing from either side are skipped.
+func compareBench(baseline, current benchResult, tolerance float64) []benchRegression {
+	var regressions []benchRegression
+	for _, cur := range current.Scenarios {
+		i := slices.IndexFunc(baseline.Scenarios, func(s benchScenarioResult) bool { return s.Name == cur.Name })
+		if i < 0 {
+			continue
+		}
+		base := baseline.Scenarios[i]
+		for _, m := range []struct {
+			name           string
+			base, cur, gap float64
+		}{
+			{"ns_per_tick", base.NsPerTick, cur.NsPerTick, 0},
+			{"allocs_per_tick", base.AllocsPerTick, cur.AllocsPerTick, 1},
+			{"heap_mb", base.HeapMB, cur.HeapMB, 0},
+		} {
+			if m.cur <= m.base*(1+tolerance)+m.gap {
+				continue
+			}
+			change := 0.0
+			if m.base > 0 {
+				change = m.cur/m.base - 1
+			}
+			regressions = append(regressions, benchRegression{
+				Scenario: cur.Name,
+				Metric:   m.name,
+				Baseline: m.base,
+				Current:  m.cur,
+				Change:   change,
+			})
+		}
+	}
+	return regressions
+}
+
+// checkBenchBaseline rejects a baseline recorded with a different worker
+// count, GOMAXPROCS, tick count or architecture, since its costs are not
+// comparable with this run's.
+func checkBenchBaseline(baseline, current benchResult) error {
+	var mismatches []string
+	if baseline.Workers != current.Workers {
+		mismatches = append(mismatches, fmt.Sprintf("workers %d, this run %d", baseline.Workers, current.Workers))
+	}
+	if baseline.Procs != current.Procs {
+		mismatches = append(mismatches, fmt.Sprintf("procs %d, this run %d", baseline.Procs, current.Procs))
+	}
+	if baseline.Ticks != current.Ticks {
+		mismatches = append(mismatches, fmt.Sprintf("ticks %d, this run %d", baseline.Ticks, current.Ticks))
+	}
+	if baseline.GOARCH != current.GOARCH {
+		mismatches = append(mismatches, fmt.Sprintf("goarch %s, this run %s", baseline.GOARCH, current.GOARCH))
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("recorded with %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+func loadBenchResult(path string) (*benchResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var result benchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("read bench baseline %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+func writeBenchResult(path string, result benchResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
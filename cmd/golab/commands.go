@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"math/rand"
 	"os"
 	"runtime"
 	"sort"
@@ -12,12 +11,12 @@ import (
 	"strings"
 	"time"
 
+	"golab/internal/assets"
 	"golab/internal/config"
 	"golab/internal/core"
 	"golab/internal/game"
 	"golab/internal/render"
-
-	expRand "golang.org/x/exp/rand"
+	"golab/internal/util"
 )
 
 const (
@@ -31,12 +30,17 @@ const (
 	defaultScaleTargetBots    = 100000
 	defaultScaleTicks         = 300
 	defaultScaleWarmupTicks   = 20
+	// maxSeedRange bounds one lo..hi range in a seed list.
+	maxSeedRange = 100000
 )
 
 func runCommand(args []string) bool {
 	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
 		return false
 	}
+	defer closeCommandMetrics()
+	defer closeCommandEvents()
+	defer closeCommandProfiles()
 
 	switch args[0] {
 	case "status":
@@ -57,6 +61,18 @@ func runCommand(args []string) bool {
 	case "run", "seed-roulette":
 		runSeedRoulette(args[1:])
 		return true
+	case "rerun":
+		runRerun(args[1:])
+		return true
+	case "mutate":
+		runMutate(args[1:])
+		return true
+	case "timeline":
+		runTimeline(args[1:])
+		return true
+	case "sweep-similar":
+		runSweepSimilar(args[1:])
+		return true
 	case "render":
 		runRender(args[1:])
 		return true
@@ -66,6 +82,24 @@ func runCommand(args []string) bool {
 	case "smartness-eval":
 		runSmartnessEval(args[1:])
 		return true
+	case "sweep":
+		runSweep(args[1:])
+		return true
+	case "determinism":
+		runDeterminism(args[1:])
+		return true
+	case "diff-replay":
+		runDiffReplay(args[1:])
+		return true
+	case "bench":
+		runBench(args[1:])
+		return true
+	case "serve":
+		runServe(args[1:])
+		return true
+	case "tui":
+		runTUI(args[1:])
+		return true
 	default:
 		return false
 	}
@@ -84,10 +118,15 @@ func runStatus(args []string) {
 
 	tickCount := normalizeNonNegativeInt(*ticks)
 	topBotsCount := normalizeNonNegativeInt(*topBots)
-	summary := runMatchSummary(*seed, tickCount, topBotsCount)
+	gameRunner := newDeterministicGame(*seed)
+	gameRunner.InitializeForCommands()
+	gameRunner.EnablePhaseTimers(true)
+	gameRunner.RunHeadlessFrames(tickCount)
+	times, _ := gameRunner.PhaseTimes()
 	payload := map[string]any{
-		"command": "status",
-		"summary": summary,
+		"command":     "status",
+		"summary":     summarizeMatch(gameRunner, *seed, tickCount, topBotsCount),
+		"tick_phases": tickPhaseCosts(times),
 	}
 	printJSON(payload, *pretty)
 }
@@ -200,15 +239,16 @@ func runReplay(args []string) {
 	ticks := flags.Int("ticks", defaultReplayTicks, "Simulation ticks to execute.")
 	sampleEvery := flags.Int("sample-every", defaultReplaySampleEvery, "Sample interval for frame output.")
 	topBots := flags.Int("top-bots", defaultTopBots, "Number of top bots to include per frame.")
+	boards := flags.Bool("boards", false, "Store each frame's cell kinds, run-length encoded, for diff-replay images.")
 	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
-	usage := "replay [--seed N] [--ticks T] [--sample-every N] [--top-bots M] [--pretty]"
+	usage := "replay [--seed N] [--ticks T] [--sample-every N] [--top-bots M] [--boards] [--pretty]"
 	if err := parseCommandFlags(flags, args, usage); err != nil {
 		os.Exit(2)
 	}
 
 	tickCount := normalizeNonNegativeInt(*ticks)
 	interval := normalizePositiveInt(*sampleEvery)
-	summary := runReplaySummary(*seed, tickCount, interval, normalizeNonNegativeInt(*topBots))
+	summary := runReplayFrames(*seed, tickCount, interval, normalizeNonNegativeInt(*topBots), *boards)
 	payload := map[string]any{
 		"command":       "replay",
 		"match_id":      fmt.Sprintf("match-%d", *seed),
@@ -289,7 +329,7 @@ type smartnessEvalAggregate struct {
 
 func runSmartnessEval(args []string) {
 	flags := commandFlagSet("smartness-eval")
-	seedsArg := flags.String("seeds", "1 2 3", "Space- or comma-separated deterministic seeds.")
+	seedsArg := flags.String("seeds", "1 2 3", "Space- or comma-separated deterministic seeds; ranges like 1..10 are expanded.")
 	ticks := flags.Int("ticks", defaultSmartnessEvalTicks, "Simulation ticks per seed.")
 	smartEvolution := flags.Bool("smart-evolution", true, "Enable smart evolution during the eval.")
 	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
@@ -306,12 +346,27 @@ func runSmartnessEval(args []string) {
 	}
 
 	tickCount := normalizeNonNegativeInt(*ticks)
+	conf := config.NewConfig()
+	conf.SmartEvolution = *smartEvolution
+	runs := runSmartnessEvalSeeds(seeds, tickCount, conf)
+
+	payload := map[string]any{
+		"command":         "smartness-eval",
+		"ticks":           tickCount,
+		"smart_evolution": *smartEvolution,
+		"runs":            runs,
+		"aggregate":       aggregateSmartnessEval(runs, tickCount),
+	}
+	printJSON(payload, *pretty)
+}
+
+func runSmartnessEvalSeeds(seeds []int64, ticks int, conf config.Config) []smartnessEvalRun {
 	runs := make([]smartnessEvalRun, 0, len(seeds))
 	for _, seed := range seeds {
-		summary := runMatchSummaryWithSmartEvolution(seed, tickCount, 3, *smartEvolution)
+		summary := runMatchSummaryWithConfig(seed, ticks, 3, conf)
 		runs = append(runs, smartnessEvalRun{
 			Seed:                       seed,
-			Ticks:                      tickCount,
+			Ticks:                      ticks,
 			LiveBots:                   summary.LiveBots,
 			SuccessfulDivisions:        summary.SuccessfulDivisions,
 			MaxLineageDepth:            summary.MaxLineageDepth,
@@ -345,15 +400,7 @@ func runSmartnessEval(args []string) {
 			TopNonColonyDirectionShare: summary.TopNonColonyDirectionShare,
 		})
 	}
-
-	payload := map[string]any{
-		"command":         "smartness-eval",
-		"ticks":           tickCount,
-		"smart_evolution": *smartEvolution,
-		"runs":            runs,
-		"aggregate":       aggregateSmartnessEval(runs, tickCount),
-	}
-	printJSON(payload, *pretty)
+	return runs
 }
 
 func aggregateSmartnessEval(runs []smartnessEvalRun, ticks int) smartnessEvalAggregate {
@@ -547,20 +594,102 @@ func runRender(args []string) {
 	seed := flags.Int64("seed", 1, "Deterministic PRNG seed.")
 	ticks := flags.Int("ticks", defaultStatusTicks, "Simulation ticks to execute before rendering.")
 	targetBots := flags.Int("target-bots", 0, "Exact scale-seeded bot count before rendering; 0 uses normal initialization.")
-	output := flags.String("output", "golab-render.png", "PNG output path.")
+	output := flags.String("output", "golab-render.png", "Output path; defaults follow --format.")
 	cellSize := flags.Int("cell-size", 2, "Output pixels per board cell.")
 	padding := flags.Int("padding", 0, "Outer image padding in pixels.")
-	atlasPath := flags.String("atlas", "assests/sprites/atlas.png", "Sprite atlas path.")
-	style := flags.String("style", "game", "Render style: game, atlas, flat, pheromone, biome, density, or colony.")
+	atlasPath := flags.String("atlas", "", "Sprite atlas path; defaults to the --assets pack or the embedded atlas.")
+	assetsDir := flags.String("assets", "", "Texture pack directory laid out like assests/; missing files fall back to the embedded assets.")
+	style := flags.String("style", "game", "Render style: game, atlas, flat, pheromone, biome, density, colony, deaths, heatmap, or territory.")
+	layer := flags.String("layer", "deaths", "Heatmap layer for --style heatmap: births, deaths, kills, raids, pheromone, or occupancy.")
 	border := flags.Bool("border", false, "Draw a border around the board.")
 	legend := flags.Bool("legend", false, "Draw a compact visual legend below the board.")
+	timelapse := flags.Bool("timelapse", false, "Sample frames while the simulation runs and encode an animation.")
+	every := flags.Int("every", defaultTimelapseEvery, "Logic ticks between timelapse frames.")
+	format := flags.String("format", "", "Still format: png or svg; timelapse format: gif (default), apng, or png-seq (output is then a directory).")
+	frameDelay := flags.Duration("frame-delay", 80*time.Millisecond, "Display time per timelapse frame.")
+	maxFrameSize := flags.Int("max-frame-size", 0, "Cap the longer timelapse frame edge in pixels; 0 keeps full size.")
+	palette := flags.Int("palette", 0, "Quantize timelapse frames to N colors (1-256); gif defaults to 256.")
+	compare := flags.String("compare", "", "Render two seeds A,B side by side with a diff panel.")
+	configA := flags.String("config-a", "", "JSON config for the first compared run.")
+	configB := flags.String("config-b", "", "JSON config for the second compared run.")
 	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
-	usage := "render [--seed N] [--ticks N] [--target-bots N] [--output path] [--cell-size N] [--padding N] [--style game|atlas|flat|pheromone|biome|density|colony] [--border=true|false] [--legend=true|false] [--pretty]"
+	usage := "render [--seed N] [--ticks N] [--target-bots N] [--output path] [--cell-size N] [--padding N] [--assets dir] [--atlas path] [--style game|atlas|flat|pheromone|biome|density|colony|deaths|heatmap|territory] [--layer births|deaths|kills|raids|pheromone|occupancy] [--format png|svg] [--border=true|false] [--legend=true|false] [--timelapse --every N --format gif|apng|png-seq --frame-delay D --max-frame-size N --palette N] [--compare A,B] [--config-a path] [--config-b path] [--pretty]"
 	if err := parseCommandFlags(flags, args, usage); err != nil {
 		os.Exit(2)
 	}
+	outputSet := false
+	flags.Visit(func(f *flag.Flag) {
+		outputSet = outputSet || f.Name == "output"
+	})
+	stillFormat := *format
+	if stillFormat == "" {
+		stillFormat = "png"
+	}
+	if !*timelapse && stillFormat != "png" && stillFormat != "svg" {
+		fmt.Fprintf(os.Stderr, "unknown render format %q: use png or svg, or --timelapse for gif, apng, or png-seq\n", *format)
+		os.Exit(2)
+	}
+
+	heatLayer, err := core.ParseHeatLayer(*layer)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := assets.SetDir(*assetsDir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
+	renderOpts := render.Options{
+		AtlasPath:          *atlasPath,
+		Output:             *output,
+		CellSize:           normalizePositiveInt(*cellSize),
+		Padding:            normalizeNonNegativeInt(*padding),
+		Border:             *border,
+		Legend:             *legend,
+		Style:              *style,
+		HeatLayer:          heatLayer,
+		RenderPaths:        true,
+		RenderTaskTargets:  true,
+		RenderUnreachables: true,
+	}
 	tickCount := normalizeNonNegativeInt(*ticks)
+	if compareRequested(*compare, *configA, *configB) {
+		if *timelapse || stillFormat != "png" || *targetBots > 0 {
+			fmt.Fprintln(os.Stderr, "--compare and --config-a/--config-b render a still PNG without --timelapse or --target-bots")
+			os.Exit(2)
+		}
+		runs, err := resolveCompareRuns(*seed, *compare, *configA, *configB)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if !outputSet {
+			renderOpts.Output = "golab-compare.png"
+		}
+		result, err := renderComparison(runs, tickCount, renderOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		payload := map[string]any{
+			"command":    "render",
+			"ticks":      tickCount,
+			"runs":       runs,
+			"output":     result.Output,
+			"width":      result.Width,
+			"height":     result.Height,
+			"cell_size":  renderOpts.CellSize,
+			"style":      *style,
+			"format":     "png",
+			"diff_cells": result.DiffCells,
+		}
+		if *style == "heatmap" {
+			payload["layer"] = heatLayer.String()
+		}
+		printJSON(payload, *pretty)
+		return
+	}
 	gameRunner := newDeterministicGame(*seed)
 	target := normalizeNonNegativeInt(*targetBots)
 	if target > 0 {
@@ -572,20 +701,57 @@ func runRender(args []string) {
 	} else {
 		gameRunner.InitializeForCommands()
 	}
+	if *style == "heatmap" {
+		gameRunner.EnableHeatmaps()
+	}
+	if *timelapse {
+		timelapseFormat, err := render.ParseTimelapseFormat(*format)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if !outputSet {
+			renderOpts.Output = defaultTimelapseOutput(timelapseFormat)
+		}
+		result, err := runTimelapse(gameRunner, tickCount, normalizePositiveInt(*every), render.TimelapseOptions{
+			Options:      renderOpts,
+			Format:       timelapseFormat,
+			FrameDelay:   *frameDelay,
+			MaxFrameSize: normalizeNonNegativeInt(*maxFrameSize),
+			PaletteSize:  *palette,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		printJSON(map[string]any{
+			"command":     "render",
+			"seed":        *seed,
+			"ticks":       tickCount,
+			"target_bots": target,
+			"output":      result.Output,
+			"width":       result.Width,
+			"height":      result.Height,
+			"cell_size":   renderOpts.CellSize,
+			"style":       *style,
+			"timelapse":   true,
+			"format":      result.Format,
+			"frames":      result.Frames,
+			"every":       normalizePositiveInt(*every),
+		}, *pretty)
+		return
+	}
 	gameRunner.RunHeadlessFrames(tickCount)
 
-	result, err := render.SaveBoardPNG(gameRunner.Board, render.Options{
-		AtlasPath:          *atlasPath,
-		Output:             *output,
-		CellSize:           normalizePositiveInt(*cellSize),
-		Padding:            normalizeNonNegativeInt(*padding),
-		Border:             *border,
-		Legend:             *legend,
-		Style:              *style,
-		RenderPaths:        true,
-		RenderTaskTargets:  true,
-		RenderUnreachables: true,
-	})
+	var result render.Result
+	if stillFormat == "svg" {
+		if !outputSet {
+			renderOpts.Output = "golab-render.svg"
+		}
+		result, err = render.SaveBoardSVG(gameRunner.Board, render.SVGOptions{Options: renderOpts, ColonyID: gameRunner.ColonyID})
+	} else {
+		result, err = render.SaveBoardPNG(gameRunner.Board, renderOpts)
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -601,6 +767,10 @@ func runRender(args []string) {
 		"height":      result.Height,
 		"cell_size":   normalizePositiveInt(*cellSize),
 		"style":       *style,
+		"format":      stillFormat,
+	}
+	if *style == "heatmap" {
+		payload["layer"] = heatLayer.String()
 	}
 	printJSON(payload, *pretty)
 }
@@ -615,12 +785,15 @@ type scaleTestResult struct {
 	FinalLiveBots       int     `json:"final_live_bots"`
 	Ticks               int     `json:"ticks"`
 	WarmupTicks         int     `json:"warmup_ticks,omitempty"`
+	Workers             int     `json:"workers"`
 	ElapsedMS           int64   `json:"elapsed_ms"`
 	LogicTicksPerSecond float64 `json:"logic_ticks_per_second"`
 	BotStepsPerSecond   float64 `json:"bot_steps_per_second"`
 	HeapMB              float64 `json:"heap_mb"`
 	AllocMB             float64 `json:"alloc_mb"`
 	GCCount             uint32  `json:"gc_count"`
+	// TickPhases is the cost per measured tick of each phase of the tick.
+	TickPhases map[string]tickCost `json:"tick_phases"`
 }
 
 func runScaleTest(args []string) {
@@ -629,8 +802,9 @@ func runScaleTest(args []string) {
 	targetBots := flags.Int("target-bots", defaultScaleTargetBots, "Exact number of bots to seed.")
 	ticks := flags.Int("ticks", defaultScaleTicks, "Measured simulation ticks to execute.")
 	warmupTicks := flags.Int("warmup-ticks", defaultScaleWarmupTicks, "Warmup ticks before measuring.")
+	workers := flags.Int("workers", 1, "Bot stepping workers; above 1 uses the parallel stripe scheduler.")
 	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
-	usage := "scale-test [--seed N] [--target-bots N] [--ticks N] [--warmup-ticks N] [--pretty]"
+	usage := "scale-test [--seed N] [--target-bots N] [--ticks N] [--warmup-ticks N] [--workers N] [--pretty]"
 	if err := parseCommandFlags(flags, args, usage); err != nil {
 		os.Exit(2)
 	}
@@ -640,6 +814,7 @@ func runScaleTest(args []string) {
 	warmup := normalizeNonNegativeInt(*warmupTicks)
 
 	gameRunner := newScaleGame(*seed)
+	gameRunner.SetBotWorkers(*workers)
 	if err := gameRunner.InitializeForScale(target, *seed); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -654,11 +829,13 @@ func runScaleTest(args []string) {
 		gameRunner.RunHeadlessFrames(warmup)
 	}
 	runtime.GC()
+	gameRunner.EnablePhaseTimers(true)
 
 	start := time.Now()
 	if tickCount > 0 {
 		gameRunner.RunHeadlessFrames(tickCount)
 	}
+	times, _ := gameRunner.PhaseTimes()
 	elapsed := time.Since(start)
 	if elapsed <= 0 {
 		elapsed = time.Nanosecond
@@ -677,12 +854,14 @@ func runScaleTest(args []string) {
 		FinalLiveBots:       gameRunner.Board.ActiveBotCount(),
 		Ticks:               tickCount,
 		WarmupTicks:         warmup,
+		Workers:             gameRunner.BotWorkers(),
 		ElapsedMS:           elapsed.Milliseconds(),
 		LogicTicksPerSecond: float64(tickCount) / seconds,
 		BotStepsPerSecond:   float64(initialLive*tickCount) / seconds,
 		HeapMB:              bytesToMB(mem.HeapInuse),
 		AllocMB:             bytesToMB(mem.Alloc),
 		GCCount:             mem.NumGC,
+		TickPhases:          tickPhaseCosts(times),
 	}
 	printJSON(result, *pretty)
 }
@@ -729,27 +908,14 @@ func runSeedRoulette(args []string) {
 	winner := winningBot(summary.TopBots)
 	winnerHP := winnerValue(winner)
 
-	winnerDesc := "none"
-	if winner != nil {
-		winnerDesc = fmt.Sprintf("Bot #%d — HP %d, F %d O %d", winner.Index, winner.Hp, winner.FoodInventory, winner.OreInventory)
-	}
-
-	card := discordCard{
-		Title:       fmt.Sprintf("🎰 Seed Roulette — Match #%d", matchSeed),
-		Description: fmt.Sprintf("Rolled seed **%d** (%s). Simulated %d ticks.", matchSeed, rngSource, tickCount),
-		Color:       0x5865F2,
-		Fields: []discordField{
-			{Name: "Seed", Value: fmt.Sprintf("%d", matchSeed), Inline: true},
-			{Name: "Ticks", Value: fmt.Sprintf("%d", tickCount), Inline: true},
-			{Name: "Live Bots", Value: fmt.Sprintf("%d", summary.LiveBots), Inline: true},
-			{Name: "Colonies", Value: fmt.Sprintf("%d", summary.ColonyCount), Inline: true},
-			{Name: "Winner Score", Value: fmt.Sprintf("%d", winnerHP), Inline: true},
-			{Name: "Winner", Value: winnerDesc, Inline: false},
-		},
-		Footer: map[string]any{"text": fmt.Sprintf("bots-arena • %s", time.Now().UTC().Format(time.RFC3339))},
-	}
+	card := matchCard(
+		fmt.Sprintf("🎰 Seed Roulette — Match #%d", matchSeed),
+		fmt.Sprintf("Rolled seed **%d** (%s). Simulated %d ticks.", matchSeed, rngSource, tickCount),
+		matchSeed,
+		tickCount,
+		summary,
+	)
 
-	actions := []string{"rerun", "mutate", "timeline", "sweep-similar"}
 	verdict := seedRouletteVerdict(summary)
 
 	payload := map[string]any{
@@ -762,16 +928,46 @@ func runSeedRoulette(args []string) {
 		"winner_hp": winnerHP,
 		"card":      card,
 		"verdict":   verdict,
-		"actions":   actions,
+		"actions":   seedRouletteActions,
 	}
 	printJSON(payload, *pretty)
 }
 
+func matchCard(title, description string, seed int64, ticks int, summary matchSummary) discordCard {
+	winner := winningBot(summary.TopBots)
+	winnerDesc := "none"
+	if winner != nil {
+		winnerDesc = fmt.Sprintf("Bot #%d — HP %d, F %d O %d", winner.Index, winner.Hp, winner.FoodInventory, winner.OreInventory)
+	}
+	return discordCard{
+		Title:       title,
+		Description: description,
+		Color:       0x5865F2,
+		Fields: []discordField{
+			{Name: "Seed", Value: fmt.Sprintf("%d", seed), Inline: true},
+			{Name: "Ticks", Value: fmt.Sprintf("%d", ticks), Inline: true},
+			{Name: "Live Bots", Value: fmt.Sprintf("%d", summary.LiveBots), Inline: true},
+			{Name: "Colonies", Value: fmt.Sprintf("%d", summary.ColonyCount), Inline: true},
+			{Name: "Winner Score", Value: fmt.Sprintf("%d", winnerValue(winner)), Inline: true},
+			{Name: "Winner", Value: winnerDesc, Inline: false},
+		},
+		Footer: cardFooter(),
+	}
+}
+
+func cardFooter() map[string]any {
+	return map[string]any{"text": fmt.Sprintf("bots-arena • %s", time.Now().UTC().Format(time.RFC3339))}
+}
+
 func parseCommandFlags(flags *flag.FlagSet, args []string, usage string) error {
 	flags.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: bots-arena %s\n", usage)
 		flags.PrintDefaults()
 	}
+	metrics := registerMetricsFlags(flags)
+	events := registerEventFlags(flags)
+	registerInvariantFlags(flags)
+	profiles := registerProfileFlags(flags)
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
@@ -779,6 +975,18 @@ func parseCommandFlags(flags *flag.FlagSet, args []string, usage string) error {
 		flags.Usage()
 		return fmt.Errorf("unexpected positional arguments: %v", flags.Args())
 	}
+	if err := metrics.open(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return err
+	}
+	if err := openCommandEvents(*events); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return err
+	}
+	if err := profiles.start(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return err
+	}
 	return nil
 }
 
@@ -815,6 +1023,28 @@ func parseSeedList(value string) ([]int64, error) {
 	})
 	seeds := make([]int64, 0, len(fields))
 	for _, field := range fields {
+		if from, to, ok := strings.Cut(field, ".."); ok {
+			first, err := strconv.ParseInt(from, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid seed range %q: %w", field, err)
+			}
+			last, err := strconv.ParseInt(to, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid seed range %q: %w", field, err)
+			}
+			if last < first {
+				return nil, fmt.Errorf("invalid seed range %q: end is before start", field)
+			}
+			if uint64(last-first) >= maxSeedRange {
+				return nil, fmt.Errorf("invalid seed range %q: more than %d seeds", field, maxSeedRange)
+			}
+			// Count up rather than compare against last, which would loop
+			// forever once last is MaxInt64.
+			for n := int64(0); n <= last-first; n++ {
+				seeds = append(seeds, first+n)
+			}
+			continue
+		}
 		seed, err := strconv.ParseInt(field, 10, 64)
 		if err != nil {
 			return nil, fmt.Errorf("invalid seed %q: %w", field, err)
@@ -893,70 +1123,76 @@ type botSummary struct {
 }
 
 type matchSummary struct {
-	Command                    string       `json:"command"`
-	Seed                       int64        `json:"seed"`
-	Ticks                      int          `json:"ticks"`
-	Timestamp                  string       `json:"timestamp"`
-	LiveBots                   int          `json:"live_bots"`
-	ColonyCount                int          `json:"colony_count"`
-	Controller                 int          `json:"controllers"`
-	FarmCount                  int          `json:"farms"`
-	Spawners                   int          `json:"spawners"`
-	SpawnerBirths              int          `json:"spawner_births"`
-	TotalSpawnerCharges        int          `json:"total_spawner_charges"`
-	Mines                      int          `json:"mines"`
-	Buildings                  int          `json:"buildings"`
-	Depots                     int          `json:"depots"`
-	TotalDepotFood             int          `json:"total_depot_food"`
-	TotalDepotOre              int          `json:"total_depot_ore"`
-	Food                       int          `json:"food"`
-	Resources                  int          `json:"resources"`
-	Poison                     int          `json:"poison"`
-	Organics                   int          `json:"organics"`
-	Water                      int          `json:"water"`
-	Wall                       int          `json:"wall"`
-	TotalHP                    int          `json:"total_bot_hp"`
-	TotalInv                   int          `json:"total_bot_inventory"`
-	TotalFoodInv               int          `json:"total_food_inventory"`
-	TotalOreInv                int          `json:"total_ore_inventory"`
-	TotalColonyFoodBank        int          `json:"total_colony_food_bank"`
-	TotalColonyOreBank         int          `json:"total_colony_ore_bank"`
-	ColonyMemberBots           int          `json:"colony_member_bots"`
-	ConnectedColonyBots        int          `json:"connected_colony_bots"`
-	ActiveColonies             int          `json:"active_colonies"`
-	SoloActiveColonies         int          `json:"solo_active_colonies"`
-	MaxColonyMembers           int          `json:"max_colony_members"`
-	MaxConnectedMembers        int          `json:"max_connected_members"`
-	MaxColonyComponent         int          `json:"max_colony_component"`
-	MaxConnectedComponent      int          `json:"max_connected_component"`
-	LongestColonyRun           int          `json:"longest_colony_run"`
-	LongestConnectedRun        int          `json:"longest_connected_run"`
-	ColonyTissueCells          int          `json:"colony_tissue_cells"`
-	FriendlyAdjacencies        int          `json:"friendly_adjacencies"`
-	ForeignAdjacencies         int          `json:"foreign_adjacencies"`
-	PheromoneActiveCells       int          `json:"pheromone_active_cells"`
-	TotalFoodPheromone         int          `json:"total_food_pheromone"`
-	TotalOrePheromone          int          `json:"total_ore_pheromone"`
-	TotalHomePheromone         int          `json:"total_home_pheromone"`
-	TotalDangerPheromone       int          `json:"total_danger_pheromone"`
-	DivisionReadyBots          int          `json:"division_ready_bots"`
-	SuccessfulDivisions        int          `json:"successful_divisions"`
-	LivingBotDivisions         int          `json:"living_bot_divisions"`
-	MaxLineageDepth            int          `json:"max_lineage_depth"`
-	FoodGathered               int          `json:"food_gathered"`
-	OreGathered                int          `json:"ore_gathered"`
-	StolenFood                 int          `json:"stolen_food"`
-	StolenOre                  int          `json:"stolen_ore"`
-	CombatKills                int          `json:"combat_kills"`
-	ControllerRaids            int          `json:"controller_raids"`
-	DepotRaids                 int          `json:"depot_raids"`
-	EliteCount                 int          `json:"elite_count"`
-	BestScore                  int          `json:"best_score"`
-	TopColonyLinkedBots        int          `json:"top_colony_linked_bots"`
-	TopActiveColonyBots        int          `json:"top_active_colony_bots"`
-	TopSpawnerActiveBots       int          `json:"top_spawner_active_bots"`
-	TopNonColonyDirectionShare float64      `json:"top_non_colony_direction_share"`
-	TopBots                    []botSummary `json:"top_bots"`
+	Command                    string               `json:"command"`
+	Seed                       int64                `json:"seed"`
+	Ticks                      int                  `json:"ticks"`
+	Timestamp                  string               `json:"timestamp"`
+	LiveBots                   int                  `json:"live_bots"`
+	ColonyCount                int                  `json:"colony_count"`
+	Controller                 int                  `json:"controllers"`
+	FarmCount                  int                  `json:"farms"`
+	Spawners                   int                  `json:"spawners"`
+	SpawnerBirths              int                  `json:"spawner_births"`
+	TotalSpawnerCharges        int                  `json:"total_spawner_charges"`
+	Mines                      int                  `json:"mines"`
+	Buildings                  int                  `json:"buildings"`
+	Depots                     int                  `json:"depots"`
+	TotalDepotFood             int                  `json:"total_depot_food"`
+	TotalDepotOre              int                  `json:"total_depot_ore"`
+	Food                       int                  `json:"food"`
+	Resources                  int                  `json:"resources"`
+	Poison                     int                  `json:"poison"`
+	Organics                   int                  `json:"organics"`
+	Water                      int                  `json:"water"`
+	Wall                       int                  `json:"wall"`
+	TotalHP                    int                  `json:"total_bot_hp"`
+	TotalInv                   int                  `json:"total_bot_inventory"`
+	TotalFoodInv               int                  `json:"total_food_inventory"`
+	TotalOreInv                int                  `json:"total_ore_inventory"`
+	TotalColonyFoodBank        int                  `json:"total_colony_food_bank"`
+	TotalColonyOreBank         int                  `json:"total_colony_ore_bank"`
+	ColonyMemberBots           int                  `json:"colony_member_bots"`
+	ConnectedColonyBots        int                  `json:"connected_colony_bots"`
+	ActiveColonies             int                  `json:"active_colonies"`
+	SoloActiveColonies         int                  `json:"solo_active_colonies"`
+	MaxColonyMembers           int                  `json:"max_colony_members"`
+	MaxConnectedMembers        int                  `json:"max_connected_members"`
+	MaxColonyComponent         int                  `json:"max_colony_component"`
+	MaxConnectedComponent      int                  `json:"max_connected_component"`
+	LongestColonyRun           int                  `json:"longest_colony_run"`
+	LongestConnectedRun        int                  `json:"longest_connected_run"`
+	ColonyTissueCells          int                  `json:"colony_tissue_cells"`
+	FriendlyAdjacencies        int                  `json:"friendly_adjacencies"`
+	ForeignAdjacencies         int                  `json:"foreign_adjacencies"`
+	PheromoneActiveCells       int                  `json:"pheromone_active_cells"`
+	TotalFoodPheromone         int                  `json:"total_food_pheromone"`
+	TotalOrePheromone          int                  `json:"total_ore_pheromone"`
+	TotalHomePheromone         int                  `json:"total_home_pheromone"`
+	TotalDangerPheromone       int                  `json:"total_danger_pheromone"`
+	DivisionReadyBots          int                  `json:"division_ready_bots"`
+	SuccessfulDivisions        int                  `json:"successful_divisions"`
+	LivingBotDivisions         int                  `json:"living_bot_divisions"`
+	MaxLineageDepth            int                  `json:"max_lineage_depth"`
+	FoodGathered               int                  `json:"food_gathered"`
+	OreGathered                int                  `json:"ore_gathered"`
+	StolenFood                 int                  `json:"stolen_food"`
+	StolenOre                  int                  `json:"stolen_ore"`
+	CombatKills                int                  `json:"combat_kills"`
+	ControllerRaids            int                  `json:"controller_raids"`
+	DepotRaids                 int                  `json:"depot_raids"`
+	Deaths                     int                  `json:"deaths"`
+	DeathsByCause              map[string]int       `json:"deaths_by_cause"`
+	ColonyDeaths               []colonyDeathSummary `json:"colony_deaths"`
+	ColonyTerritory            []colonyTerritory    `json:"colony_territory"`
+	UnclaimedArea              int                  `json:"unclaimed_area"`
+	EliteCount                 int                  `json:"elite_count"`
+	BestScore                  int                  `json:"best_score"`
+	TopColonyLinkedBots        int                  `json:"top_colony_linked_bots"`
+	TopActiveColonyBots        int                  `json:"top_active_colony_bots"`
+	TopSpawnerActiveBots       int                  `json:"top_spawner_active_bots"`
+	TopNonColonyDirectionShare float64              `json:"top_non_colony_direction_share"`
+	TopBots                    []botSummary         `json:"top_bots"`
+	Board                      string               `json:"board,omitempty"`
 }
 
 func runMatchSummary(seed int64, ticks, topBots int) matchSummary {
@@ -964,7 +1200,13 @@ func runMatchSummary(seed int64, ticks, topBots int) matchSummary {
 }
 
 func runMatchSummaryWithSmartEvolution(seed int64, ticks, topBots int, smartEvolution bool) matchSummary {
-	gameRunner := newDeterministicGameWithSmartEvolution(seed, smartEvolution)
+	conf := config.NewConfig()
+	conf.SmartEvolution = smartEvolution
+	return runMatchSummaryWithConfig(seed, ticks, topBots, conf)
+}
+
+func runMatchSummaryWithConfig(seed int64, ticks, topBots int, conf config.Config) matchSummary {
+	gameRunner := newDeterministicGameWithConfig(seed, conf)
 	tickCount := normalizeNonNegativeInt(ticks)
 	gameRunner.InitializeForCommands()
 	gameRunner.RunHeadlessFrames(tickCount)
@@ -972,16 +1214,27 @@ func runMatchSummaryWithSmartEvolution(seed int64, ticks, topBots int, smartEvol
 }
 
 func runReplaySummary(seed int64, ticks, sampleEvery, topBots int) []matchSummary {
+	return runReplayFrames(seed, ticks, sampleEvery, topBots, false)
+}
+
+// runReplayFrames samples a summary every sampleEvery ticks; with boards set
+// each frame also carries the encoded cell kinds.
+func runReplayFrames(seed int64, ticks, sampleEvery, topBots int, boards bool) []matchSummary {
 	gameRunner := newDeterministicGame(seed)
 	gameRunner.InitializeForCommands()
 
-	frames := []matchSummary{
-		summarizeMatch(gameRunner, seed, 0, topBots),
+	frame := func(tick int) matchSummary {
+		summary := summarizeMatch(gameRunner, seed, tick, topBots)
+		if boards {
+			summary.Board = encodeBoardKinds(gameRunner.Board)
+		}
+		return summary
 	}
+	frames := []matchSummary{frame(0)}
 	for tick := 1; tick <= ticks; tick++ {
 		gameRunner.RunHeadlessFrames(1)
 		if tick%sampleEvery == 0 || tick == ticks {
-			frames = append(frames, summarizeMatch(gameRunner, seed, tick, topBots))
+			frames = append(frames, frame(tick))
 		}
 	}
 	return frames
@@ -993,11 +1246,27 @@ func newDeterministicGame(seed int64) *game.Game {
 
 func newDeterministicGameWithSmartEvolution(seed int64, smartEvolution bool) *game.Game {
 	conf := config.NewConfig()
-	conf.LogicStep = 0
 	conf.SmartEvolution = smartEvolution
-	rand.Seed(seed)
-	expRand.Seed(uint64(seed))
-	return game.NewGame(&conf)
+	return newDeterministicGameWithConfig(seed, conf)
+}
+
+func newDeterministicGameWithConfig(seed int64, conf config.Config) *game.Game {
+	conf.LogicStep = 0
+	g := newSeededGame(seed, &conf)
+	attachCommandMetrics(g, seed)
+	attachCommandEvents(g, seed)
+	attachCommandInvariants(g, seed)
+	return g
+}
+
+// newSeededGame builds a game that owns a PRNG state seeded with seed, so
+// its run depends on the seed alone even next to other games.
+func newSeededGame(seed int64, conf *config.Config) *game.Game {
+	random := util.NewRandom(seed)
+	util.UseRandom(random)
+	g := game.NewGame(conf)
+	g.SetRandom(random)
+	return g
 }
 
 func newScaleGame(seed int64) *game.Game {
@@ -1007,17 +1276,116 @@ func newScaleGame(seed int64) *game.Game {
 	conf.ImmigrationBots = 0
 	conf.ImmigrationInterval = 0
 	conf.SmartEvolution = false
-	rand.Seed(seed)
-	expRand.Seed(uint64(seed))
-	return game.NewGame(&conf)
+	g := newSeededGame(seed, &conf)
+	attachCommandMetrics(g, seed)
+	attachCommandEvents(g, seed)
+	attachCommandInvariants(g, seed)
+	return g
+}
+
+// colonyDeathSummary breaks deaths down for one colony. ColonyID is the
+// Game.ColonyID also used by top_bots, events and SVG data-colony, and is
+// null once the colony has left the board.
+type colonyDeathSummary struct {
+	ColonyID *int           `json:"colony_id"`
+	X        int            `json:"x"`
+	Y        int            `json:"y"`
+	Members  int            `json:"members"`
+	Total    int            `json:"total"`
+	Causes   map[string]int `json:"causes"`
+}
+
+func deathCauseCounts(counts map[core.DeathCause]int) map[string]int {
+	out := make(map[string]int, len(core.DeathCauses()))
+	for _, cause := range core.DeathCauses() {
+		out[cause.String()] = counts[cause]
+	}
+	return out
 }
 
-func registerColonyID(colonyIDByRef map[*core.Colony]int, colony *core.Colony) {
+func summarizeColonyDeaths(g *game.Game, colonyIDByRef map[*core.Colony]int) []colonyDeathSummary {
+	out := []colonyDeathSummary{}
+	for colony, counts := range g.ColonyDeathsByCause() {
+		entry := colonyDeathSummary{
+			X:       colony.Center.C,
+			Y:       colony.Center.R,
+			Members: len(colony.Members),
+			Causes:  deathCauseCounts(counts),
+		}
+		if id, ok := colonyIDByRef[colony]; ok {
+			idCopy := id
+			entry.ColonyID = &idCopy
+		}
+		for _, count := range counts {
+			entry.Total += count
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		left, right := out[i], out[j]
+		if left.Total != right.Total {
+			return left.Total > right.Total
+		}
+		if (left.ColonyID == nil) != (right.ColonyID == nil) {
+			return left.ColonyID != nil
+		}
+		if left.ColonyID != nil && *left.ColonyID != *right.ColonyID {
+			return *left.ColonyID < *right.ColonyID
+		}
+		if left.Y != right.Y {
+			return left.Y < right.Y
+		}
+		return left.X < right.X
+	})
+	return out
+}
+
+// colonyTerritory reports the cells one controlled colony holds on the
+// influence map drawn by render --style territory.
+type colonyTerritory struct {
+	ColonyID *int    `json:"colony_id"`
+	X        int     `json:"x"`
+	Y        int     `json:"y"`
+	Members  int     `json:"members"`
+	Area     int     `json:"area"`
+	Share    float64 `json:"share"`
+}
+
+func summarizeTerritory(brd *core.Board, colonyIDByRef map[*core.Colony]int) ([]colonyTerritory, int) {
+	territory := brd.Territory()
+	out := make([]colonyTerritory, 0, len(territory.Colonies))
+	cells := core.Rows * core.Cols
+	unclaimed := cells
+	for i, colony := range territory.Colonies {
+		entry := colonyTerritory{
+			X:       colony.Center.C,
+			Y:       colony.Center.R,
+			Members: len(colony.Members),
+			Area:    territory.Areas[i],
+			Share:   float64(territory.Areas[i]) / float64(cells),
+		}
+		if id, ok := colonyIDByRef[colony]; ok {
+			idCopy := id
+			entry.ColonyID = &idCopy
+		}
+		unclaimed -= entry.Area
+		out = append(out, entry)
+	}
+	// Territory lists colonies in board scan order, which breaks area ties.
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Area > out[j].Area
+	})
+	return out, unclaimed
+}
+
+// registerColonyID records colony as present on the board under its
+// Game.ColonyID, the same ID events and SVG data-colony attributes use.
+func registerColonyID(g *game.Game, colonyIDByRef map[*core.Colony]int, colony *core.Colony) {
 	if colony == nil {
 		return
 	}
 	if _, ok := colonyIDByRef[colony]; !ok {
-		colonyIDByRef[colony] = len(colonyIDByRef)
+		colonyIDByRef[colony] = g.ColonyID(colony)
 	}
 }
 
@@ -1031,30 +1399,21 @@ func summarizeMatch(g *game.Game, seed int64, tick int, topBots int) matchSummar
 
 	colonyIDByRef := map[*core.Colony]int{}
 	for _, colony := range g.Colonies {
-		registerColonyID(colonyIDByRef, colony)
-	}
-	for _, cell := range *g.Board.GetGrid() {
-		switch v := cell.(type) {
-		case core.Controller:
-			registerColonyID(colonyIDByRef, v.Colony)
-		case *core.Controller:
-			registerColonyID(colonyIDByRef, v.Colony)
-		case core.Depot:
-			registerColonyID(colonyIDByRef, v.Colony)
-		case *core.Depot:
-			if v != nil {
-				registerColonyID(colonyIDByRef, v.Colony)
-			}
-		case core.Farm:
-			registerColonyID(colonyIDByRef, colonyForSummaryOwnedCell(v.Colony, v.Owner))
-		case core.Spawner:
-			registerColonyID(colonyIDByRef, colonyForSummaryOwnedCell(v.Colony, v.Owner))
+		registerColonyID(g, colonyIDByRef, colony)
+	}
+	for i, kind := range g.Board.Kinds() {
+		switch kind {
+		case core.CellController, core.CellDepot:
+			registerColonyID(g, colonyIDByRef, g.Board.CellAtIdx(i).Colony)
+		case core.CellFarm, core.CellSpawner:
+			cell := g.Board.CellAtIdx(i)
+			registerColonyID(g, colonyIDByRef, colonyForSummaryOwnedCell(cell.Colony, cell.Owner))
 		}
 	}
 	for _, id := range g.Board.ActiveBotIDs() {
 		bot := g.Board.BotByID(id)
 		if bot != nil {
-			registerColonyID(colonyIDByRef, bot.Colony)
+			registerColonyID(g, colonyIDByRef, bot.Colony)
 		}
 	}
 	summary.ColonyCount = len(colonyIDByRef)
@@ -1161,6 +1520,10 @@ func summarizeMatch(g *game.Game, seed int64, tick int, topBots int) matchSummar
 	summary.ControllerRaids = g.ControllerRaids()
 	summary.DepotRaids = g.DepotRaids()
 	summary.SpawnerBirths = g.SpawnerBirths()
+	summary.Deaths = g.Deaths()
+	summary.DeathsByCause = deathCauseCounts(g.DeathsByCause())
+	summary.ColonyDeaths = summarizeColonyDeaths(g, colonyIDByRef)
+	summary.ColonyTerritory, summary.UnclaimedArea = summarizeTerritory(g.Board, colonyIDByRef)
 	summary.EliteCount = g.EliteCount()
 	summary.BestScore = g.BestEvolutionScore()
 	summary.TopBots = topSelector.Top()
@@ -1189,40 +1552,35 @@ func summarizeMatch(g *game.Game, seed int64, tick int, topBots int) matchSummar
 	summary.ColonyTissueCells = colonyTissueCells(g.Board)
 	summary.TopNonColonyDirectionShare = topNonColonyDirectionShare(g.Board)
 
-	for _, cell := range *g.Board.GetGrid() {
-		switch v := cell.(type) {
-		case core.Wall:
+	for i, kind := range g.Board.Kinds() {
+		switch kind {
+		case core.CellWall:
 			summary.Wall++
-		case core.Controller:
+		case core.CellController:
 			summary.Controller++
-		case core.Farm:
+		case core.CellFarm:
 			summary.FarmCount++
-		case core.Spawner:
+		case core.CellSpawner:
 			summary.Spawners++
-			summary.TotalSpawnerCharges += max(0, v.Amount)
-		case core.Mine:
+			summary.TotalSpawnerCharges += max(0, g.Board.CellAtIdx(i).Amount)
+		case core.CellMine:
 			summary.Mines++
-		case core.Poison:
+		case core.CellPoison:
 			summary.Poison++
-		case core.Organics:
+		case core.CellOrganics:
 			summary.Organics++
-		case core.Food:
+		case core.CellFood:
 			summary.Food++
-		case core.Resource:
+		case core.CellResource:
 			summary.Resources++
-		case core.Building:
+		case core.CellBuilding:
 			summary.Buildings++
-		case core.Depot:
+		case core.CellDepot:
+			depot := g.Board.CellAtIdx(i)
 			summary.Depots++
-			summary.TotalDepotFood += v.Food
-			summary.TotalDepotOre += v.Ore
-		case *core.Depot:
-			if v != nil {
-				summary.Depots++
-				summary.TotalDepotFood += v.Food
-				summary.TotalDepotOre += v.Ore
-			}
-		case core.Water:
+			summary.TotalDepotFood += depot.Amount
+			summary.TotalDepotOre += depot.Extra
+		case core.CellWater:
 			summary.Water++
 		}
 	}
@@ -1242,33 +1600,22 @@ func activeColonySizes(brd *core.Board) colonySizeSummary {
 		return colonySizeSummary{}
 	}
 	active := map[*core.Colony]struct{}{}
-	for idx, cell := range *brd.GetGrid() {
-		switch ctrl := cell.(type) {
-		case core.Controller:
-			if ctrl.Colony != nil {
-				active[ctrl.Colony] = struct{}{}
-			}
-		case *core.Controller:
-			if ctrl != nil && ctrl.Colony != nil {
-				active[ctrl.Colony] = struct{}{}
-			}
-		case core.Depot:
-			if ctrl.Colony != nil {
-				active[ctrl.Colony] = struct{}{}
-			}
-		case *core.Depot:
-			if ctrl != nil && ctrl.Colony != nil {
-				active[ctrl.Colony] = struct{}{}
+	for idx, kind := range brd.Kinds() {
+		switch kind {
+		case core.CellController, core.CellDepot:
+			if colony := brd.CellAtIdx(idx).Colony; colony != nil {
+				active[colony] = struct{}{}
 			}
-		case core.Farm:
-			if colony := colonyForSummaryOwnedCell(ctrl.Colony, ctrl.Owner); colony != nil {
+		case core.CellFarm:
+			cell := brd.CellAtIdx(idx)
+			if colony := colonyForSummaryOwnedCell(cell.Colony, cell.Owner); colony != nil {
 				active[colony] = struct{}{}
 			}
-		case core.Spawner:
-			if colony := colonyForSummaryOwner(ctrl.Owner); colony != nil {
+		case core.CellSpawner:
+			if colony := colonyForSummaryOwner(brd.CellAtIdx(idx).Owner); colony != nil {
 				active[colony] = struct{}{}
 			}
-		case core.ColonyFlag:
+		case core.CellFlag:
 			if colony := brd.PheromoneHomeOwnerAt(core.Position{R: idx / core.Cols, C: idx % core.Cols}); colony != nil {
 				active[colony] = struct{}{}
 			}
@@ -1406,24 +1753,15 @@ func visibleColonyAt(brd *core.Board, pos core.Position, connectedOnly bool) *co
 		}
 		return bot.Colony
 	}
-	switch cell := brd.At(pos).(type) {
-	case core.Controller:
-		return cell.Colony
-	case *core.Controller:
-		if cell != nil {
-			return cell.Colony
-		}
-	case core.Depot:
-		return cell.Colony
-	case *core.Depot:
-		if cell != nil {
-			return cell.Colony
-		}
-	case core.Farm:
+	switch brd.KindAt(pos) {
+	case core.CellController, core.CellDepot:
+		return brd.CellAtIdx(util.Idx(pos)).Colony
+	case core.CellFarm:
+		cell := brd.CellAtIdx(util.Idx(pos))
 		return colonyForSummaryOwnedCell(cell.Colony, cell.Owner)
-	case core.Spawner:
-		return colonyForSummaryOwner(cell.Owner)
-	case core.ColonyFlag:
+	case core.CellSpawner:
+		return colonyForSummaryOwner(brd.CellAtIdx(util.Idx(pos)).Owner)
+	case core.CellFlag:
 		return brd.PheromoneHomeOwnerAt(pos)
 	}
 	if brd.PheromoneAt(pos).Home > 0 {
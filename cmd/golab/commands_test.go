@@ -1,15 +1,34 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"golab/internal/config"
 	"golab/internal/core"
 	"golab/internal/game"
+	"golab/internal/render"
+	"golab/internal/ui"
 	"golab/internal/util"
+	"image/color"
+	"image/png"
 	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 var benchmarkSummary matchSummary
@@ -299,6 +318,189 @@ func TestReplaySummarySameSeedIsDeterministic(t *testing.T) {
 	}
 }
 
+func TestCheckDeterminismComparesRunsPerSchedule(t *testing.T) {
+	result := checkDeterminism(3, 15, 3, []int{1, 2}, []int{1, 1, 2})
+	if !result.Deterministic || len(result.Runs) != 3 {
+		t.Fatalf("determinism result = %+v, want three matching runs", result)
+	}
+	if got := result.Runs[1]; got.Procs != 2 || got.Schedule != "serial" || got.Baseline != 0 || got.FinalHash != result.Runs[0].FinalHash {
+		t.Fatalf("second run = %+v, want a serial rerun matching run 0", got)
+	}
+	if got := result.Runs[2]; got.Schedule != "parallel" || got.Baseline != 2 || got.Diverged {
+		t.Fatalf("parallel run = %+v, want its own baseline", got)
+	}
+}
+
+func TestDiffReplaysFindsFirstDivergentTickAndRanksMetrics(t *testing.T) {
+	frame := func(tick int, bots, colonies float64, deaths map[string]any) map[string]any {
+		return map[string]any{
+			"command": "replay", "seed": float64(tick), "ticks": float64(tick), "timestamp": "now",
+			"live_bots": bots, "colony_count": colonies, "deaths_by_cause": deaths,
+			"top_bots": []any{map[string]any{"hp": bots}},
+		}
+	}
+	a := replayFile{Seed: 1, Frames: []map[string]any{
+		frame(0, 100, 1, map[string]any{"age": 0.0}),
+		frame(5, 110, 1, map[string]any{"age": 1.0}),
+		frame(10, 120, 2, map[string]any{"age": 2.0}),
+	}}
+	b := replayFile{Seed: 2, Frames: []map[string]any{
+		frame(0, 100, 1, map[string]any{"age": 0.0}),
+		frame(10, 130, 4, map[string]any{"age": 2.0, "poison": 3.0}),
+		frame(15, 140, 4, map[string]any{"age": 2.0}),
+	}}
+
+	result := diffReplays(a, b, 2)
+	if !result.Diverged || result.FirstDivergentTick == nil || *result.FirstDivergentTick != 10 {
+		t.Fatalf("first divergent tick = %v, want 10 (tick 5 is only in a)", result.FirstDivergentTick)
+	}
+	if result.AlignedTicks != 2 || result.FinalTick != 10 {
+		t.Fatalf("aligned %d ticks ending at %d, want 2 ending at 10", result.AlignedTicks, result.FinalTick)
+	}
+	var names []string
+	for _, diff := range result.DivergentMetrics {
+		names = append(names, diff.Metric)
+	}
+	if !slices.Equal(names, []string{"deaths_by_cause.poison", "colony_count"}) {
+		t.Fatalf("divergent metrics = %v, want the new cause then the doubled colony count", names)
+	}
+	if same := diffReplays(a, a, 5); same.Diverged || len(same.FinalMetrics) != 0 {
+		t.Fatalf("self diff = %+v, want no divergence", same)
+	}
+}
+
+func TestBoardKindEncodingRoundTrips(t *testing.T) {
+	g := newDeterministicGame(4)
+	g.InitializeForCommands()
+	g.RunHeadlessFrames(10)
+
+	encoded := encodeBoardKinds(g.Board)
+	decoded, err := decodeBoardKinds(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(decoded.Kinds(), g.Board.Kinds()) {
+		t.Fatal("decoded board kinds differ from the encoded board")
+	}
+	if decoded.ActiveBotCount() != g.Board.ActiveBotCount() {
+		t.Fatalf("decoded bots = %d, want %d", decoded.ActiveBotCount(), g.Board.ActiveBotCount())
+	}
+	for _, bad := range []string{"", "a5", "z", encoded + "a", "a99999999999999999999", "a9223372036854775807", "a0" + encoded} {
+		if _, err := decodeBoardKinds(bad); err == nil {
+			t.Fatalf("decodeBoardKinds(%.24q) succeeded", bad)
+		}
+	}
+
+	// Without boards, two replays of one seed would be resimulated by this
+	// build into identical boards, so the image is refused.
+	frames := []map[string]any{{"ticks": float64(10)}}
+	_, err = renderReplayDiff(replayFile{Path: "a.json", Seed: 4, Frames: frames}, replayFile{Path: "b.json", Seed: 4, Frames: frames}, 10,
+		render.Options{Output: filepath.Join(t.TempDir(), "diff.png")})
+	if err == nil || !strings.Contains(err.Error(), "--boards") {
+		t.Fatalf("same-seed diff without boards = %v, want an error naming --boards", err)
+	}
+}
+
+func TestParseInvariantIntervalAcceptsBareAndEveryForms(t *testing.T) {
+	for value, want := range map[string]int{"": 1, "true": 1, "false": 0, "10": 10, "every 10": 10, "every=4": 4} {
+		if got, err := parseInvariantInterval(value); err != nil || got != want {
+			t.Errorf("parseInvariantInterval(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"often", "-3", "every"} {
+		if _, err := parseInvariantInterval(value); err == nil {
+			t.Errorf("parseInvariantInterval(%q) succeeded", value)
+		}
+	}
+}
+
+func TestCompareBenchFlagsGrowthPastTolerance(t *testing.T) {
+	scenario := func(name string, ns, allocs, heap float64) benchScenarioResult {
+		return benchScenarioResult{Name: name, tickCost: tickCost{NsPerTick: ns, AllocsPerTick: allocs}, HeapMB: heap}
+	}
+	baseline := benchResult{Scenarios: []benchScenarioResult{
+		scenario("idle", 1000, 0, 10),
+		scenario("colony", 1000, 100, 10),
+	}}
+	current := benchResult{Scenarios: []benchScenarioResult{
+		scenario("idle", 1100, 1, 10),
+		scenario("colony", 1300, 100, 12),
+		scenario("render", 9999, 9999, 99),
+	}}
+
+	got := compareBench(baseline, current, 0.15)
+	if len(got) != 2 {
+		t.Fatalf("regressions = %+v, want colony ns and heap only", got)
+	}
+	if got[0].Scenario != "colony" || got[0].Metric != "ns_per_tick" || math.Abs(got[0].Change-0.3) > 1e-9 {
+		t.Fatalf("first regression = %+v, want colony ns_per_tick up 30%%", got[0])
+	}
+	if got[1].Metric != "heap_mb" {
+		t.Fatalf("second regression = %+v, want heap_mb", got[1])
+	}
+	if got := compareBench(baseline, current, 0.5); len(got) != 0 {
+		t.Fatalf("regressions at 50%% tolerance = %+v, want none", got)
+	}
+
+	header := benchResult{GOARCH: "amd64", Procs: 8, Workers: 1, Ticks: 50}
+	if err := checkBenchBaseline(header, header); err != nil {
+		t.Fatalf("checkBenchBaseline rejected a matching baseline: %v", err)
+	}
+	mismatched := header
+	mismatched.Workers, mismatched.GOARCH = 4, "arm64"
+	if err := checkBenchBaseline(mismatched, header); err == nil || !strings.Contains(err.Error(), "workers 4") || !strings.Contains(err.Error(), "goarch arm64") {
+		t.Fatalf("checkBenchBaseline(mismatched) = %v, want workers and goarch named", err)
+	}
+
+	if _, err := selectBenchScenarios("idle, nope"); err == nil {
+		t.Fatalf("selectBenchScenarios accepted an unknown scenario")
+	}
+	selected, err := selectBenchScenarios("idle")
+	if err != nil || len(selected) != 1 {
+		t.Fatalf("selectBenchScenarios(idle) = %v, %v", selected, err)
+	}
+	run, err := runBenchScenario(selected[0], 3, 1)
+	if err != nil || run.Ticks != 3 || run.LiveBots == 0 || run.NsPerTick <= 0 {
+		t.Fatalf("idle run = %+v, %v", run, err)
+	}
+	if _, ok := run.Phases["bots"]; !ok || len(run.Phases) != int(game.NumTickPhases) {
+		t.Fatalf("idle phases = %v, want one entry per tick phase", run.Phases)
+	}
+}
+
+func TestProfileFlagsWriteCPUHeapAndTraceFiles(t *testing.T) {
+	dir := t.TempDir()
+	paths := map[string]string{
+		"cpuprofile": filepath.Join(dir, "cpu.pprof"),
+		"memprofile": filepath.Join(dir, "heap.pprof"),
+		"trace":      filepath.Join(dir, "run.trace"),
+	}
+	flags := commandFlagSet("profile-test")
+	profiles := registerProfileFlags(flags)
+	var args []string
+	for name, path := range paths {
+		args = append(args, "--"+name, path)
+	}
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := profiles.start(); err != nil {
+		t.Fatal(err)
+	}
+	newDeterministicGame(1).RunHeadlessFrames(2)
+	closeCommandProfiles()
+
+	for name, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 {
+			t.Errorf("--%s wrote %v, %v; want a non-empty file", name, info, err)
+		}
+	}
+	if commandProfiles != (commandProfileFiles{}) {
+		t.Fatalf("profiles still open after close: %+v", commandProfiles)
+	}
+}
+
 func clearSummaryTimestamps(frames []matchSummary) {
 	for i := range frames {
 		frames[i].Timestamp = ""
@@ -482,3 +684,605 @@ func newSummaryBenchmarkGame() *game.Game {
 	}
 	return g
 }
+
+func TestParseMatchIDAcceptsPrefixedAndBareSeeds(t *testing.T) {
+	for input, want := range map[string]int64{"match-42": 42, "42": 42, " Match-7 ": 7, "match--3": -3} {
+		got, err := parseMatchID(input)
+		if err != nil || got != want {
+			t.Fatalf("parseMatchID(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "match-", "match-x"} {
+		if _, err := parseMatchID(input); err == nil {
+			t.Fatalf("parseMatchID(%q) should fail", input)
+		}
+	}
+}
+
+func TestMutateConfigIsDeterministicAndBounded(t *testing.T) {
+	first, firstMutations := mutateConfig(config.NewConfig(), 11, 25)
+	second, secondMutations := mutateConfig(config.NewConfig(), 11, 25)
+	if !reflect.DeepEqual(first, second) || !reflect.DeepEqual(firstMutations, secondMutations) {
+		t.Fatalf("same mutation seed produced different configs: %+v vs %+v", firstMutations, secondMutations)
+	}
+	for _, mutation := range firstMutations {
+		got, ok := first.IntField(mutation.Field)
+		if !ok || got != mutation.To {
+			t.Fatalf("mutation %+v not applied, field = %d", mutation, got)
+		}
+		if limit := mutation.From / 4; mutation.To < mutation.From-limit || mutation.To > mutation.From+limit {
+			t.Fatalf("mutation %+v exceeds 25%% strength", mutation)
+		}
+	}
+
+	unchanged, mutations := mutateConfig(config.NewConfig(), 11, 0)
+	if len(mutations) != 0 || !reflect.DeepEqual(unchanged, config.NewConfig()) {
+		t.Fatalf("zero-strength mutation changed config: %+v", mutations)
+	}
+}
+
+func TestRouletteActionsEmitChainableCards(t *testing.T) {
+	type actionPayload struct {
+		Command string      `json:"command"`
+		MatchID string      `json:"match_id"`
+		Seed    int64       `json:"seed"`
+		Card    discordCard `json:"card"`
+		Actions []string    `json:"actions"`
+	}
+	runs := map[string]func([]string){
+		"rerun":         runRerun,
+		"mutate":        runMutate,
+		"timeline":      runTimeline,
+		"sweep-similar": runSweepSimilar,
+	}
+	for name, run := range runs {
+		args := []string{"--match-id", "match-5", "--ticks", "3"}
+		if name == "sweep-similar" {
+			args = append(args, "--radius", "1")
+		}
+		output := captureStdout(t, func() { run(args) })
+
+		var payload actionPayload
+		if err := json.Unmarshal([]byte(output), &payload); err != nil {
+			t.Fatalf("parse %s JSON: %v\noutput:\n%s", name, err, output)
+		}
+		if payload.Command != name || payload.MatchID != "match-5" || payload.Seed != 5 {
+			t.Fatalf("%s header = %+v", name, payload)
+		}
+		if payload.Card.Title == "" || payload.Card.Footer["text"] == nil {
+			t.Fatalf("%s card = %+v, want title and footer", name, payload.Card)
+		}
+		if !reflect.DeepEqual(payload.Actions, seedRouletteActions) {
+			t.Fatalf("%s actions = %v, want %v", name, payload.Actions, seedRouletteActions)
+		}
+	}
+}
+
+func TestParseSeedListExpandsRanges(t *testing.T) {
+	seeds, err := parseSeedList("1..3, 7")
+	if err != nil {
+		t.Fatalf("parse seeds: %v", err)
+	}
+	if want := []int64{1, 2, 3, 7}; !reflect.DeepEqual(seeds, want) {
+		t.Fatalf("seeds = %v, want %v", seeds, want)
+	}
+	if _, err := parseSeedList("5..2"); err == nil {
+		t.Fatalf("descending seed range should fail")
+	}
+	for _, huge := range []string{"1..1000000", "-9223372036854775808..9223372036854775807"} {
+		if _, err := parseSeedList(huge); err == nil {
+			t.Fatalf("parseSeedList(%q) should refuse the range", huge)
+		}
+	}
+
+	// A range ending at MaxInt64 must stop there instead of wrapping.
+	seeds, err = parseSeedList("9223372036854775806..9223372036854775807")
+	if want := []int64{math.MaxInt64 - 1, math.MaxInt64}; err != nil || !reflect.DeepEqual(seeds, want) {
+		t.Fatalf("seeds ending at MaxInt64 = %v %v, want %v", seeds, err, want)
+	}
+}
+
+func TestSweepCombinationsExpandGridAndSample(t *testing.T) {
+	params, err := parseSweepParams([]string{"colonySpawnerBirthPeriod=20,40,80", "pheromoneDecay=1..4"})
+	if err != nil {
+		t.Fatalf("parse params: %v", err)
+	}
+	if len(params) != 2 || !reflect.DeepEqual(params[1].Values, []int{1, 2, 3, 4}) {
+		t.Fatalf("params = %+v", params)
+	}
+
+	grid, mode, err := sweepCombinations(params, 0, 1)
+	if err != nil || mode != "grid" || len(grid) != 12 {
+		t.Fatalf("grid = %d combos mode %q err %v, want 12 grid", len(grid), mode, err)
+	}
+	if grid[0]["colonySpawnerBirthPeriod"] != 20 || grid[0]["pheromoneDecay"] != 1 ||
+		grid[11]["colonySpawnerBirthPeriod"] != 80 || grid[11]["pheromoneDecay"] != 4 {
+		t.Fatalf("grid endpoints = %v / %v", grid[0], grid[11])
+	}
+
+	sampled, mode, err := sweepCombinations(params, 5, 9)
+	again, _, _ := sweepCombinations(params, 5, 9)
+	if err != nil || mode != "random" || len(sampled) != 5 || !reflect.DeepEqual(sampled, again) {
+		t.Fatalf("sampled = %v mode %q err %v, want 5 deterministic random combos", sampled, mode, err)
+	}
+
+	// Four 4096-value ranges overflow any grid index; sampling must refuse
+	// them instead of sampling from a wrapped total.
+	wide := []sweepParam{}
+	for _, name := range []string{"hpThreshold", "oceansCount", "mutationRate", "botChance"} {
+		values, err := parseSweepValues("1..4096")
+		if err != nil {
+			t.Fatalf("parse 1..4096: %v", err)
+		}
+		wide = append(wide, sweepParam{Name: name, Values: values})
+	}
+	for _, samples := range []int{0, 2} {
+		if combos, _, err := sweepCombinations(wide, samples, 1); err == nil {
+			t.Fatalf("sweepCombinations over a 4096^4 grid with %d samples = %d combos, want an error", samples, len(combos))
+		}
+	}
+	if sampled, mode, err := sweepCombinations(wide[:2], 2, 1); err != nil || mode != "random" || len(sampled) != 2 {
+		t.Fatalf("sampled 4096^2 grid = %v %q %v, want 2 random combos", sampled, mode, err)
+	}
+
+	maxRange := strconv.Itoa(math.MaxInt-1) + ".." + strconv.Itoa(math.MaxInt)
+	if values, err := parseSweepValues(maxRange); err != nil || !reflect.DeepEqual(values, []int{math.MaxInt - 1, math.MaxInt}) {
+		t.Fatalf("parseSweepValues(%q) = %v %v, want the two values", maxRange, values, err)
+	}
+
+	for _, bad := range []string{"missing=1", "pheromonesEnabled=1", "pheromoneDecay", "pheromoneDecay=4..1", "pheromoneDecay=1..200000"} {
+		if _, err := parseSweepParams([]string{bad}); err == nil {
+			t.Fatalf("parseSweepParams(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBestSweepResultHonorsObjectiveDirection(t *testing.T) {
+	results := []sweepResult{
+		{Params: map[string]int{"a": 1}, Objective: 3},
+		{Params: map[string]int{"a": 2}, Objective: 9},
+		{Params: map[string]int{"a": 3}, Objective: 1},
+	}
+	if best := bestSweepResult(results, false); best == nil || best.Params["a"] != 2 {
+		t.Fatalf("max best = %+v, want a=2", best)
+	}
+	if best := bestSweepResult(results, true); best == nil || best.Params["a"] != 3 {
+		t.Fatalf("min best = %+v, want a=3", best)
+	}
+	if err := validateSweepObjective("median_best_score"); err != nil {
+		t.Fatalf("median_best_score should be a valid objective: %v", err)
+	}
+}
+
+func TestMetricsOutStreamsRowsFromAnyCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.ndjson")
+	captureStdout(t, func() {
+		runCommand([]string{"status", "--seed", "3", "--ticks", "6", "--metrics-out", path, "--metrics-every", "2"})
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read metrics: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("metrics rows = %d, want 3:\n%s", len(lines), data)
+	}
+	for i, line := range lines {
+		var sample game.MetricsSample
+		if err := json.Unmarshal([]byte(line), &sample); err != nil {
+			t.Fatalf("parse metrics row %d: %v", i, err)
+		}
+		if sample.Seed != 3 || sample.Tick != (i+1)*2 || sample.LiveBots == 0 {
+			t.Fatalf("metrics row %d = %+v, want seed 3 tick %d with live bots", i, sample, (i+1)*2)
+		}
+	}
+	if commandMetrics != nil {
+		t.Fatalf("commandMetrics left open after command")
+	}
+}
+
+func TestEventsFlagWritesNDJSONLabeledBySeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	captureStdout(t, func() {
+		runCommand([]string{"status", "--seed", "4", "--ticks", "40", "--events", path})
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		t.Fatalf("events file is empty")
+	}
+	for i, line := range lines {
+		var event game.Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("parse event %d: %v", i, err)
+		}
+		if event.Seed != 4 || event.Kind == "" || event.Tick <= 0 {
+			t.Fatalf("event %d = %+v, want seed 4 with kind and tick", i, event)
+		}
+	}
+	if commandEvents != nil {
+		t.Fatalf("commandEvents left open after command")
+	}
+}
+
+func TestMatchSummaryDeathCausesSumToDeaths(t *testing.T) {
+	summary := runMatchSummary(9, 150, 0)
+
+	total := 0
+	for cause, count := range summary.DeathsByCause {
+		if cause == "none" {
+			t.Fatalf("deaths_by_cause includes placeholder cause: %+v", summary.DeathsByCause)
+		}
+		total += count
+	}
+	if total != summary.Deaths {
+		t.Fatalf("deaths_by_cause sum = %d, want deaths %d", total, summary.Deaths)
+	}
+	if len(summary.DeathsByCause) != len(core.DeathCauses()) {
+		t.Fatalf("deaths_by_cause keys = %v, want every cause", summary.DeathsByCause)
+	}
+	colonyTotal := 0
+	for _, colony := range summary.ColonyDeaths {
+		colonyTotal += colony.Total
+	}
+	if colonyTotal > summary.Deaths {
+		t.Fatalf("colony death total = %d exceeds deaths %d", colonyTotal, summary.Deaths)
+	}
+}
+
+func TestServeStreamsKeyframeAndPatchesThatRebuildTheBoard(t *testing.T) {
+	g := newDeterministicGame(7)
+	g.InitializeForCommands()
+	viewer := newBoardViewer(g, 7, 2)
+	server := httptest.NewServer(viewer.handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), "<canvas") {
+		t.Fatalf("index page missing canvas: %.80q", page)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	events := bufio.NewReader(stream.Body)
+	next := func(want string) map[string]any {
+		t.Helper()
+		for {
+			name, data := readSSE(t, events)
+			if name != want {
+				continue
+			}
+			var payload map[string]any
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				t.Fatal(err)
+			}
+			return payload
+		}
+	}
+
+	key := next("key")
+	frame, _ := base64.StdEncoding.DecodeString(key["rgb"].(string))
+	if len(frame) != 3*util.Cells {
+		t.Fatalf("keyframe has %d bytes, want %d", len(frame), 3*util.Cells)
+	}
+	for range 5 {
+		viewer.advance()
+		patch, _ := base64.StdEncoding.DecodeString(next("patch")["cells"].(string))
+		for i := 0; i+7 <= len(patch); i += 7 {
+			cellIdx := int(binary.LittleEndian.Uint32(patch[i:]))
+			copy(frame[3*cellIdx:3*cellIdx+3], patch[i+4:i+7])
+		}
+	}
+	viewer.mu.Lock()
+	want := slices.Clone(viewer.frame)
+	viewer.mu.Unlock()
+	if !slices.Equal(frame, want) {
+		t.Fatal("keyframe plus patches diverged from the server frame")
+	}
+	for cellIdx := range util.Cells {
+		rgb := cellRGB(g.Board, cellIdx, ui.RenderModeNormal)
+		if !slices.Equal(frame[3*cellIdx:3*cellIdx+3], rgb[:]) {
+			t.Fatalf("cell %d streamed %v, board draws %v", cellIdx, frame[3*cellIdx:3*cellIdx+3], rgb)
+		}
+	}
+
+	resp, err = http.PostForm(server.URL+"/control", url.Values{"action": {"mode"}, "value": {strconv.Itoa(int(ui.RenderModeHealth))}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	viewer.advance()
+	if key := next("key"); key["tick"].(float64) != float64(g.State.LogicTick) {
+		t.Fatalf("mode change keyframe at tick %v, want %d", key["tick"], g.State.LogicTick)
+	}
+
+	resp, err = http.PostForm(server.URL+"/control", url.Values{"action": {"speed"}, "value": {"0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("speed 0 returned %d, want 400", resp.StatusCode)
+	}
+}
+
+func readSSE(t *testing.T, r *bufio.Reader) (name, data string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestTUIFrameDrawsBoardPanelAndHelpWithinTerminal(t *testing.T) {
+	g := newDeterministicGame(3)
+	g.InitializeForCommands()
+	g.RunHeadlessFrames(2)
+	width, height := 100, 30
+	view := tuiView{CenterRow: util.Rows / 2, CenterCol: util.Cols / 2, Speed: 1}
+	view.Zoom = fitTUIPaneZoom(tuiPaneSize(view, width, height))
+	if boardWidth, boardHeight := tuiBoardSize(width, height); (util.Cols+view.Zoom-1)/view.Zoom > boardWidth || (util.Rows+view.Zoom-1)/view.Zoom > boardHeight {
+		t.Fatalf("fit zoom %d does not fit %dx%d", view.Zoom, boardWidth, boardHeight)
+	}
+
+	for _, trueColor := range []bool{false, true} {
+		view.TrueColor = trueColor
+		frame := composeTUIPanes([]*game.Game{g}, nil, view, width, height)
+		lines := strings.Split(frame, "\r\n")
+		if len(lines) != height {
+			t.Fatalf("frame has %d lines, want %d", len(lines), height)
+		}
+		plain := regexp.MustCompile("\x1b\\[[0-9;]*[mK]").ReplaceAllString(frame, "")
+		for i, line := range strings.Split(plain, "\r\n") {
+			if n := len([]rune(line)); n > width {
+				t.Fatalf("line %d is %d columns wide, want at most %d", i, n, width)
+			}
+		}
+		if !strings.Contains(plain, "▀") || !strings.Contains(plain, fmt.Sprintf("live bots  %d", g.Board.ActiveBotCount())) {
+			t.Fatalf("frame missing board or stats panel:\n%s", plain)
+		}
+		wantColor := "\x1b[38;5;"
+		if trueColor {
+			wantColor = "\x1b[38;2;"
+		}
+		if !strings.Contains(frame, wantColor) {
+			t.Fatalf("truecolor=%v frame missing %q escapes", trueColor, wantColor)
+		}
+	}
+}
+
+func TestTUIKeysPanZoomPauseAndCycleModes(t *testing.T) {
+	if got, want := splitTUIKeys([]byte("\x1b[Am \x1bOD+")), []string{"up", "m", " ", "left", "+"}; !slices.Equal(got, want) {
+		t.Fatalf("splitTUIKeys = %q, want %q", got, want)
+	}
+
+	view := tuiView{Zoom: 4, CenterRow: 10, CenterCol: 0, Speed: 1}
+	steps := 0
+	for _, key := range []string{"up", "left", "+", " ", "n", "n", "m", "]"} {
+		var quit bool
+		view, steps, quit = view.handleKey(key, steps, 100, 30)
+		if quit {
+			t.Fatalf("key %q quit the viewer", key)
+		}
+	}
+	if view.CenterRow <= 10 || view.CenterCol <= 0 || view.CenterCol >= util.Cols {
+		t.Fatalf("pan moved center to %d,%d", view.CenterRow, view.CenterCol)
+	}
+	if view.Zoom != 2 || !view.Paused || steps != 2 || view.Mode != ui.RenderModeGenome || view.Speed != 2 {
+		t.Fatalf("view after keys = %+v steps=%d", view, steps)
+	}
+	if _, _, quit := view.handleKey("q", 0, 100, 30); !quit {
+		t.Fatal("q did not quit")
+	}
+}
+
+func TestMatchSummaryTerritoryCoversControlledColonies(t *testing.T) {
+	summary := runMatchSummary(9, 150, 0)
+
+	if len(summary.ColonyTerritory) == 0 {
+		t.Fatal("colony_territory is empty after a match with controllers")
+	}
+	claimed := 0
+	for i, colony := range summary.ColonyTerritory {
+		if colony.Area <= 0 || colony.ColonyID == nil {
+			t.Fatalf("territory entry %d = %+v, want a positive area and colony id", i, colony)
+		}
+		if i > 0 && colony.Area > summary.ColonyTerritory[i-1].Area {
+			t.Fatalf("colony_territory not sorted by area: %+v", summary.ColonyTerritory)
+		}
+		claimed += colony.Area
+	}
+	if claimed+summary.UnclaimedArea != core.Rows*core.Cols {
+		t.Fatalf("claimed %d + unclaimed %d, want %d cells", claimed, summary.UnclaimedArea, core.Rows*core.Cols)
+	}
+}
+
+func TestMatchSummaryColonyIDsMatchSVGDataColony(t *testing.T) {
+	gameRunner := newDeterministicGame(9)
+	gameRunner.InitializeForCommands()
+	gameRunner.RunHeadlessFrames(150)
+	summary := summarizeMatch(gameRunner, 9, 150, 0)
+
+	output := filepath.Join(t.TempDir(), "board.svg")
+	if _, err := render.SaveBoardSVG(gameRunner.Board, render.SVGOptions{
+		Options:  render.Options{Output: output},
+		ColonyID: gameRunner.ColonyID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	svg, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := map[int]bool{}
+	for _, match := range regexp.MustCompile(`data-kind="controller" data-colony="(\d+)"`).FindAllSubmatch(svg, -1) {
+		id, _ := strconv.Atoi(string(match[1]))
+		rendered[id] = true
+	}
+	summarized := map[int]bool{}
+	for _, colony := range summary.ColonyTerritory {
+		if colony.ColonyID != nil {
+			summarized[*colony.ColonyID] = true
+		}
+	}
+	if len(rendered) == 0 || !reflect.DeepEqual(rendered, summarized) {
+		t.Fatalf("SVG controller colonies %v, colony_territory ids %v", rendered, summarized)
+	}
+}
+
+func TestRenderComparisonMatchesSoloRunsAndLoadsConfigs(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "b.json")
+	if err := os.WriteFile(configPath, []byte(`{"immigrationBots": 0}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := resolveCompareRuns(1, "3,4", "", configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs[0].Seed != 3 || runs[1].Seed != 4 || runs[1].Config.ImmigrationBots != 0 || runs[0].Config.ImmigrationBots == 0 {
+		t.Fatalf("runs = %+v %+v, want seeds 3,4 with the second config loaded over defaults", runs[0], runs[1])
+	}
+	if _, err := resolveCompareRuns(1, "3,4,5", "", ""); err == nil {
+		t.Fatal("three seeds accepted for --compare")
+	}
+
+	opts := render.Options{
+		AtlasPath: filepath.Join("..", "..", "assests", "sprites", "atlas.png"),
+		Output:    filepath.Join(dir, "compare.png"),
+		Style:     "flat",
+		CellSize:  1,
+	}
+	result, err := renderComparison(runs, 5, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffCells == 0 {
+		t.Fatal("different seeds produced no diff cells")
+	}
+
+	solo := newDeterministicGame(3)
+	solo.InitializeForCommands()
+	solo.RunHeadlessFrames(5)
+	want, err := render.RenderBoard(solo.Board, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(result.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	got, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < util.Rows; y++ {
+		for x := 0; x < util.Cols; x++ {
+			if g, w := color.RGBAModel.Convert(got.At(x, y)), want.At(x, y); g != w {
+				t.Fatalf("left panel at %d,%d = %v, want solo seed 3 render %v", x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestTUISplitGamesMatchSoloRuns(t *testing.T) {
+	runs, err := resolveCompareRuns(1, "3,4", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newGame := func(run compareRun) *game.Game {
+		g := newDeterministicGameWithConfig(run.Seed, run.Config)
+		configureGameMaster(g, "mock", "", 120, 750*time.Millisecond)
+		g.InitializeForCommands()
+		return g
+	}
+	games := []*game.Game{newGame(runs[0]), newGame(runs[1])}
+	for range 5 {
+		stepTUIGames(games, 30)
+	}
+
+	for i, run := range runs {
+		solo := newGame(run)
+		solo.RunHeadlessFrames(150)
+		if got, want := games[i].StateHash(), solo.StateHash(); got != want {
+			t.Fatalf("split game %d (seed %d) diverged from its solo run: components %v", i, run.Seed, got.Diverged(want))
+		}
+	}
+}
+
+func TestTUISplitFrameShowsBothGamesSideBySide(t *testing.T) {
+	runs, err := resolveCompareRuns(1, "3,4", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	games := make([]*game.Game, len(runs))
+	for i, run := range runs {
+		games[i] = newDeterministicGameWithConfig(run.Seed, run.Config)
+		games[i].InitializeForCommands()
+	}
+	stepTUIGames(games, 2)
+	width, height := 140, 30
+	view := tuiView{CenterRow: util.Rows / 2, CenterCol: util.Cols / 2, Speed: 1, Split: true}
+	paneWidth, paneHeight := tuiPaneSize(view, width, height)
+	view.Zoom = fitTUIPaneZoom(paneWidth, paneHeight)
+	if boardWidth, _ := tuiBoardSize(width, height); 2*paneWidth+1 > boardWidth {
+		t.Fatalf("two panes of %d do not fit board width %d", paneWidth, boardWidth)
+	}
+
+	frame := composeTUIPanes(games, runs[:], view, width, height)
+	plain := regexp.MustCompile("\x1b\\[[0-9;]*[mK]").ReplaceAllString(frame, "")
+	lines := strings.Split(plain, "\r\n")
+	if len(lines) != height {
+		t.Fatalf("frame has %d lines, want %d", len(lines), height)
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n > width {
+			t.Fatalf("line %d is %d columns wide, want at most %d", i, n, width)
+		}
+	}
+	for _, want := range []string{
+		"golab compare",
+		fmt.Sprintf("%-10s %-9s %s", "seed", "3", "4"),
+		fmt.Sprintf("%-10s %-9d %d", "tick", games[0].State.LogicTick, games[1].State.LogicTick),
+	} {
+		if !strings.Contains(plain, want) {
+			t.Fatalf("split panel missing %q:\n%s", want, plain)
+		}
+	}
+	if games[0].State.LogicTick != games[1].State.LogicTick {
+		t.Fatalf("lockstep ticks = %d and %d", games[0].State.LogicTick, games[1].State.LogicTick)
+	}
+	middle := []rune(lines[height/2])
+	left, right := string(middle[:paneWidth]), string(middle[paneWidth+1:2*paneWidth+1])
+	if middle[paneWidth] != ' ' || !strings.Contains(left, "▀") || !strings.Contains(right, "▀") {
+		t.Fatalf("middle line does not show two panes split at column %d: %q", paneWidth, string(middle))
+	}
+}
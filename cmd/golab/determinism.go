@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"golab/internal/game"
+)
+
+const (
+	defaultDeterminismTicks = 200
+	defaultDeterminismRuns  = 3
+)
+
+// determinismRun is one replay of the seed and the settings it ran under.
+type determinismRun struct {
+	Run        int               `json:"run"`
+	Procs      int               `json:"procs"`
+	Workers    int               `json:"workers"`
+	Schedule   string            `json:"schedule"`
+	Baseline   int               `json:"baseline"`
+	FinalHash  string            `json:"final_hash"`
+	Diverged   bool              `json:"diverged"`
+	FirstTick  int               `json:"first_divergent_tick,omitempty"`
+	Components []string          `json:"components,omitempty"`
+	Hashes     map[string]string `json:"hashes,omitempty"`
+	Expected   map[string]string `json:"expected,omitempty"`
+}
+
+type determinismResult struct {
+	Command       string           `json:"command"`
+	Seed          int64            `json:"seed"`
+	Ticks         int              `json:"ticks"`
+	Runs          []determinismRun `json:"runs"`
+	Deterministic bool             `json:"deterministic"`
+}
+
+func runDeterminism(args []string) {
+	flags := commandFlagSet("determinism")
+	seed := flags.Int64("seed", 1, "Deterministic PRNG seed.")
+	ticks := flags.Int("ticks", defaultDeterminismTicks, "Simulation ticks per run.")
+	runs := flags.Int("runs", defaultDeterminismRuns, "Number of runs of the seed.")
+	procsArg := flags.String("procs", "", "GOMAXPROCS per run as a comma list, cycled across runs; empty keeps the current value.")
+	workersArg := flags.String("workers", "1", "Bot stepping workers per run as a comma list, cycled across runs.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "determinism [--seed N] [--ticks N] [--runs N] [--procs 1,4] [--workers 1,4] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+
+	procs, err := parseIntList(*procsArg, "--procs")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	workers, err := parseIntList(*workersArg, "--workers")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	result := checkDeterminism(*seed, normalizeNonNegativeInt(*ticks), max(*runs, 1), procs, workers)
+	printJSON(result, *pretty)
+	if !result.Deterministic {
+		exitCommand(1)
+	}
+}
+
+// checkDeterminism runs seed runs times, cycling through procs and workers,
+// and compares each run's per-tick state hashes with the first run on the
+// same scheduler. Serial and parallel schedules are compared separately
+// because the parallel scheduler's results differ from the serial one's by
+// design.
+//
+// There is no flag-order variant: the flag package stores every flag before
+// the command starts, and a game reads only the parsed seed, config and
+// worker count, so reordering flags cannot reach the simulation. Repeating a
+// flag is the one case where order matters, and there the last value wins
+// by definition rather than by accident.
+func checkDeterminism(seed int64, ticks, runs int, procs, workers []int) determinismResult {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	result := determinismResult{
+		Command:       "determinism",
+		Seed:          seed,
+		Ticks:         ticks,
+		Deterministic: true,
+	}
+	baselines := map[string]int{}
+	var history [][]game.StateHash
+	for n := range runs {
+		run := determinismRun{Run: n, Procs: runtime.GOMAXPROCS(0), Workers: 1}
+		if len(procs) > 0 {
+			run.Procs = max(procs[n%len(procs)], 1)
+			runtime.GOMAXPROCS(run.Procs)
+		}
+		if len(workers) > 0 {
+			run.Workers = max(workers[n%len(workers)], 1)
+		}
+		run.Schedule = "serial"
+		if run.Workers > 1 {
+			run.Schedule = "parallel"
+		}
+
+		hashes := stateHashesPerTick(seed, ticks, run.Workers)
+		history = append(history, hashes)
+		run.FinalHash = combinedStateHash(hashes[len(hashes)-1])
+
+		base, ok := baselines[run.Schedule]
+		if !ok {
+			baselines[run.Schedule] = n
+			base = n
+		}
+		run.Baseline = base
+		for tick, hash := range hashes {
+			want := history[base][tick]
+			if components := hash.Diverged(want); len(components) > 0 {
+				run.Diverged = true
+				run.FirstTick = tick
+				run.Components = components
+				run.Hashes = stateHashStrings(hash, components)
+				run.Expected = stateHashStrings(want, components)
+				result.Deterministic = false
+				break
+			}
+		}
+		result.Runs = append(result.Runs, run)
+	}
+	return result
+}
+
+// stateHashesPerTick returns the state hash after initialization and after
+// each of ticks ticks.
+func stateHashesPerTick(seed int64, ticks, workers int) []game.StateHash {
+	g := newDeterministicGame(seed)
+	g.SetBotWorkers(workers)
+	g.InitializeForCommands()
+	hashes := make([]game.StateHash, 0, ticks+1)
+	hashes = append(hashes, g.StateHash())
+	for range ticks {
+		g.RunHeadlessFrames(1)
+		hashes = append(hashes, g.StateHash())
+	}
+	return hashes
+}
+
+func stateHashStrings(hash game.StateHash, components []string) map[string]string {
+	out := make(map[string]string, len(components))
+	for _, name := range components {
+		out[name] = formatStateHash(hash.Component(name))
+	}
+	return out
+}
+
+func combinedStateHash(hash game.StateHash) string {
+	var combined uint64
+	for _, name := range game.StateHashComponents {
+		combined = combined*31 + hash.Component(name)
+	}
+	return formatStateHash(combined)
+}
+
+func formatStateHash(v uint64) string {
+	return fmt.Sprintf("%016x", v)
+}
+
+func parseIntList(value, name string) ([]int, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	list := make([]int, 0, len(fields))
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", name, field, err)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
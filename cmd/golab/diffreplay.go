@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golab/internal/core"
+	"golab/internal/render"
+)
+
+const defaultDiffReplayTop = 10
+
+// replayFile is the part of a replay payload diff-replay reads. Frames stay
+// generic so files written by other builds still load when fields change.
+type replayFile struct {
+	Path   string           `json:"path"`
+	Seed   int64            `json:"seed"`
+	Frames []map[string]any `json:"-"`
+}
+
+// replayMetricDiff is one summary metric at one tick on both sides.
+type replayMetricDiff struct {
+	Metric   string  `json:"metric"`
+	A        float64 `json:"a"`
+	B        float64 `json:"b"`
+	Delta    float64 `json:"delta"`
+	Relative float64 `json:"relative"`
+}
+
+type replayDiff struct {
+	Command            string             `json:"command"`
+	A                  replayFile         `json:"a"`
+	B                  replayFile         `json:"b"`
+	AlignedTicks       int                `json:"aligned_ticks"`
+	Diverged           bool               `json:"diverged"`
+	FirstDivergentTick *int               `json:"first_divergent_tick"`
+	DivergentMetrics   []replayMetricDiff `json:"divergent_metrics"`
+	FinalTick          int                `json:"final_tick"`
+	FinalMetrics       []replayMetricDiff `json:"final_metrics"`
+	Image              *replayDiffImage   `json:"image,omitempty"`
+	// ImageError says why the diff image was skipped.
+	ImageError string `json:"image_error,omitempty"`
+}
+
+// replayDiffImage describes the board comparison at the first divergent
+// tick. BoardSource is "replay" when both files carry boards and
+// "resimulated" when the boards were rebuilt from the seeds by this build.
+type replayDiffImage struct {
+	render.CompareResult
+	BoardSource string `json:"board_source"`
+}
+
+// replayIdentityFields name a frame rather than measure it.
+var replayIdentityFields = map[string]bool{
+	"command":   true,
+	"seed":      true,
+	"ticks":     true,
+	"timestamp": true,
+	"board":     true,
+}
+
+func runDiffReplay(args []string) {
+	var paths []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		paths, args = append(paths, args[0]), args[1:]
+	}
+	flags := commandFlagSet("diff-replay")
+	top := flags.Int("top", defaultDiffReplayTop, "Metrics to report per compared tick, most divergent first.")
+	image := flags.Bool("image", true, "Render both boards at the first divergent tick with a diff panel.")
+	output := flags.String("output", "golab-replay-diff.png", "Diff image path.")
+	cellSize := flags.Int("cell-size", 2, "Output pixels per board cell.")
+	style := flags.String("style", "game", "Render style for both boards, as for render --style.")
+	legend := flags.Bool("legend", false, "Draw a compact visual legend below the boards.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "diff-replay a.json b.json [--top N] [--image=true|false] [--output path] [--cell-size N] [--style game|flat|colony|...] [--legend] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+	if len(paths) != 2 {
+		flags.Usage()
+		fmt.Fprintf(os.Stderr, "diff-replay needs exactly two replay files, got %d\n", len(paths))
+		os.Exit(2)
+	}
+
+	var files [2]replayFile
+	for i, path := range paths {
+		file, err := loadReplayFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		files[i] = file
+	}
+	result := diffReplays(files[0], files[1], normalizeNonNegativeInt(*top))
+	if result.Diverged && *image {
+		img, err := renderReplayDiff(files[0], files[1], *result.FirstDivergentTick, render.Options{
+			Output:   *output,
+			CellSize: normalizePositiveInt(*cellSize),
+			Style:    *style,
+			Legend:   *legend,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			result.ImageError = err.Error()
+		} else {
+			result.Image = &img
+		}
+	}
+	printJSON(result, *pretty)
+	if result.Diverged {
+		exitCommand(1)
+	}
+}
+
+func loadReplayFile(path string) (replayFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return replayFile{}, err
+	}
+	var payload struct {
+		Seed   int64            `json:"seed"`
+		Frames []map[string]any `json:"frames"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return replayFile{}, fmt.Errorf("invalid replay %s: %w", path, err)
+	}
+	if len(payload.Frames) == 0 {
+		return replayFile{}, fmt.Errorf("replay %s has no frames", path)
+	}
+	return replayFile{Path: path, Seed: payload.Seed, Frames: payload.Frames}, nil
+}
+
+// diffReplays aligns the frames of a and b by tick and reports the first
+// tick where any summary metric differs, the metrics that differ most there,
+// and the metrics that differ most at the last tick both files sampled.
+func diffReplays(a, b replayFile, top int) replayDiff {
+	result := replayDiff{Command: "diff-replay", A: a, B: b}
+	framesB := make(map[int]map[string]any, len(b.Frames))
+	for _, frame := range b.Frames {
+		framesB[replayFrameTick(frame)] = frame
+	}
+	var last []replayMetricDiff
+	for _, frameA := range a.Frames {
+		tick := replayFrameTick(frameA)
+		frameB, ok := framesB[tick]
+		if !ok {
+			continue
+		}
+		result.AlignedTicks++
+		result.FinalTick = tick
+		last = diffReplayMetrics(frameA, frameB)
+		if len(last) > 0 && !result.Diverged {
+			result.Diverged = true
+			result.FirstDivergentTick = &tick
+			result.DivergentMetrics = last[:min(top, len(last))]
+		}
+	}
+	result.FinalMetrics = last[:min(top, len(last))]
+	return result
+}
+
+func replayFrameTick(frame map[string]any) int {
+	tick, _ := frame["ticks"].(float64)
+	return int(tick)
+}
+
+// diffReplayMetrics lists the numeric metrics that differ between two
+// frames, most divergent first. Divergence is relative, so a drift of 50
+// bots in 10000 ranks below a colony count going from 2 to 4. Metrics only
+// one side has count as 0 on the other.
+func diffReplayMetrics(a, b map[string]any) []replayMetricDiff {
+	valuesA, valuesB := map[string]float64{}, map[string]float64{}
+	flattenReplayMetrics("", a, valuesA)
+	flattenReplayMetrics("", b, valuesB)
+	for name := range valuesB {
+		if _, ok := valuesA[name]; !ok {
+			valuesA[name] = 0
+		}
+	}
+	var diffs []replayMetricDiff
+	for name, va := range valuesA {
+		vb := valuesB[name]
+		if va == vb {
+			continue
+		}
+		delta := vb - va
+		diffs = append(diffs, replayMetricDiff{
+			Metric:   name,
+			A:        va,
+			B:        vb,
+			Delta:    delta,
+			Relative: math.Abs(delta) / max(math.Abs(va), math.Abs(vb), 1),
+		})
+	}
+	slices.SortFunc(diffs, func(x, y replayMetricDiff) int {
+		if x.Relative != y.Relative {
+			if x.Relative > y.Relative {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(x.Metric, y.Metric)
+	})
+	return diffs
+}
+
+// flattenReplayMetrics collects numeric fields, naming nested ones by their
+// dotted path. Lists such as top_bots are per-bot detail, not metrics, and
+// are skipped.
+func flattenReplayMetrics(prefix string, frame map[string]any, out map[string]float64) {
+	for key, value := range frame {
+		if prefix == "" && replayIdentityFields[key] {
+			continue
+		}
+		switch v := value.(type) {
+		case float64:
+			out[prefix+key] = v
+		case map[string]any:
+			flattenReplayMetrics(prefix+key+".", v, out)
+		}
+	}
+}
+
+// renderReplayDiff draws both boards at tick. It uses the boards stored in
+// the replay frames when both have them, so outputs from two different builds
+// are drawn as those builds saw them. Otherwise it replays each seed to tick
+// with this build, which only shows a difference when seeds differ.
+func renderReplayDiff(a, b replayFile, tick int, opts render.Options) (replayDiffImage, error) {
+	boardA, errA := replayFrameBoard(a, tick)
+	boardB, errB := replayFrameBoard(b, tick)
+	source := "replay"
+	if errA != nil || errB != nil {
+		if a.Seed == b.Seed {
+			// Both replays usually come from different builds; replaying the
+			// seed twice with this build would draw two identical boards.
+			return replayDiffImage{}, fmt.Errorf("cannot draw the diff image: %w", errors.Join(errA, errB))
+		}
+		source = "resimulated"
+		for i, seed := range []int64{a.Seed, b.Seed} {
+			g := newDeterministicGame(seed)
+			g.InitializeForCommands()
+			g.RunHeadlessFrames(tick)
+			if i == 0 {
+				boardA = g.Board
+			} else {
+				boardB = g.Board
+			}
+		}
+	}
+	result, err := render.SaveComparisonPNG(boardA, boardB, opts)
+	return replayDiffImage{CompareResult: result, BoardSource: source}, err
+}
+
+func replayFrameBoard(file replayFile, tick int) (*core.Board, error) {
+	for _, frame := range file.Frames {
+		if replayFrameTick(frame) != tick {
+			continue
+		}
+		encoded, ok := frame["board"].(string)
+		if !ok {
+			return nil, fmt.Errorf("replay %s frame %d has no board; rerun replay with --boards", file.Path, tick)
+		}
+		return decodeBoardKinds(encoded)
+	}
+	return nil, fmt.Errorf("replay %s has no frame at tick %d", file.Path, tick)
+}
+
+// encodeBoardKinds run-length encodes the board's cell kinds in index order:
+// each run is a letter, 'a' plus the kind, followed by its length when that
+// is more than one.
+func encodeBoardKinds(brd *core.Board) string {
+	var sb strings.Builder
+	kinds := brd.Kinds()
+	for start := 0; start < len(kinds); {
+		end := start + 1
+		for end < len(kinds) && kinds[end] == kinds[start] {
+			end++
+		}
+		sb.WriteByte('a' + byte(kinds[start]))
+		if n := end - start; n > 1 {
+			sb.WriteString(strconv.Itoa(n))
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+// decodeBoardKinds rebuilds a board from encodeBoardKinds output. Only kinds
+// survive: bots are blank and structures hold an amount of 1 with no owner.
+func decodeBoardKinds(encoded string) (*core.Board, error) {
+	brd := core.NewBoard()
+	i := 0
+	for pos := 0; pos < len(encoded); {
+		kind := core.CellKind(encoded[pos] - 'a')
+		if encoded[pos] < 'a' || kind > core.CellFlag {
+			return nil, fmt.Errorf("invalid board encoding at byte %d", pos)
+		}
+		pos++
+		end := pos
+		for end < len(encoded) && encoded[end] >= '0' && encoded[end] <= '9' {
+			end++
+		}
+		n := 1
+		if end > pos {
+			var err error
+			if n, err = strconv.Atoi(encoded[pos:end]); err != nil || n < 2 {
+				return nil, fmt.Errorf("invalid board run length %q at byte %d", encoded[pos:end], pos)
+			}
+		}
+		pos = end
+		if n > core.Rows*core.Cols-i {
+			return nil, fmt.Errorf("board encoding holds more than %d cells", core.Rows*core.Cols)
+		}
+		for ; n > 0; n, i = n-1, i+1 {
+			switch kind {
+			case core.CellEmpty:
+			case core.CellBot:
+				bot := core.NewBot(core.Position{R: i / core.Cols, C: i % core.Cols})
+				brd.AddBot(bot.Pos, &bot)
+			default:
+				brd.SetCellIdx(i, core.Cell{Kind: kind, Amount: 1})
+			}
+		}
+	}
+	if i != core.Rows*core.Cols {
+		return nil, fmt.Errorf("board encoding holds %d cells, want %d", i, core.Rows*core.Cols)
+	}
+	return brd, nil
+}
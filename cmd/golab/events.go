@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"golab/internal/game"
+)
+
+// commandEvents is the NDJSON sink opened from --events for the current
+// process; every deterministic game constructed afterwards streams into it.
+var commandEvents *game.EventSink
+
+func registerEventFlags(flags *flag.FlagSet) *string {
+	return flags.String("events", "", "Stream simulation events (births, deaths, kills, raids, builds, colonies, tasks) to path as NDJSON.")
+}
+
+func openCommandEvents(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	closeCommandEvents()
+	commandEvents = game.NewEventSink(f)
+	return nil
+}
+
+func attachCommandEvents(g *game.Game, seed int64) {
+	if commandEvents == nil {
+		return
+	}
+	sink := commandEvents
+	g.Events().Subscribe(func(event game.Event) {
+		event.Seed = seed
+		sink.Write(event)
+	})
+}
+
+func closeCommandEvents() {
+	if commandEvents == nil {
+		return
+	}
+	if err := commandEvents.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "events: %v\n", err)
+	}
+	commandEvents = nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golab/internal/game"
+)
+
+// commandInvariantEvery is the tick interval from --check-invariants for the
+// current process; every game constructed afterwards checks at it. 0 is off.
+var commandInvariantEvery int
+
+// invariantsFlag parses --check-invariants, --check-invariants=N and
+// --check-invariants="every N" into a tick interval.
+type invariantsFlag struct {
+	every *int
+}
+
+func (f invariantsFlag) String() string {
+	if f.every == nil || *f.every == 0 {
+		return ""
+	}
+	return strconv.Itoa(*f.every)
+}
+
+func (f invariantsFlag) Set(value string) error {
+	every, err := parseInvariantInterval(value)
+	if err != nil {
+		return err
+	}
+	*f.every = every
+	return nil
+}
+
+func (f invariantsFlag) IsBoolFlag() bool { return true }
+
+func parseInvariantInterval(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "", "true":
+		return 1, nil
+	case "false":
+		return 0, nil
+	}
+	value = strings.TrimLeft(strings.TrimPrefix(value, "every"), " =")
+	every, err := strconv.Atoi(value)
+	if err != nil || every < 0 {
+		return 0, fmt.Errorf("invalid --check-invariants %q: use a tick interval like 10 or \"every 10\"", value)
+	}
+	return every, nil
+}
+
+func registerInvariantFlags(flags *flag.FlagSet) {
+	commandInvariantEvery = 0
+	flags.Var(invariantsFlag{&commandInvariantEvery}, "check-invariants", "Verify board, registry and colony bookkeeping after every tick, or every N ticks with =N; exit 3 with a report on the first failure.")
+}
+
+func attachCommandInvariants(g *game.Game, seed int64) {
+	if commandInvariantEvery <= 0 {
+		return
+	}
+	g.EnableInvariantChecks(commandInvariantEvery, func(err *game.InvariantError) {
+		fmt.Fprintf(os.Stderr, "seed %d: %v\n", seed, err)
+		exitCommand(3)
+	})
+}
@@ -3,13 +3,13 @@ package main
 import (
 	"flag"
 	"fmt"
+	"golab/internal/assets"
 	"golab/internal/config"
 	"golab/internal/game"
 	"golab/internal/ui"
 	"os"
 	"os/signal"
 	"runtime"
-	"runtime/pprof"
 	"strings"
 	"syscall"
 	"time"
@@ -30,16 +30,81 @@ func main() {
 	gmCommand := flag.String("gm-command", "", "external game-master command; receives observation JSON on stdin")
 	gmInterval := flag.Int("gm-interval", 120, "logic ticks between game-master observations")
 	gmTimeout := flag.Duration("gm-timeout", 750*time.Millisecond, "external game-master timeout")
-	cpuProfile := flag.String("cpuprofile", "", "write CPU profile to path")
+	assetsDir := flag.String("assets", "", "texture pack directory laid out like assests/; missing files fall back to the embedded assets")
+	rewindEvery := flag.Int("rewind-every", 100, "logic ticks between rewind snapshots in the window; 0 disables rewind")
+	rewindKeep := flag.Int("rewind-keep", 40, "rewind snapshots to keep")
+	compare := flag.String("compare", "", "split the window between seeds A,B stepped in lockstep")
+	seed := flag.Int64("seed", 1, "seed for both sides of a split window when only --config-a/--config-b are given")
+	configA := flag.String("config-a", "", "JSON config for the left game in the split window")
+	configB := flag.String("config-b", "", "JSON config for the right game in the split window")
+	metrics := registerMetricsFlags(flag.CommandLine)
+	events := registerEventFlags(flag.CommandLine)
+	registerInvariantFlags(flag.CommandLine)
+	profiles := registerProfileFlags(flag.CommandLine)
 	flag.Parse()
+	if err := assets.SetDir(*assetsDir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := metrics.open(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer closeCommandMetrics()
+	if err := openCommandEvents(*events); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer closeCommandEvents()
 
-	stopCPUProfile := startCPUProfile(*cpuProfile)
-	defer stopCPUProfile()
+	if err := profiles.start(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer closeProfilesOnInterrupt()()
+
+	if compareRequested(*compare, *configA, *configB) {
+		if *headless {
+			fmt.Fprintln(os.Stderr, "--compare needs the window; use golab render --compare or golab tui --compare without it")
+			os.Exit(2)
+		}
+		runs, err := resolveCompareRuns(*seed, *compare, *configA, *configB)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		var games [2]*game.Game
+		var labels [2]string
+		for i := range runs {
+			g := newSeededGame(runs[i].Seed, &runs[i].Config)
+			configureGameMaster(g, *gmMode, *gmCommand, *gmInterval, *gmTimeout)
+			attachCommandMetrics(g, runs[i].Seed)
+			attachCommandEvents(g, runs[i].Seed)
+			attachCommandInvariants(g, runs[i].Seed)
+			g.EnableHeatmaps()
+			// Snapshots reseed the game's PRNG, so each side needs its own
+			// rewind seed to keep its run apart from the other.
+			g.EnableRewind(*rewindEvery, *rewindKeep, runs[i].Seed)
+			games[i], labels[i] = g, compareRunLabel(runs[i])
+		}
+		ui.SetConfig(&runs[0].Config)
+		ui.PrepareUi()
+		defer glfw.Terminate()
+		game.RunSplit(games[0], games[1], labels)
+		return
+	}
 
 	// config := config.LoadFromJson("conf.json")
 	config := config.NewConfig()
 	g := game.NewGame(&config)
 	configureGameMaster(g, *gmMode, *gmCommand, *gmInterval, *gmTimeout)
+	attachCommandMetrics(g, 0)
+	attachCommandEvents(g, 0)
+	attachCommandInvariants(g, 0)
+	if !*headless {
+		g.EnableHeatmaps()
+		g.EnableRewind(*rewindEvery, *rewindKeep, time.Now().UnixNano())
+	}
 
 	ui.SetConfig(&config)
 	ui.SetBoard(g.Board)
@@ -55,33 +120,24 @@ func main() {
 	}
 }
 
-func startCPUProfile(path string) func() {
-	if path == "" {
+// closeProfilesOnInterrupt flushes the profiles when the window or headless
+// run is interrupted, since that path never returns from main. The returned
+// func stops watching and flushes them on a normal return.
+func closeProfilesOnInterrupt() func() {
+	if commandProfiles == (commandProfileFiles{}) {
 		return func() {}
 	}
-
-	f, err := os.Create(path)
-	if err != nil {
-		panic(err)
-	}
-	if err := pprof.StartCPUProfile(f); err != nil {
-		f.Close()
-		panic(err)
-	}
-
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sig
-		pprof.StopCPUProfile()
-		f.Close()
+		closeCommandProfiles()
 		os.Exit(0)
 	}()
 
 	return func() {
 		signal.Stop(sig)
-		pprof.StopCPUProfile()
-		f.Close()
+		closeCommandProfiles()
 	}
 }
 
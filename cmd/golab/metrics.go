@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"golab/internal/game"
+)
+
+type metricsFlags struct {
+	out    *string
+	format *string
+	every  *int
+}
+
+// commandMetrics is the exporter opened from --metrics-out for the current
+// process; every deterministic game constructed afterwards streams into it.
+var commandMetrics *game.MetricsExporter
+
+func registerMetricsFlags(flags *flag.FlagSet) metricsFlags {
+	return metricsFlags{
+		out:    flags.String("metrics-out", "", "Stream per-tick metrics to path; .csv selects CSV, anything else NDJSON."),
+		format: flags.String("metrics-format", "", "Metrics format override: csv or ndjson."),
+		every:  flags.Int("metrics-every", 1, "Logic ticks between metrics rows."),
+	}
+}
+
+func (m metricsFlags) open() error {
+	if *m.out == "" {
+		return nil
+	}
+	format, err := game.ParseMetricsFormat(*m.format, *m.out)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*m.out)
+	if err != nil {
+		return err
+	}
+	exporter, err := game.NewMetricsExporter(f, format, normalizePositiveInt(*m.every))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	closeCommandMetrics()
+	commandMetrics = exporter
+	return nil
+}
+
+func attachCommandMetrics(g *game.Game, seed int64) {
+	if commandMetrics != nil {
+		g.EnableMetrics(commandMetrics, seed)
+	}
+}
+
+func closeCommandMetrics() {
+	if commandMetrics == nil {
+		return
+	}
+	if err := commandMetrics.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "metrics: %v\n", err)
+	}
+	commandMetrics = nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// commandProfiles holds the profiles the current process is recording; they
+// are flushed by closeCommandProfiles when the command returns or exits.
+var commandProfiles commandProfileFiles
+
+type commandProfileFiles struct {
+	cpu     *os.File
+	trace   *os.File
+	memPath string
+}
+
+type profileFlags struct {
+	cpu   *string
+	mem   *string
+	trace *string
+}
+
+func registerProfileFlags(flags *flag.FlagSet) profileFlags {
+	return profileFlags{
+		cpu:   flags.String("cpuprofile", "", "Write a CPU profile to path."),
+		mem:   flags.String("memprofile", "", "Write a heap profile to path when the command finishes."),
+		trace: flags.String("trace", "", "Write a runtime execution trace to path."),
+	}
+}
+
+// start begins the requested CPU profile and trace. The heap profile is
+// only written on close, but its path is checked here so a typo fails
+// before the run rather than after it.
+func (p profileFlags) start() error {
+	closeCommandProfiles()
+	if *p.mem != "" {
+		f, err := os.Create(*p.mem)
+		if err != nil {
+			return err
+		}
+		f.Close()
+		commandProfiles.memPath = *p.mem
+	}
+	if *p.cpu != "" {
+		f, err := os.Create(*p.cpu)
+		if err != nil {
+			return err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return err
+		}
+		commandProfiles.cpu = f
+	}
+	if *p.trace != "" {
+		f, err := os.Create(*p.trace)
+		if err != nil {
+			closeCommandProfiles()
+			return err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			closeCommandProfiles()
+			return err
+		}
+		commandProfiles.trace = f
+	}
+	return nil
+}
+
+func closeCommandProfiles() {
+	if f := commandProfiles.cpu; f != nil {
+		pprof.StopCPUProfile()
+		f.Close()
+	}
+	if f := commandProfiles.trace; f != nil {
+		trace.Stop()
+		f.Close()
+	}
+	if path := commandProfiles.memPath; path != "" {
+		if err := writeHeapProfile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "memprofile: %v\n", err)
+		}
+	}
+	commandProfiles = commandProfileFiles{}
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// Collect first so the profile shows what is live, not what is garbage.
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exitCommand flushes the metrics, events and profiles a command opened and
+// exits with code; os.Exit skips the deferred closes in runCommand.
+func exitCommand(code int) {
+	closeCommandMetrics()
+	closeCommandEvents()
+	closeCommandProfiles()
+	os.Exit(code)
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"golab/internal/config"
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+const (
+	defaultTimelineFrames    = 8
+	maxTimelineCardFields    = 24
+	defaultMutationStrength  = 25
+	defaultMutationRate      = 4
+	defaultSweepSimilarRange = 10
+	defaultSweepSimilarLimit = 5
+)
+
+var seedRouletteActions = []string{"rerun", "mutate", "timeline", "sweep-similar"}
+
+// mutableConfigFields lists the balance knobs perturbed by "mutate --mode config".
+var mutableConfigFields = []string{
+	"botChance",
+	"resourceChance",
+	"poisonChance",
+	"immigrationBots",
+	"divisionMinHp",
+	"foodGrabHpGain",
+	"colonySpawnerBirthPeriod",
+	"controllerCrowdThreshold",
+	"pheromoneDecay",
+	"pheromoneHomeDeposit",
+}
+
+type configMutation struct {
+	Field string `json:"field"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+}
+
+type timelineFrame struct {
+	Tick        int `json:"tick"`
+	LiveBots    int `json:"live_bots"`
+	Colonies    int `json:"colonies"`
+	Active      int `json:"active_colonies"`
+	Divisions   int `json:"successful_divisions"`
+	CombatKills int `json:"combat_kills"`
+	BestScore   int `json:"best_score"`
+}
+
+type similarSeed struct {
+	MatchID     string `json:"match_id"`
+	Seed        int64  `json:"seed"`
+	Verdict     string `json:"verdict"`
+	LiveBots    int    `json:"live_bots"`
+	Colonies    int    `json:"colonies"`
+	WinnerScore int    `json:"winner_score"`
+}
+
+func runRerun(args []string) {
+	flags := commandFlagSet("rerun")
+	matchID := flags.String("match-id", "", "Match id to replay, e.g. match-42.")
+	ticks := flags.Int("ticks", defaultMatchTicks, "Simulation ticks to execute.")
+	topBots := flags.Int("top-bots", defaultTopBots, "Number of top bots to include in output.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "rerun --match-id match-N [--ticks N] [--top-bots N] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+	matchSeed := mustParseMatchID(flags, *matchID)
+
+	tickCount := normalizeNonNegativeInt(*ticks)
+	summary := runMatchSummary(matchSeed, tickCount, normalizeNonNegativeInt(*topBots))
+	winner := winningBot(summary.TopBots)
+	card := matchCard(
+		fmt.Sprintf("🔁 Rerun — Match #%d", matchSeed),
+		fmt.Sprintf("Replayed seed **%d** for %d ticks.", matchSeed, tickCount),
+		matchSeed,
+		tickCount,
+		summary,
+	)
+	payload := map[string]any{
+		"command":   "rerun",
+		"match_id":  matchIDForSeed(matchSeed),
+		"seed":      matchSeed,
+		"ticks":     tickCount,
+		"summary":   summary,
+		"winner":    winner,
+		"winner_hp": winnerValue(winner),
+		"card":      card,
+		"verdict":   seedRouletteVerdict(summary),
+		"actions":   seedRouletteActions,
+	}
+	printJSON(payload, *pretty)
+}
+
+func runMutate(args []string) {
+	flags := commandFlagSet("mutate")
+	matchID := flags.String("match-id", "", "Match id to mutate, e.g. match-42.")
+	mode := flags.String("mode", "config", "Mutation target: config or champion.")
+	mutationSeed := flags.Int64("mutation-seed", 0, "Seed for the perturbation; 0 derives it from the match seed.")
+	strength := flags.Int("strength", defaultMutationStrength, "Maximum config perturbation in percent.")
+	mutationRate := flags.Int("mutation-rate", defaultMutationRate, "Genome cells rewritten in champion mode.")
+	ticks := flags.Int("ticks", defaultMatchTicks, "Simulation ticks to execute.")
+	topBots := flags.Int("top-bots", defaultTopBots, "Number of top bots to include in output.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "mutate --match-id match-N [--mode config|champion] [--mutation-seed N] [--strength PCT] [--mutation-rate N] [--ticks N] [--top-bots N] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+	matchSeed := mustParseMatchID(flags, *matchID)
+	perturbSeed := *mutationSeed
+	if perturbSeed == 0 {
+		perturbSeed = matchSeed*7919 + 1
+	}
+
+	tickCount := normalizeNonNegativeInt(*ticks)
+	topBotsCount := normalizeNonNegativeInt(*topBots)
+	baseline := runMatchSummary(matchSeed, tickCount, topBotsCount)
+
+	payload := map[string]any{
+		"command":       "mutate",
+		"match_id":      matchIDForSeed(matchSeed),
+		"seed":          matchSeed,
+		"ticks":         tickCount,
+		"mutation_seed": perturbSeed,
+	}
+
+	var summary matchSummary
+	var detail string
+	switch strings.ToLower(strings.TrimSpace(*mode)) {
+	case "", "config":
+		conf, mutations := mutateConfig(config.NewConfig(), perturbSeed, normalizeNonNegativeInt(*strength))
+		gameRunner := newDeterministicGameWithConfig(matchSeed, conf)
+		gameRunner.InitializeForCommands()
+		gameRunner.RunHeadlessFrames(tickCount)
+		summary = summarizeMatch(gameRunner, matchSeed, tickCount, topBotsCount)
+		payload["mode"] = "config"
+		payload["mutations"] = mutations
+		detail = fmt.Sprintf("%d config knobs perturbed by up to %d%%", len(mutations), normalizeNonNegativeInt(*strength))
+	case "champion":
+		genome, ok := championGenomeForSeed(matchSeed, tickCount)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "match-%d has no champion genome after %d ticks\n", matchSeed, tickCount)
+			os.Exit(1)
+		}
+		util.SeedRandom(perturbSeed)
+		mutated := core.NewMutatedGenomeWithRate(genome, normalizeNonNegativeInt(*mutationRate))
+		gameRunner := newDeterministicGame(matchSeed)
+		gameRunner.InitialGenome = &mutated
+		gameRunner.InitializeForCommands()
+		gameRunner.RunHeadlessFrames(tickCount)
+		summary = summarizeMatch(gameRunner, matchSeed, tickCount, topBotsCount)
+		changes := genomeDifferences(genome, mutated)
+		payload["mode"] = "champion"
+		payload["genome_changes"] = changes
+		detail = fmt.Sprintf("champion genome reseeded with %d rewritten cells", changes)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown --mode: %s\n", *mode)
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	winner := winningBot(summary.TopBots)
+	card := matchCard(
+		fmt.Sprintf("🧬 Mutant — Match #%d", matchSeed),
+		fmt.Sprintf("Reran seed **%d** with %s. Live bots %d → %d.", matchSeed, detail, baseline.LiveBots, summary.LiveBots),
+		matchSeed,
+		tickCount,
+		summary,
+	)
+	card.Fields = append(card.Fields,
+		discordField{Name: "Baseline Verdict", Value: seedRouletteVerdict(baseline), Inline: true},
+		discordField{Name: "Mutant Verdict", Value: seedRouletteVerdict(summary), Inline: true},
+	)
+	payload["baseline"] = baseline
+	payload["summary"] = summary
+	payload["winner"] = winner
+	payload["winner_hp"] = winnerValue(winner)
+	payload["card"] = card
+	payload["verdict"] = seedRouletteVerdict(summary)
+	payload["actions"] = seedRouletteActions
+	printJSON(payload, *pretty)
+}
+
+func runTimeline(args []string) {
+	flags := commandFlagSet("timeline")
+	matchID := flags.String("match-id", "", "Match id to replay, e.g. match-42.")
+	ticks := flags.Int("ticks", defaultMatchTicks, "Simulation ticks to execute.")
+	interval := flags.Int("interval", 0, "Ticks between timeline frames; 0 splits the run into 8 frames.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "timeline --match-id match-N [--ticks N] [--interval N] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+	matchSeed := mustParseMatchID(flags, *matchID)
+
+	tickCount := normalizeNonNegativeInt(*ticks)
+	step := timelineInterval(tickCount, normalizeNonNegativeInt(*interval))
+	frames, final := runTimelineFrames(matchSeed, tickCount, step)
+
+	card := discordCard{
+		Title:       fmt.Sprintf("📈 Timeline — Match #%d", matchSeed),
+		Description: fmt.Sprintf("Seed **%d**, %d ticks sampled every %d. Final verdict: %s.", matchSeed, tickCount, step, seedRouletteVerdict(final)),
+		Color:       0x57F287,
+		Fields:      timelineCardFields(frames),
+		Footer:      cardFooter(),
+	}
+	winner := winningBot(final.TopBots)
+	payload := map[string]any{
+		"command":   "timeline",
+		"match_id":  matchIDForSeed(matchSeed),
+		"seed":      matchSeed,
+		"ticks":     tickCount,
+		"interval":  step,
+		"frames":    frames,
+		"winner":    winner,
+		"winner_hp": winnerValue(winner),
+		"card":      card,
+		"verdict":   seedRouletteVerdict(final),
+		"actions":   seedRouletteActions,
+	}
+	printJSON(payload, *pretty)
+}
+
+func runSweepSimilar(args []string) {
+	flags := commandFlagSet("sweep-similar")
+	matchID := flags.String("match-id", "", "Match id to compare against, e.g. match-42.")
+	radius := flags.Int("radius", defaultSweepSimilarRange, "Seeds scanned on each side of the match seed.")
+	limit := flags.Int("limit", defaultSweepSimilarLimit, "Maximum number of similar seeds to report.")
+	ticks := flags.Int("ticks", defaultMatchTicks, "Simulation ticks per seed.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "sweep-similar --match-id match-N [--radius N] [--limit N] [--ticks N] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+	matchSeed := mustParseMatchID(flags, *matchID)
+
+	tickCount := normalizeNonNegativeInt(*ticks)
+	reference := runMatchSummary(matchSeed, tickCount, 1)
+	verdict := seedRouletteVerdict(reference)
+	similar, scanned := sweepSimilarSeeds(matchSeed, verdict, normalizeNonNegativeInt(*radius), normalizePositiveInt(*limit), tickCount)
+
+	fields := make([]discordField, 0, len(similar))
+	for _, entry := range similar {
+		fields = append(fields, discordField{
+			Name:   entry.MatchID,
+			Value:  fmt.Sprintf("%d bots, %d colonies, score %d", entry.LiveBots, entry.Colonies, entry.WinnerScore),
+			Inline: false,
+		})
+	}
+	card := discordCard{
+		Title:       fmt.Sprintf("🧭 Similar Seeds — Match #%d", matchSeed),
+		Description: fmt.Sprintf("Found %d of %d nearby seeds with verdict **%s**.", len(similar), scanned, verdict),
+		Color:       0xFEE75C,
+		Fields:      fields,
+		Footer:      cardFooter(),
+	}
+	payload := map[string]any{
+		"command":  "sweep-similar",
+		"match_id": matchIDForSeed(matchSeed),
+		"seed":     matchSeed,
+		"ticks":    tickCount,
+		"verdict":  verdict,
+		"scanned":  scanned,
+		"similar":  similar,
+		"card":     card,
+		"actions":  seedRouletteActions,
+	}
+	printJSON(payload, *pretty)
+}
+
+func mustParseMatchID(flags *flag.FlagSet, value string) int64 {
+	seed, err := parseMatchID(value)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.Usage()
+		os.Exit(2)
+	}
+	return seed
+}
+
+func parseMatchID(value string) (int64, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(strings.ToLower(value)), "match-")
+	if trimmed == "" {
+		return 0, fmt.Errorf("--match-id is required")
+	}
+	seed, err := strconv.ParseInt(trimmed, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid match id %q: use match-N or N", value)
+	}
+	return seed, nil
+}
+
+func matchIDForSeed(seed int64) string {
+	return fmt.Sprintf("match-%d", seed)
+}
+
+func mutateConfig(conf config.Config, seed int64, strength int) (config.Config, []configMutation) {
+	rng := rand.New(rand.NewSource(seed))
+	mutations := make([]configMutation, 0, len(mutableConfigFields))
+	for _, name := range mutableConfigFields {
+		from, ok := conf.IntField(name)
+		if !ok {
+			continue
+		}
+		percent := rng.Intn(2*strength+1) - strength
+		to := max(0, from+from*percent/100)
+		if to == from {
+			continue
+		}
+		if err := conf.SetIntField(name, to); err != nil {
+			continue
+		}
+		mutations = append(mutations, configMutation{Field: name, From: from, To: to})
+	}
+	return conf, mutations
+}
+
+func championGenomeForSeed(seed int64, ticks int) (core.Genome, bool) {
+	gameRunner := newDeterministicGame(seed)
+	gameRunner.InitializeForCommands()
+	gameRunner.RunHeadlessFrames(ticks)
+	return gameRunner.ChampionGenome()
+}
+
+func genomeDifferences(a, b core.Genome) int {
+	diff := 0
+	for i := range a.Matrix {
+		if a.Matrix[i] != b.Matrix[i] {
+			diff++
+		}
+	}
+	return diff
+}
+
+func timelineInterval(ticks, interval int) int {
+	if interval > 0 {
+		return interval
+	}
+	return max(1, (ticks+defaultTimelineFrames-1)/defaultTimelineFrames)
+}
+
+func runTimelineFrames(seed int64, ticks, interval int) ([]timelineFrame, matchSummary) {
+	gameRunner := newDeterministicGame(seed)
+	gameRunner.InitializeForCommands()
+
+	final := summarizeMatch(gameRunner, seed, 0, 1)
+	frames := []timelineFrame{timelineFrameFromSummary(final)}
+	for tick := 1; tick <= ticks; tick++ {
+		gameRunner.RunHeadlessFrames(1)
+		if tick%interval == 0 || tick == ticks {
+			final = summarizeMatch(gameRunner, seed, tick, 1)
+			frames = append(frames, timelineFrameFromSummary(final))
+		}
+	}
+	return frames, final
+}
+
+func timelineFrameFromSummary(summary matchSummary) timelineFrame {
+	return timelineFrame{
+		Tick:        summary.Ticks,
+		LiveBots:    summary.LiveBots,
+		Colonies:    summary.ColonyCount,
+		Active:      summary.ActiveColonies,
+		Divisions:   summary.SuccessfulDivisions,
+		CombatKills: summary.CombatKills,
+		BestScore:   summary.BestScore,
+	}
+}
+
+func timelineCardFields(frames []timelineFrame) []discordField {
+	fields := make([]discordField, 0, len(frames))
+	for _, frame := range frames {
+		if len(fields) == maxTimelineCardFields {
+			break
+		}
+		fields = append(fields, discordField{
+			Name:   fmt.Sprintf("t=%d", frame.Tick),
+			Value:  fmt.Sprintf("%d bots • %d/%d colonies • %d kills", frame.LiveBots, frame.Active, frame.Colonies, frame.CombatKills),
+			Inline: true,
+		})
+	}
+	return fields
+}
+
+func sweepSimilarSeeds(center int64, verdict string, radius, limit, ticks int) ([]similarSeed, int) {
+	similar := []similarSeed{}
+	scanned := 0
+	for offset := 1; offset <= radius && len(similar) < limit; offset++ {
+		for _, seed := range []int64{center - int64(offset), center + int64(offset)} {
+			if len(similar) >= limit {
+				break
+			}
+			scanned++
+			summary := runMatchSummary(seed, ticks, 1)
+			if seedRouletteVerdict(summary) != verdict {
+				continue
+			}
+			winner := winningBot(summary.TopBots)
+			similar = append(similar, similarSeed{
+				MatchID:     matchIDForSeed(seed),
+				Seed:        seed,
+				Verdict:     verdict,
+				LiveBots:    summary.LiveBots,
+				Colonies:    summary.ColonyCount,
+				WinnerScore: winnerValue(winner),
+			})
+		}
+	}
+	return similar, scanned
+}
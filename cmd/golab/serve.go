@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"golab/internal/core"
+	"golab/internal/game"
+	"golab/internal/ui"
+	"golab/internal/util"
+)
+
+const (
+	defaultServeAddr = "127.0.0.1:8080"
+	defaultServeFPS  = 15
+	maxServeSpeed    = 64
+	serveClientQueue = 32
+)
+
+//go:embed web
+var webAssets embed.FS
+
+// boardViewer owns a running game and fans its cell colors out to browser
+// clients. Only the loop goroutine touches the game; the mutex guards the
+// control fields, the last-sent frame and the client set.
+type boardViewer struct {
+	g    *game.Game
+	seed int64
+
+	mu      sync.Mutex
+	paused  bool
+	speed   int
+	steps   int
+	mode    ui.RenderMode
+	rekey   bool
+	frame   []byte
+	tick    int
+	live    int
+	clients map[*viewerClient]struct{}
+}
+
+type viewerClient struct {
+	out    chan []byte
+	resync bool
+}
+
+type viewerState struct {
+	Seed     int64    `json:"seed"`
+	Tick     int      `json:"tick"`
+	LiveBots int      `json:"live_bots"`
+	Paused   bool     `json:"paused"`
+	Speed    int      `json:"speed"`
+	Mode     int      `json:"mode"`
+	Modes    []string `json:"modes"`
+	Rows     int      `json:"rows"`
+	Cols     int      `json:"cols"`
+}
+
+func runServe(args []string) {
+	flags := commandFlagSet("serve")
+	addr := flags.String("addr", defaultServeAddr, "Address to listen on.")
+	seed := flags.Int64("seed", 1, "Deterministic PRNG seed.")
+	speed := flags.Int("speed", 1, "Logic ticks per streamed frame.")
+	fps := flags.Int("fps", defaultServeFPS, "Frames streamed per second.")
+	gmMode := flags.String("gm", "mock", "Game master mode: mock or off.")
+	usage := "serve [--addr HOST:PORT] [--seed N] [--speed N] [--fps N] [--gm mock|off]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+
+	g := newDeterministicGame(*seed)
+	configureGameMaster(g, *gmMode, "", 120, 750*time.Millisecond)
+	g.InitializeForCommands()
+	g.EnableHeatmaps()
+	viewer := newBoardViewer(g, *seed, *speed)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Addr: *addr, Handler: viewer.handler()}
+	go viewer.run(ctx, time.Second/time.Duration(normalizePositiveInt(*fps)))
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "serving seed %d on http://%s/\n", *seed, *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newBoardViewer(g *game.Game, seed int64, speed int) *boardViewer {
+	v := &boardViewer{
+		g:       g,
+		seed:    seed,
+		speed:   min(normalizePositiveInt(speed), maxServeSpeed),
+		frame:   make([]byte, 3*util.Cells),
+		clients: map[*viewerClient]struct{}{},
+	}
+	g.Board.PullPatch()
+	v.refresh(true)
+	return v
+}
+
+func (v *boardViewer) handler() http.Handler {
+	static, err := fs.Sub(webAssets, "web")
+	if err != nil {
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /state", v.serveState)
+	mux.HandleFunc("GET /events", v.serveEvents)
+	mux.HandleFunc("POST /control", v.serveControl)
+	return mux
+}
+
+// run advances the game once per interval and broadcasts what changed.
+func (v *boardViewer) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			v.advance()
+		}
+	}
+}
+
+func (v *boardViewer) advance() {
+	v.mu.Lock()
+	ticks := 0
+	switch {
+	case !v.paused:
+		ticks = v.speed
+	case v.steps > 0:
+		ticks = 1
+		v.steps--
+	}
+	v.mu.Unlock()
+
+	v.g.RunHeadlessFrames(ticks)
+	v.refresh(false)
+}
+
+// refresh recolors the cells that may have changed and queues the difference
+// against the last frame to every client. Bot colors in the non-normal modes
+// track HP, cargo and tasks, which do not dirty cells, so bot cells are always
+// rechecked; full-board modes recheck everything.
+func (v *boardViewer) refresh(full bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	brd := v.g.Board
+	full = full || v.rekey || v.mode == ui.RenderModeColony || v.mode == ui.RenderModeBiome || v.mode == ui.RenderModePheromone
+	rekey := v.rekey
+	v.rekey = false
+
+	var patch []byte
+	recolor := func(cellIdx int) {
+		rgb := cellRGB(brd, cellIdx, v.mode)
+		at := v.frame[3*cellIdx : 3*cellIdx+3]
+		if [3]byte(at) == rgb {
+			return
+		}
+		copy(at, rgb[:])
+		patch = binary.LittleEndian.AppendUint32(patch, uint32(cellIdx))
+		patch = append(patch, rgb[:]...)
+	}
+	dirty := brd.PullPatch()
+	if full {
+		for cellIdx := range util.Cells {
+			recolor(cellIdx)
+		}
+	} else {
+		for _, cellIdx := range dirty {
+			recolor(cellIdx)
+		}
+		for _, id := range brd.ActiveBotIDs() {
+			if cellIdx := brd.BotCell(id); cellIdx >= 0 {
+				recolor(cellIdx)
+			}
+		}
+	}
+	v.tick = v.g.State.LogicTick
+	v.live = brd.ActiveBotCount()
+
+	state := sseMessage("state", v.stateLocked())
+	var frame []byte
+	if len(patch) > 0 && !rekey {
+		frame = sseMessage("patch", map[string]any{"tick": v.tick, "cells": base64.StdEncoding.EncodeToString(patch)})
+	}
+	for client := range v.clients {
+		if rekey || client.resync {
+			client.resync = !client.send(v.keyframeLocked())
+		} else if frame != nil && !client.send(frame) {
+			client.resync = true
+			continue
+		}
+		client.send(state)
+	}
+}
+
+func cellRGB(brd *core.Board, cellIdx int, mode ui.RenderMode) [3]byte {
+	color := ui.CellColor(brd, util.PosOf(cellIdx), mode)
+	var rgb [3]byte
+	for i, c := range color {
+		rgb[i] = uint8(min(max(c, 0), 1)*255 + 0.5)
+	}
+	return rgb
+}
+
+func (c *viewerClient) send(message []byte) bool {
+	select {
+	case c.out <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+func (v *boardViewer) keyframeLocked() []byte {
+	return sseMessage("key", map[string]any{
+		"tick": v.tick,
+		"rows": util.Rows,
+		"cols": util.Cols,
+		"rgb":  base64.StdEncoding.EncodeToString(v.frame),
+	})
+}
+
+func (v *boardViewer) stateLocked() viewerState {
+	modes := ui.RenderModes()
+	labels := make([]string, len(modes))
+	for i, mode := range modes {
+		labels[i] = mode.Label()
+	}
+	return viewerState{
+		Seed:     v.seed,
+		Tick:     v.tick,
+		LiveBots: v.live,
+		Paused:   v.paused,
+		Speed:    v.speed,
+		Mode:     int(v.mode),
+		Modes:    labels,
+		Rows:     util.Rows,
+		Cols:     util.Cols,
+	}
+}
+
+func sseMessage(event string, payload any) []byte {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Appendf(nil, "event: %s\ndata: %s\n\n", event, data)
+}
+
+func (v *boardViewer) serveState(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	state := v.stateLocked()
+	v.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// serveEvents streams a keyframe followed by patches as server-sent events.
+func (v *boardViewer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	client := &viewerClient{out: make(chan []byte, serveClientQueue)}
+	v.mu.Lock()
+	client.out <- v.keyframeLocked()
+	client.out <- sseMessage("state", v.stateLocked())
+	v.clients[client] = struct{}{}
+	v.mu.Unlock()
+	defer func() {
+		v.mu.Lock()
+		delete(v.clients, client)
+		v.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case message := <-client.out:
+			if _, err := w.Write(message); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (v *boardViewer) serveControl(w http.ResponseWriter, r *http.Request) {
+	action := r.FormValue("action")
+	value, valueErr := strconv.Atoi(r.FormValue("value"))
+	v.mu.Lock()
+	switch action {
+	case "pause":
+		v.paused = true
+	case "resume":
+		v.paused = false
+		v.steps = 0
+	case "step":
+		v.paused = true
+		v.steps++
+	case "speed":
+		if valueErr != nil || value < 1 || value > maxServeSpeed {
+			v.mu.Unlock()
+			http.Error(w, fmt.Sprintf("speed must be 1-%d", maxServeSpeed), http.StatusBadRequest)
+			return
+		}
+		v.speed = value
+	case "mode":
+		if valueErr != nil || value < 0 || value >= len(ui.RenderModes()) {
+			v.mu.Unlock()
+			http.Error(w, "unknown render mode", http.StatusBadRequest)
+			return
+		}
+		if ui.RenderMode(value) != v.mode {
+			v.mode = ui.RenderMode(value)
+			v.rekey = true
+		}
+	default:
+		v.mu.Unlock()
+		http.Error(w, fmt.Sprintf("unknown action %q", action), http.StatusBadRequest)
+		return
+	}
+	state := v.stateLocked()
+	v.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golab/internal/config"
+)
+
+const (
+	defaultSweepTicks     = 500
+	defaultSweepObjective = "median_best_score"
+	maxSweepCombinations  = 4096
+	// maxSweepSampledGrid bounds the grid --samples picks from, so grid
+	// indexes stay far from overflowing.
+	maxSweepSampledGrid = 1 << 30
+	// maxSweepRangeValues bounds one lo..hi range in a --param list.
+	maxSweepRangeValues = 4096
+)
+
+type sweepParam struct {
+	Name   string `json:"name"`
+	Values []int  `json:"values"`
+}
+
+type sweepParamFlags []string
+
+func (p *sweepParamFlags) String() string {
+	return strings.Join(*p, " ")
+}
+
+func (p *sweepParamFlags) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+type sweepResult struct {
+	Params    map[string]int         `json:"params"`
+	Aggregate smartnessEvalAggregate `json:"aggregate"`
+	Objective float64                `json:"objective"`
+}
+
+type sweepRun struct {
+	Command        string        `json:"command"`
+	Mode           string        `json:"mode"`
+	Seeds          []int64       `json:"seeds"`
+	Ticks          int           `json:"ticks"`
+	SmartEvolution bool          `json:"smart_evolution"`
+	Objective      string        `json:"objective"`
+	Minimize       bool          `json:"minimize"`
+	Params         []sweepParam  `json:"params"`
+	Results        []sweepResult `json:"results"`
+	Best           *sweepResult  `json:"best"`
+}
+
+func runSweep(args []string) {
+	flags := commandFlagSet("sweep")
+	var params sweepParamFlags
+	flags.Var(&params, "param", "Config field sweep as jsonName=v1,v2,v3 or jsonName=lo..hi; repeatable.")
+	seedsArg := flags.String("seeds", "1 2 3", "Seeds per combination; lists and ranges like 1..10.")
+	ticks := flags.Int("ticks", defaultSweepTicks, "Simulation ticks per seed.")
+	samples := flags.Int("samples", 0, "Random combinations to evaluate; 0 runs the full grid.")
+	sampleSeed := flags.Int64("sample-seed", 1, "Seed for random combination sampling.")
+	objective := flags.String("objective", defaultSweepObjective, "Aggregate metric used to pick the best config.")
+	minimize := flags.Bool("minimize", false, "Pick the combination with the lowest objective instead of the highest.")
+	smartEvolution := flags.Bool("smart-evolution", true, "Enable smart evolution during the sweep.")
+	format := flags.String("format", "json", "Output format: json or csv.")
+	pretty := flags.Bool("pretty", false, "Pretty-print JSON output.")
+	usage := "sweep --param name=v1,v2 [--param name=lo..hi] [--seeds 1..10] [--ticks N] [--samples N] [--sample-seed N] [--objective metric] [--minimize] [--format json|csv] [--pretty]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+
+	sweepParams, err := parseSweepParams(params)
+	if err == nil {
+		err = validateSweepObjective(*objective)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.Usage()
+		os.Exit(2)
+	}
+	seeds, err := parseSeedList(*seedsArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	combos, mode, err := sweepCombinations(sweepParams, normalizeNonNegativeInt(*samples), *sampleSeed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	tickCount := normalizeNonNegativeInt(*ticks)
+	result := sweepRun{
+		Command:        "sweep",
+		Mode:           mode,
+		Seeds:          seeds,
+		Ticks:          tickCount,
+		SmartEvolution: *smartEvolution,
+		Objective:      *objective,
+		Minimize:       *minimize,
+		Params:         sweepParams,
+		Results:        make([]sweepResult, 0, len(combos)),
+	}
+	for _, combo := range combos {
+		conf := config.NewConfig()
+		conf.SmartEvolution = *smartEvolution
+		for name, value := range combo {
+			if err := conf.SetIntField(name, value); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+		}
+		aggregate := aggregateSmartnessEval(runSmartnessEvalSeeds(seeds, tickCount, conf), tickCount)
+		result.Results = append(result.Results, sweepResult{
+			Params:    combo,
+			Aggregate: aggregate,
+			Objective: sweepObjectiveValue(aggregate, *objective),
+		})
+	}
+	result.Best = bestSweepResult(result.Results, *minimize)
+
+	switch strings.ToLower(strings.TrimSpace(*format)) {
+	case "", "json":
+		printJSON(result, *pretty)
+	case "csv":
+		if err := writeSweepCSV(os.Stdout, result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown --format: %s\n", *format)
+		os.Exit(2)
+	}
+}
+
+func parseSweepParams(values []string) ([]sweepParam, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("--param is required at least once")
+	}
+	probe := config.NewConfig()
+	params := make([]sweepParam, 0, len(values))
+	seen := map[string]bool{}
+	for _, value := range values {
+		name, list, ok := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --param %q: use name=v1,v2 or name=lo..hi", value)
+		}
+		if _, ok := probe.IntField(name); !ok {
+			return nil, fmt.Errorf("invalid --param %q: %s is not an integer config field", value, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("invalid --param %q: %s is swept twice", value, name)
+		}
+		seen[name] = true
+		parsed, err := parseSweepValues(list)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --param %q: %w", value, err)
+		}
+		params = append(params, sweepParam{Name: name, Values: parsed})
+	}
+	return params, nil
+}
+
+func parseSweepValues(value string) ([]int, error) {
+	out := []int{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if from, to, ok := strings.Cut(field, ".."); ok {
+			first, err := strconv.Atoi(from)
+			if err != nil {
+				return nil, err
+			}
+			last, err := strconv.Atoi(to)
+			if err != nil {
+				return nil, err
+			}
+			if last < first {
+				return nil, fmt.Errorf("range %q ends before it starts", field)
+			}
+			if uint64(last-first) >= maxSweepRangeValues {
+				return nil, fmt.Errorf("range %q has more than %d values", field, maxSweepRangeValues)
+			}
+			// Count up rather than compare against last, which would loop
+			// forever once last is MaxInt.
+			for n := 0; n <= last-first; n++ {
+				out = append(out, first+n)
+			}
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no values")
+	}
+	return out, nil
+}
+
+// sweepCombinations expands the full parameter grid, or a deterministic random
+// subset of it when samples is positive and smaller than the grid. A full
+// grid is limited to maxSweepCombinations and a sampled one to
+// maxSweepSampledGrid.
+func sweepCombinations(params []sweepParam, samples int, sampleSeed int64) ([]map[string]int, string, error) {
+	limit := maxSweepCombinations
+	if samples > 0 {
+		limit = maxSweepSampledGrid
+	}
+	total := 1
+	for _, param := range params {
+		if n := len(param.Values); n > 0 && total > limit/n {
+			if samples == 0 {
+				return nil, "", fmt.Errorf("sweep grid exceeds %d combinations; use --samples", maxSweepCombinations)
+			}
+			return nil, "", fmt.Errorf("sweep grid exceeds %d combinations even for --samples; narrow the --param ranges", maxSweepSampledGrid)
+		}
+		total *= len(param.Values)
+	}
+
+	indexes := []int{}
+	mode := "grid"
+	if samples > 0 && samples < total {
+		mode = "random"
+		rng := rand.New(rand.NewSource(sampleSeed))
+		picked := map[int]bool{}
+		for len(indexes) < samples {
+			idx := rng.Intn(total)
+			if picked[idx] {
+				continue
+			}
+			picked[idx] = true
+			indexes = append(indexes, idx)
+		}
+	} else {
+		for idx := 0; idx < total; idx++ {
+			indexes = append(indexes, idx)
+		}
+	}
+
+	combos := make([]map[string]int, 0, len(indexes))
+	for _, idx := range indexes {
+		combo := make(map[string]int, len(params))
+		for i := len(params) - 1; i >= 0; i-- {
+			values := params[i].Values
+			combo[params[i].Name] = values[idx%len(values)]
+			idx /= len(values)
+		}
+		combos = append(combos, combo)
+	}
+	return combos, mode, nil
+}
+
+func smartnessAggregateColumns() []string {
+	typ := reflect.TypeOf(smartnessEvalAggregate{})
+	columns := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		columns = append(columns, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+	}
+	return columns
+}
+
+func validateSweepObjective(name string) error {
+	for _, column := range smartnessAggregateColumns() {
+		if column == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown --objective %q: use one of %s", name, strings.Join(smartnessAggregateColumns(), ", "))
+}
+
+func smartnessAggregateValues(aggregate smartnessEvalAggregate) map[string]float64 {
+	data, err := json.Marshal(aggregate)
+	if err != nil {
+		panic(err)
+	}
+	values := map[string]float64{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		panic(err)
+	}
+	return values
+}
+
+func sweepObjectiveValue(aggregate smartnessEvalAggregate, objective string) float64 {
+	return smartnessAggregateValues(aggregate)[objective]
+}
+
+func bestSweepResult(results []sweepResult, minimize bool) *sweepResult {
+	var best *sweepResult
+	for i := range results {
+		candidate := &results[i]
+		if best == nil ||
+			(!minimize && candidate.Objective > best.Objective) ||
+			(minimize && candidate.Objective < best.Objective) {
+			best = candidate
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	out := *best
+	return &out
+}
+
+func writeSweepCSV(out io.Writer, result sweepRun) error {
+	writer := csv.NewWriter(out)
+	columns := smartnessAggregateColumns()
+	header := make([]string, 0, len(result.Params)+len(columns)+2)
+	for _, param := range result.Params {
+		header = append(header, param.Name)
+	}
+	header = append(header, columns...)
+	header = append(header, "objective", "best")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range result.Results {
+		record := make([]string, 0, len(header))
+		for _, param := range result.Params {
+			record = append(record, strconv.Itoa(row.Params[param.Name]))
+		}
+		values := smartnessAggregateValues(row.Aggregate)
+		for _, column := range columns {
+			record = append(record, strconv.FormatFloat(values[column], 'f', -1, 64))
+		}
+		isBest := result.Best != nil && reflect.DeepEqual(result.Best.Params, row.Params)
+		record = append(record, strconv.FormatFloat(row.Objective, 'f', -1, 64), strconv.FormatBool(isBest))
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
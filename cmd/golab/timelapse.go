@@ -0,0 +1,40 @@
+package main
+
+import (
+	"golab/internal/game"
+	"golab/internal/render"
+)
+
+const defaultTimelapseEvery = 20
+
+func defaultTimelapseOutput(format render.TimelapseFormat) string {
+	switch format {
+	case render.TimelapseAPNG:
+		return "golab-timelapse.apng"
+	case render.TimelapsePNGSeq:
+		return "golab-timelapse"
+	default:
+		return "golab-timelapse.gif"
+	}
+}
+
+// runTimelapse advances g for ticks logic ticks, capturing the starting board,
+// every interval-th tick and the final tick when it falls between intervals.
+func runTimelapse(g *game.Game, ticks, every int, opts render.TimelapseOptions) (render.TimelapseResult, error) {
+	timelapse, err := render.NewTimelapse(opts)
+	if err != nil {
+		return render.TimelapseResult{}, err
+	}
+	if err := timelapse.Capture(g.Board); err != nil {
+		return render.TimelapseResult{}, err
+	}
+	for tick := 1; tick <= ticks; tick++ {
+		g.RunHeadlessFrames(1)
+		if tick%every == 0 || tick == ticks {
+			if err := timelapse.Capture(g.Board); err != nil {
+				return render.TimelapseResult{}, err
+			}
+		}
+	}
+	return timelapse.Save()
+}
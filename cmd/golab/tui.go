@@ -0,0 +1,544 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golab/internal/config"
+	"golab/internal/core"
+	"golab/internal/game"
+	"golab/internal/ui"
+	"golab/internal/util"
+)
+
+const (
+	defaultTUIFPS    = 10
+	tuiPanelWidth    = 30
+	tuiMaxZoom       = 32
+	tuiSizeEvery     = 10
+	tuiDefaultWidth  = 120
+	tuiDefaultHeight = 40
+)
+
+// tuiView is what the terminal viewer shows: a viewport of zoom×zoom cell
+// blocks around a center cell. Each terminal character draws two blocks with
+// an upper half-block glyph, and screen up is toward higher board rows, as in
+// the desktop window.
+type tuiView struct {
+	Mode      ui.RenderMode
+	Zoom      int
+	CenterRow int
+	CenterCol int
+	Paused    bool
+	Speed     int
+	TrueColor bool
+	// Split shows two games side by side, sharing every view setting.
+	Split bool
+}
+
+func runTUI(args []string) {
+	flags := commandFlagSet("tui")
+	seed := flags.Int64("seed", 1, "Deterministic PRNG seed.")
+	speed := flags.Int("speed", 1, "Logic ticks per frame.")
+	fps := flags.Int("fps", defaultTUIFPS, "Frames drawn per second.")
+	colorMode := flags.String("color", "auto", "Terminal colors: auto, truecolor, or 256.")
+	frames := flags.Int("frames", 0, "Draw this many frames and exit without reading keys; 0 runs interactively.")
+	gmMode := flags.String("gm", "mock", "Game master mode: mock or off.")
+	compare := flags.String("compare", "", "Split the screen between two seeds A,B stepped in lockstep.")
+	configA := flags.String("config-a", "", "JSON config for the left game in split mode.")
+	configB := flags.String("config-b", "", "JSON config for the right game in split mode.")
+	usage := "tui [--seed N] [--speed N] [--fps N] [--color auto|truecolor|256] [--frames N] [--gm mock|off] [--compare A,B] [--config-a path] [--config-b path]"
+	if err := parseCommandFlags(flags, args, usage); err != nil {
+		os.Exit(2)
+	}
+	trueColor, err := parseTUIColor(*colorMode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	runs := []compareRun{{Seed: *seed, Config: config.NewConfig()}}
+	if compareRequested(*compare, *configA, *configB) {
+		pair, err := resolveCompareRuns(*seed, *compare, *configA, *configB)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		runs = pair[:]
+	}
+	games := make([]*game.Game, len(runs))
+	for i, run := range runs {
+		games[i] = newDeterministicGameWithConfig(run.Seed, run.Config)
+		configureGameMaster(games[i], *gmMode, "", 120, 750*time.Millisecond)
+		games[i].InitializeForCommands()
+		games[i].EnableHeatmaps()
+	}
+
+	width, height := terminalSize()
+	view := tuiView{
+		Mode:      ui.RenderModeNormal,
+		CenterRow: util.Rows / 2,
+		CenterCol: util.Cols / 2,
+		Speed:     normalizePositiveInt(*speed),
+		TrueColor: trueColor,
+		Split:     len(games) > 1,
+	}
+	view.Zoom = fitTUIPaneZoom(tuiPaneSize(view, width, height))
+	out := bufio.NewWriterSize(os.Stdout, 1<<16)
+	if *frames > 0 {
+		for frame := range *frames {
+			if frame > 0 {
+				stepTUIGames(games, view.Speed)
+			}
+			out.WriteString(composeTUIPanes(games, runs, view, width, height))
+			out.WriteString("\x1b[0m\n")
+		}
+		out.Flush()
+		return
+	}
+
+	restore, err := enterRawTerminal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tui needs an interactive terminal (use --frames for batch output): %v\n", err)
+		os.Exit(1)
+	}
+	defer restore()
+	out.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		out.WriteString("\x1b[0m\x1b[?25h\x1b[?1049l")
+		out.Flush()
+	}()
+
+	keys := make(chan string, 16)
+	go readTUIKeys(keys)
+	ticker := time.NewTicker(time.Second / time.Duration(normalizePositiveInt(*fps)))
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		steps := 0
+	drain:
+		for {
+			select {
+			case key, ok := <-keys:
+				if !ok {
+					keys = nil
+					break drain
+				}
+				var quit bool
+				view, steps, quit = view.handleKey(key, steps, width, height)
+				if quit {
+					return
+				}
+			default:
+				break drain
+			}
+		}
+		if !view.Paused {
+			steps = view.Speed
+		}
+		stepTUIGames(games, steps)
+		if frame%tuiSizeEvery == 0 {
+			width, height = terminalSize()
+		}
+		out.WriteString("\x1b[H")
+		out.WriteString(composeTUIPanes(games, runs, view, width, height))
+		out.Flush()
+		<-ticker.C
+	}
+}
+
+// stepTUIGames advances every game by the same number of ticks. Each game
+// owns its PRNG state, so a split pane matches a solo run of its seed.
+func stepTUIGames(games []*game.Game, ticks int) {
+	for _, g := range games {
+		g.RunHeadlessFrames(ticks)
+	}
+}
+
+func parseTUIColor(name string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+		return colorTerm == "truecolor" || colorTerm == "24bit", nil
+	case "truecolor", "24bit":
+		return true, nil
+	case "256":
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown --color %q: use auto, truecolor, or 256", name)
+}
+
+// handleKey applies one key press. steps counts single-tick steps requested
+// while paused during this frame.
+func (v tuiView) handleKey(key string, steps, width, height int) (tuiView, int, bool) {
+	paneWidth, paneHeight := tuiPaneSize(v, width, height)
+	panX, panY := max(1, paneWidth/4)*v.Zoom, max(1, paneHeight/4)*v.Zoom
+	switch key {
+	case "q", "\x03":
+		return v, steps, true
+	case "up", "w", "k":
+		v.CenterRow = min(util.Rows-1, v.CenterRow+panY)
+	case "down", "s", "j":
+		v.CenterRow = max(0, v.CenterRow-panY)
+	case "left", "a", "h":
+		v.CenterCol = (v.CenterCol - panX + util.Cols) % util.Cols
+	case "right", "d", "l":
+		v.CenterCol = (v.CenterCol + panX) % util.Cols
+	case "+", "=":
+		v.Zoom = max(1, v.Zoom/2)
+	case "-", "_":
+		v.Zoom = min(tuiMaxZoom, v.Zoom*2)
+	case "0":
+		v.Zoom = fitTUIPaneZoom(paneWidth, paneHeight)
+		v.CenterRow, v.CenterCol = util.Rows/2, util.Cols/2
+	case " ":
+		v.Paused = !v.Paused
+	case "n", ".":
+		v.Paused = true
+		steps++
+	case "]":
+		v.Speed = min(maxServeSpeed, v.Speed*2)
+	case "[":
+		v.Speed = max(1, v.Speed/2)
+	case "m":
+		v.Mode = ui.RenderMode((int(v.Mode) + 1) % len(ui.RenderModes()))
+	case "M":
+		modes := len(ui.RenderModes())
+		v.Mode = ui.RenderMode((int(v.Mode) + modes - 1) % modes)
+	}
+	return v, steps, false
+}
+
+func tuiBoardSize(width, height int) (int, int) {
+	return max(1, width-tuiPanelWidth-1), 2 * max(1, height-1)
+}
+
+// tuiPaneSize is the viewport of one game: the whole board area, or half of
+// it less a separator column in split mode.
+func tuiPaneSize(view tuiView, width, height int) (int, int) {
+	boardWidth, boardHeight := tuiBoardSize(width, height)
+	if view.Split {
+		boardWidth = max(1, (boardWidth-1)/2)
+	}
+	return boardWidth, boardHeight
+}
+
+// fitTUIPaneZoom picks the smallest power-of-two zoom that fits the whole
+// board in one pane.
+func fitTUIPaneZoom(paneWidth, paneHeight int) int {
+	zoom := 1
+	for zoom < tuiMaxZoom && ((util.Cols+zoom-1)/zoom > paneWidth || (util.Rows+zoom-1)/zoom > paneHeight) {
+		zoom *= 2
+	}
+	return zoom
+}
+
+// composeTUIPanes draws height-1 terminal lines: one viewport per game,
+// separated by a blank column, with the stats panel to their right, then a
+// key help line. runs labels split games in the panel.
+func composeTUIPanes(games []*game.Game, runs []compareRun, view tuiView, width, height int) string {
+	boardWidth, _ := tuiBoardSize(width, height)
+	paneWidth, paneHeight := tuiPaneSize(view, width, height)
+	pixels := make([]func(x, y int) ([3]float32, bool), len(games))
+	for i, g := range games {
+		pixels[i] = tuiPixels(g.Board, view, paneWidth, paneHeight)
+	}
+
+	panel := tuiPanel(games[0], view)
+	if len(games) > 1 {
+		panel = tuiSplitPanel(games, runs, view)
+	}
+	var b strings.Builder
+	b.Grow(height * width * 24)
+	for line := 0; line < height-1; line++ {
+		used := 0
+		for i, pixel := range pixels {
+			if i > 0 {
+				b.WriteString("\x1b[0m ")
+				used++
+			}
+			for x := 0; x < paneWidth; x++ {
+				writeTUICell(&b, pixel, x, line, view.TrueColor)
+			}
+			used += paneWidth
+		}
+		b.WriteString("\x1b[0m")
+		b.WriteString(strings.Repeat(" ", max(0, boardWidth-used)+1))
+		text := ""
+		if line < len(panel) {
+			text = panel[line]
+		}
+		b.WriteString(fitTUIText(text, tuiPanelWidth))
+		b.WriteString("\x1b[K\r\n")
+	}
+	b.WriteString(fitTUIText("arrows/wasd pan  +/- zoom  0 fit  space pause  n step  [/] speed  m mode  q quit", width))
+	b.WriteString("\x1b[K")
+	return b.String()
+}
+
+// tuiPixels maps viewport block coordinates, y growing downward, to cell or
+// chunk colors of brd; ok is false outside the board.
+func tuiPixels(brd *core.Board, view tuiView, paneWidth, paneHeight int) func(x, y int) ([3]float32, bool) {
+	zoom := max(1, view.Zoom)
+	chunkRows := (util.Rows + zoom - 1) / zoom
+	chunkCols := (util.Cols + zoom - 1) / zoom
+
+	var density map[int][3]float32
+	if zoom > 1 {
+		chunks := ui.BuildModeDensityChunks(brd, zoom, view.Mode)
+		density = make(map[int][3]float32, len(chunks))
+		for _, chunk := range chunks {
+			density[(chunk.Row/zoom)*chunkCols+chunk.Col/zoom] = chunk.Color
+		}
+	}
+	topChunk := view.CenterRow/zoom + paneHeight/2
+	leftChunk := view.CenterCol/zoom - paneWidth/2
+	if chunkCols <= paneWidth {
+		leftChunk = (chunkCols - paneWidth) / 2
+	}
+	return func(x, y int) ([3]float32, bool) {
+		chunkRow := topChunk - y
+		if chunkRow < 0 || chunkRow >= chunkRows {
+			return [3]float32{}, false
+		}
+		chunkCol := leftChunk + x
+		if chunkCols > paneWidth {
+			// Columns wrap, so only wrap once the board is wider than the view.
+			chunkCol = (chunkCol%chunkCols + chunkCols) % chunkCols
+		} else if chunkCol < 0 || chunkCol >= chunkCols {
+			return [3]float32{}, false
+		}
+		if color, ok := density[chunkRow*chunkCols+chunkCol]; ok {
+			return color, true
+		}
+		return ui.CellColor(brd, util.Position{R: chunkRow * zoom, C: chunkCol * zoom}, view.Mode), true
+	}
+}
+
+// writeTUICell draws the two blocks at x on one terminal line as a half block.
+func writeTUICell(b *strings.Builder, pixel func(x, y int) ([3]float32, bool), x, line int, trueColor bool) {
+	top, topOK := pixel(x, 2*line)
+	bottom, bottomOK := pixel(x, 2*line+1)
+	switch {
+	case !topOK && !bottomOK:
+		b.WriteString("\x1b[0m ")
+	case !bottomOK:
+		b.WriteString("\x1b[0m")
+		writeTUIColor(b, 38, top, trueColor)
+		b.WriteString("▀")
+	default:
+		writeTUIColor(b, 38, top, trueColor)
+		writeTUIColor(b, 48, bottom, trueColor)
+		if !topOK {
+			b.WriteString("\x1b[39m ")
+		} else {
+			b.WriteString("▀")
+		}
+	}
+}
+
+func tuiPanel(g *game.Game, view tuiView) []string {
+	state := g.State
+	if state == nil {
+		state = &config.GameState{}
+	}
+	run := "running"
+	if view.Paused {
+		run = "paused"
+	}
+	lines := []string{
+		"golab",
+		fmt.Sprintf("tick       %d", state.LogicTick),
+		fmt.Sprintf("tps        %.1f", state.LogicTicksPerSecond),
+		fmt.Sprintf("live bots  %d", g.Board.ActiveBotCount()),
+		fmt.Sprintf("births     %d", g.SuccessfulDivisions()),
+		fmt.Sprintf("deaths     %d", g.Deaths()),
+		fmt.Sprintf("state      %s x%d", run, view.Speed),
+		fmt.Sprintf("mode       %s", view.Mode.Label()),
+		fmt.Sprintf("zoom       1:%d", view.Zoom),
+		fmt.Sprintf("center     %d,%d", view.CenterRow, view.CenterCol),
+		"",
+	}
+	if phases := state.SlowestTickPhases(3); len(phases) > 0 {
+		lines = append(lines, "ms per tick")
+		for _, phase := range phases {
+			lines = append(lines, fmt.Sprintf("%-11s%.2f", phase.Name, phase.Millis))
+		}
+		lines = append(lines, "")
+	}
+	gm := state.GameMaster
+	if !gm.Enabled {
+		return append(lines, "game master off")
+	}
+	lines = append(lines,
+		"game master "+gm.Name,
+		fmt.Sprintf("every      %d ticks", gm.Interval),
+		fmt.Sprintf("observed   %d", gm.LastObserved),
+		fmt.Sprintf("colonies   %d", gm.Colonies),
+		fmt.Sprintf("food       %d", gm.Food),
+		fmt.Sprintf("resources  %d", gm.Resources),
+		fmt.Sprintf("poison     %d", gm.Poison),
+		fmt.Sprintf("water      %d", gm.Water),
+	)
+	if gm.LastEventKind != "" {
+		lines = append(lines,
+			fmt.Sprintf("last event %s @%d", gm.LastEventKind, gm.LastEventTick),
+			fmt.Sprintf("applied    %d at %d,%d", gm.LastApplied, gm.LastCenterRow, gm.LastCenterCol),
+		)
+	}
+	for _, text := range []string{gm.LastThought, gm.LastReason} {
+		if text != "" {
+			lines = append(lines, wrapTUIText(text, tuiPanelWidth)...)
+		}
+	}
+	return lines
+}
+
+// tuiSplitPanel compares the two split games column by column.
+func tuiSplitPanel(games []*game.Game, runs []compareRun, view tuiView) []string {
+	row := func(label string, value func(int) string) string {
+		return fmt.Sprintf("%-10s %-9s %s", label, value(0), value(1))
+	}
+	run := "running"
+	if view.Paused {
+		run = "paused"
+	}
+	lines := []string{
+		"golab compare",
+		row("", func(i int) string { return string(rune('A' + i)) }),
+		row("seed", func(i int) string { return strconv.FormatInt(runs[i].Seed, 10) }),
+		row("config", func(i int) string {
+			if runs[i].ConfigPath == "" {
+				return "default"
+			}
+			return filepath.Base(runs[i].ConfigPath)
+		}),
+		row("tick", func(i int) string { return strconv.Itoa(games[i].State.LogicTick) }),
+		row("live bots", func(i int) string { return strconv.Itoa(games[i].Board.ActiveBotCount()) }),
+		row("births", func(i int) string { return strconv.Itoa(games[i].SuccessfulDivisions()) }),
+		row("deaths", func(i int) string { return strconv.Itoa(games[i].Deaths()) }),
+		row("colonies", func(i int) string { return strconv.Itoa(activeColonySizes(games[i].Board).active) }),
+		"",
+		fmt.Sprintf("state      %s x%d", run, view.Speed),
+		fmt.Sprintf("mode       %s", view.Mode.Label()),
+		fmt.Sprintf("zoom       1:%d", view.Zoom),
+		fmt.Sprintf("center     %d,%d", view.CenterRow, view.CenterCol),
+	}
+	return lines
+}
+
+func writeTUIColor(b *strings.Builder, layer int, color [3]float32, trueColor bool) {
+	r, g, bl := tuiChannel(color[0], 255), tuiChannel(color[1], 255), tuiChannel(color[2], 255)
+	if trueColor {
+		fmt.Fprintf(b, "\x1b[%d;2;%d;%d;%dm", layer, r, g, bl)
+		return
+	}
+	cube := 16 + 36*tuiChannel(color[0], 5) + 6*tuiChannel(color[1], 5) + tuiChannel(color[2], 5)
+	fmt.Fprintf(b, "\x1b[%d;5;%dm", layer, cube)
+}
+
+func tuiChannel(value float32, levels int) int {
+	return int(min(max(value, 0), 1)*float32(levels) + 0.5)
+}
+
+func fitTUIText(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return text + strings.Repeat(" ", width-len(runes))
+}
+
+func wrapTUIText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// terminalSize asks stty for the controlling terminal size, falling back to
+// COLUMNS/LINES and then a fixed default.
+func terminalSize() (int, int) {
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = os.Stdin
+	if out, err := cmd.Output(); err == nil {
+		var rows, cols int
+		if _, err := fmt.Sscan(string(out), &rows, &cols); err == nil && rows > 0 && cols > 0 {
+			return cols, rows
+		}
+	}
+	width, errW := strconv.Atoi(os.Getenv("COLUMNS"))
+	height, errH := strconv.Atoi(os.Getenv("LINES"))
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return tuiDefaultWidth, tuiDefaultHeight
+	}
+	return width, height
+}
+
+func enterRawTerminal() (func(), error) {
+	save := exec.Command("stty", "-g")
+	save.Stdin = os.Stdin
+	saved, err := save.Output()
+	if err != nil {
+		return nil, err
+	}
+	raw := exec.Command("stty", "raw", "-echo")
+	raw.Stdin = os.Stdin
+	if err := raw.Run(); err != nil {
+		return nil, err
+	}
+	return func() {
+		restore := exec.Command("stty", strings.TrimSpace(string(saved)))
+		restore.Stdin = os.Stdin
+		restore.Run()
+	}, nil
+}
+
+func readTUIKeys(keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, key := range splitTUIKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// splitTUIKeys turns raw terminal input into key names, decoding the arrow
+// escape sequences and passing other bytes through as single characters.
+func splitTUIKeys(input []byte) []string {
+	arrows := map[byte]string{'A': "up", 'B': "down", 'C': "right", 'D': "left"}
+	var keys []string
+	for i := 0; i < len(input); i++ {
+		if input[i] == 0x1b && i+2 < len(input) && (input[i+1] == '[' || input[i+1] == 'O') {
+			if name, ok := arrows[input[i+2]]; ok {
+				keys = append(keys, name)
+				i += 2
+				continue
+			}
+		}
+		keys = append(keys, string(input[i]))
+	}
+	return keys
+}
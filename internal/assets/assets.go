@@ -0,0 +1,136 @@
+// Package assets loads the font and sprites shared by the window and the
+// offline renderer. Files come from an optional override directory first and
+// fall back to the copies embedded in the binary.
+package assets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golab/assests"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	Font  = "font.ttf"
+	Atlas = "sprites/atlas.png"
+	Bot   = "sprites/bot.jpg"
+
+	// AtlasTiles is how many tiles the renderers index into: food, wall, ore,
+	// poison, chest, farm, spawner, light, bot, dark and flag, in that order.
+	AtlasTiles = 11
+)
+
+var dir string
+
+// SetDir makes path a texture pack: files it holds, laid out like the
+// bundled assets (font.ttf, sprites/atlas.png, sprites/bot.jpg), replace the
+// embedded ones. A pack atlas is validated up front. An empty path goes back
+// to the embedded assets only.
+func SetDir(path string) error {
+	if path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("assets path is not a directory: %s", path)
+		}
+		if _, err := os.Stat(filepath.Join(path, filepath.FromSlash(Atlas))); err == nil {
+			if _, _, err := LoadAtlasFile(filepath.Join(path, filepath.FromSlash(Atlas))); err != nil {
+				return err
+			}
+		}
+	}
+	dir = path
+	return nil
+}
+
+// Dir returns the override directory, or "" when only embedded assets are
+// used.
+func Dir() string {
+	return dir
+}
+
+// Open opens name from the override directory, or from the embedded assets
+// when the directory is unset or lacks it.
+func Open(name string) (io.ReadCloser, error) {
+	if dir != "" {
+		file, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return assests.Files.Open(name)
+}
+
+func ReadFile(name string) ([]byte, error) {
+	file, err := Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// Image decodes the PNG or JPEG asset name.
+func Image(name string) (image.Image, error) {
+	data, err := ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return img, nil
+}
+
+// LoadAtlas loads the sprite atlas and returns it with its tile size.
+func LoadAtlas() (*image.RGBA, int, error) {
+	img, err := Image(Atlas)
+	if err != nil {
+		return nil, 0, err
+	}
+	return validateAtlas(img, Atlas)
+}
+
+// LoadAtlasFile loads an atlas from an explicit path instead of the assets.
+func LoadAtlasFile(path string) (*image.RGBA, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return validateAtlas(img, path)
+}
+
+// validateAtlas checks for square tiles in a single row, at least AtlasTiles
+// of them.
+func validateAtlas(src image.Image, name string) (*image.RGBA, int, error) {
+	bounds := src.Bounds()
+	tileSize := bounds.Dy()
+	if tileSize <= 0 || bounds.Dx()%tileSize != 0 {
+		return nil, 0, fmt.Errorf("atlas must contain square tiles in a single row: %s", name)
+	}
+	if tiles := bounds.Dx() / tileSize; tiles < AtlasTiles {
+		return nil, 0, fmt.Errorf("atlas has %d tiles, need at least %d: %s", tiles, AtlasTiles, name)
+	}
+	atlas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(atlas, atlas.Bounds(), src, bounds.Min, draw.Src)
+	return atlas, tileSize, nil
+}
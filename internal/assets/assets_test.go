@@ -0,0 +1,77 @@
+package assets
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeAtlas writes a w by h atlas into dir's sprites folder and returns its
+// path.
+func writeAtlas(t *testing.T, dir string, w, h int) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(Atlas))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 200, A: 255})
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEmbeddedAssetsAndTexturePackOverride(t *testing.T) {
+	t.Cleanup(func() { _ = SetDir("") })
+
+	atlas, tileSize, err := LoadAtlas()
+	if err != nil || tileSize != 32 || atlas.Bounds().Dx() != AtlasTiles*32 {
+		t.Fatalf("embedded atlas = %v tile %d err %v, want %d tiles of 32px", atlas.Bounds(), tileSize, err, AtlasTiles)
+	}
+	font, err := ReadFile(Font)
+	if err != nil || len(font) == 0 {
+		t.Fatalf("embedded font: %d bytes, err %v", len(font), err)
+	}
+	if _, err := Image(Bot); err != nil {
+		t.Fatalf("embedded bot sprite: %v", err)
+	}
+
+	pack := t.TempDir()
+	writeAtlas(t, pack, (AtlasTiles+1)*8, 8)
+	if err := SetDir(pack); err != nil {
+		t.Fatalf("SetDir(pack): %v", err)
+	}
+	atlas, tileSize, err = LoadAtlas()
+	if err != nil || tileSize != 8 || atlas.RGBAAt(0, 0).R != 200 {
+		t.Fatalf("pack atlas = %v tile %d err %v, want the pack's 8px atlas", atlas.Bounds(), tileSize, err)
+	}
+	if packFont, err := ReadFile(Font); err != nil || len(packFont) != len(font) {
+		t.Fatalf("font missing from the pack did not fall back to the embedded one: %v", err)
+	}
+
+	short := t.TempDir()
+	writeAtlas(t, short, (AtlasTiles-1)*8, 8)
+	if err := SetDir(short); err == nil || !strings.Contains(err.Error(), "need at least 11") {
+		t.Fatalf("SetDir with a 10-tile atlas = %v, want a tile count error", err)
+	}
+	if Dir() != pack {
+		t.Fatalf("rejected pack replaced the override: %q", Dir())
+	}
+	if err := SetDir(filepath.Join(pack, filepath.FromSlash(Atlas))); err == nil {
+		t.Fatal("SetDir accepted a file")
+	}
+
+	if _, _, err := LoadAtlasFile(writeAtlas(t, t.TempDir(), 90, 8)); err == nil || !strings.Contains(err.Error(), "square tiles") {
+		t.Fatalf("ragged atlas = %v, want the square tile error", err)
+	}
+}
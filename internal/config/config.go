@@ -1,8 +1,13 @@
 package config
 
 import (
+	"cmp"
 	"encoding/json"
+	"fmt"
 	"os"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -10,7 +15,24 @@ type GameState struct {
 	LastLogic           time.Time
 	LogicTick           int
 	LogicTicksPerSecond float64
-	GameMaster          GameMasterState
+	// TickPhases is the average wall time per logic tick of each phase of the
+	// tick, in tick order, over the same window as LogicTicksPerSecond.
+	TickPhases []TickPhaseTime
+	GameMaster GameMasterState
+}
+
+type TickPhaseTime struct {
+	Name   string
+	Millis float64
+}
+
+// SlowestTickPhases returns up to n phases from TickPhases, slowest first.
+func (s *GameState) SlowestTickPhases(n int) []TickPhaseTime {
+	phases := slices.Clone(s.TickPhases)
+	slices.SortStableFunc(phases, func(a, b TickPhaseTime) int {
+		return cmp.Compare(b.Millis, a.Millis)
+	})
+	return phases[:min(n, len(phases))]
 }
 
 type GameMasterState struct {
@@ -314,3 +336,39 @@ func LoadFromJson(file string) Config {
 	json.NewDecoder(confJson).Decode(&conf)
 	return conf
 }
+
+// IntField returns the integer config value stored under its JSON name.
+func (c *Config) IntField(name string) (int, bool) {
+	field, ok := c.intFieldByName(name)
+	if !ok {
+		return 0, false
+	}
+	return int(field.Int()), true
+}
+
+// SetIntField updates the integer config value stored under its JSON name.
+func (c *Config) SetIntField(name string, value int) error {
+	field, ok := c.intFieldByName(name)
+	if !ok {
+		return fmt.Errorf("unknown integer config field %q", name)
+	}
+	field.SetInt(int64(value))
+	return nil
+}
+
+func (c *Config) intFieldByName(name string) (reflect.Value, bool) {
+	value := reflect.ValueOf(c).Elem()
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if tag != name {
+			continue
+		}
+		field := value.Field(i)
+		if field.Kind() != reflect.Int {
+			return reflect.Value{}, false
+		}
+		return field, true
+	}
+	return reflect.Value{}, false
+}
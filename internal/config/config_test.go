@@ -104,3 +104,24 @@ func TestColonyOrganismDefaults(t *testing.T) {
 		t.Fatalf("unexpected colony organism defaults: %+v", cfg)
 	}
 }
+
+func TestIntFieldUsesJSONNames(t *testing.T) {
+	cfg := NewConfig()
+
+	got, ok := cfg.IntField("colonySpawnerBirthPeriod")
+	if !ok || got != cfg.ColonySpawnerBirthPeriod {
+		t.Fatalf("colonySpawnerBirthPeriod = %d/%v, want %d/true", got, ok, cfg.ColonySpawnerBirthPeriod)
+	}
+	if err := cfg.SetIntField("pheromoneDecay", 7); err != nil {
+		t.Fatalf("set pheromoneDecay: %v", err)
+	}
+	if cfg.PheromoneDecay != 7 {
+		t.Fatalf("pheromone decay = %d, want 7", cfg.PheromoneDecay)
+	}
+	if _, ok := cfg.IntField("pheromonesEnabled"); ok {
+		t.Fatalf("bool field should not resolve as an integer field")
+	}
+	if err := cfg.SetIntField("missingField", 1); err == nil {
+		t.Fatalf("set missing field should fail")
+	}
+}
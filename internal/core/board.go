@@ -3,8 +3,6 @@ package core
 import (
 	"golab/internal/util"
 	"sync"
-
-	"golang.org/x/exp/rand"
 )
 
 type Occupant any
@@ -73,8 +71,12 @@ type Board struct {
 	pathsToRenderMask []bool
 	unreachablesMask  []bool
 
-	grid                []Occupant
-	occupied            []bool
+	kinds               []CellKind
+	amount              []int32
+	extra               []int32
+	refIdx              []int32
+	refs                []cellRefs
+	freeRefs            []int32
 	dirty               []bool
 	frozen              []bool
 	biomes              []Biome
@@ -82,6 +84,10 @@ type Board struct {
 	pheromoneHomeOwner  []*Colony
 	pheromoneActive     []int
 	pheromoneActiveMask []bool
+	pheromoneScratch    pheromoneScratch
+	spatial             spatialIndex
+	passableVersion     uint64
+	chunkPassable       []uint32
 	Bots                []*Bot
 	activeBotIDs        []BotID
 	botAtCell           []BotID
@@ -93,6 +99,9 @@ type Board struct {
 	activeEnvSorted     []int
 	envActiveIndex      []int
 	activeEnvOrderDirty bool
+	deaths              []DeathCause
+	heat                [][]uint32
+	heatMax             [numHeatLayers]uint32
 	// colonyCells []ColonyCell
 	patch []int
 }
@@ -219,8 +228,11 @@ func NewBoard() *Board {
 		taskTargetsMask:     make([]bool, util.Cells),
 		pathsToRenderMask:   make([]bool, util.Cells),
 		unreachablesMask:    make([]bool, util.Cells),
-		grid:                make([]Occupant, Rows*Cols),
-		occupied:            make([]bool, Rows*Cols),
+		kinds:               make([]CellKind, Rows*Cols),
+		amount:              make([]int32, Rows*Cols),
+		extra:               make([]int32, Rows*Cols),
+		refIdx:              make([]int32, Rows*Cols),
+		refs:                make([]cellRefs, 1),
 		dirty:               make([]bool, Rows*Cols),
 		frozen:              make([]bool, Rows*Cols),
 		biomes:              make([]Biome, Rows*Cols),
@@ -230,6 +242,8 @@ func NewBoard() *Board {
 		Bots:                make([]*Bot, util.Cells),
 		botAtCell:           make([]BotID, util.Cells),
 		envActiveIndex:      make([]int, util.Cells),
+		spatial:             newSpatialIndex(),
+		chunkPassable:       make([]uint32, Chunks),
 	}
 	for i := range b.botAtCell {
 		b.botAtCell[i] = NoBotID
@@ -276,11 +290,7 @@ func (b *Board) isMarked(mask []bool, pos Position) bool {
 }
 
 func NewRandomPosition() Position {
-	return Position{C: rand.Intn(Cols), R: rand.Intn(Rows)}
-}
-
-func (b *Board) GetGrid() *[]Occupant {
-	return &b.grid
+	return Position{C: util.BoardRandIntn(Cols), R: util.BoardRandIntn(Rows)}
 }
 
 func idx(p Position) int {
@@ -294,9 +304,8 @@ func (b *Board) Clear(pos Position) {
 	i := idx(pos)
 	b.unregisterBotAtIdx(i)
 	b.unmarkEnvironmentActive(i)
-	b.occupied[i] = false
 	b.MarkDirty(i)
-	b.grid[i] = nil
+	b.clearCell(i)
 }
 
 func (b *Board) Set(pos Position, o Occupant) {
@@ -312,11 +321,7 @@ func (b *Board) Set(pos Position, o Occupant) {
 		b.setBotAtIdx(i, pos, bot)
 		return
 	}
-	b.unregisterBotAtIdx(i)
-	b.updateEnvironmentActive(i, o)
-	b.occupied[i] = true
-	b.MarkDirty(i)
-	b.grid[i] = o
+	b.SetCellIdx(i, cellOf(o))
 }
 
 func (b *Board) IsEmptyNoBot(pos Position) bool {
@@ -324,7 +329,7 @@ func (b *Board) IsEmptyNoBot(pos Position) bool {
 		return false
 	}
 
-	return b.grid[idx(pos)] == nil
+	return b.kinds[idx(pos)] == CellEmpty
 }
 
 func (b *Board) IsEmpty(pos Position) bool {
@@ -332,19 +337,21 @@ func (b *Board) IsEmpty(pos Position) bool {
 		return false
 	}
 
-	return b.grid[idx(pos)] == nil
+	return b.kinds[idx(pos)] == CellEmpty
 }
 
+// At returns the occupant of pos as a value (or *Bot). Hot loops should read
+// KindAtIdx and CellAtIdx instead, which do not allocate.
 func (b *Board) At(pos Position) Occupant {
 	if pos.R < 0 || pos.R >= Rows {
 		return nil
 	}
-	return b.grid[idx(pos)]
+	return b.occupantAt(idx(pos))
 }
 
 func (b *Board) IsPreserved(o Occupant) bool {
 	switch o.(type) {
-	case Controller, *Controller, Farm, Food, Poison, Building, Water, Depot, *Depot:
+	case Controller, Farm, Food, Poison, Building, Water, Depot:
 		return true
 	default:
 		return false
@@ -356,10 +363,10 @@ func Inside(p Position) bool {
 }
 
 func (b *Board) firstEmptyAround(idx int) int {
-	start := rand.Intn(8)
+	start := util.BoardRandIntn(8)
 	for i := range 8 {
 		n := neighbourIdx[idx][(start+i)&7]
-		if n >= 0 && !b.occupied[n] {
+		if n >= 0 && b.kinds[n] == CellEmpty {
 			return n
 		}
 	}
@@ -375,8 +382,8 @@ func (b *Board) FindEmptyPosAround(p Position) (Position, bool) {
 }
 
 func (b *Board) IsGrabable(pos Position) bool {
-	switch b.At(pos).(type) {
-	case Farm, Food, Poison, Controller, *Controller, Resource, Building, Spawner, Depot, *Depot:
+	switch b.KindAt(pos) {
+	case CellFarm, CellFood, CellPoison, CellController, CellResource, CellBuilding, CellSpawner, CellDepot:
 		return true
 	default:
 		return false
@@ -423,5 +430,21 @@ func (b *Board) IsEmptyOrBotIdx(i int) bool {
 	if i < Cols || i >= (Rows-1)*Cols {
 		return false
 	}
-	return b.grid[i] == nil || b.Bots[i] != nil
+	return b.kinds[i] == CellEmpty || b.Bots[i] != nil
+}
+
+// PassableVersion counts the cell writes that changed IsEmptyOrBotIdx, so
+// anything derived from walkable cells can tell when it is stale. Bots moving
+// between empty cells do not count.
+func (b *Board) PassableVersion() uint64 {
+	return b.passableVersion
+}
+
+// ChunkPassableVersion is PassableVersion for the cells of one chunk.
+func (b *Board) ChunkPassableVersion(chunk int) uint32 {
+	return b.chunkPassable[chunk]
+}
+
+func passableKind(kind CellKind) bool {
+	return kind == CellEmpty || kind == CellBot
 }
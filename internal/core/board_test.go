@@ -2,7 +2,10 @@ package core
 
 import (
 	"golab/internal/util"
+	"math"
+	"math/rand"
 	"slices"
+	"sort"
 	"testing"
 )
 
@@ -98,6 +101,149 @@ func TestActiveBotRegistryTracksAddMoveAndRemove(t *testing.T) {
 	}
 }
 
+func TestMoveBotLocalOnlyEntersEmptyCellsAndDefersDirty(t *testing.T) {
+	brd := NewBoard()
+	start := util.NewPos(20, 20)
+	next := util.NewPos(21, 20)
+	wall := util.NewPos(20, 21)
+	bot := NewBot(start)
+	brd.AddBot(start, &bot)
+	brd.Set(wall, Wall{Pos: wall})
+	id := brd.BotIDOf(&bot)
+	brd.PullPatch()
+
+	if dirty, ok := brd.MoveBotLocal(start, wall, &bot, nil); ok || len(dirty) != 0 {
+		t.Fatalf("MoveBotLocal into wall = %v, %v; want no move", dirty, ok)
+	}
+	dirty, ok := brd.MoveBotLocal(start, next, &bot, nil)
+	if !ok {
+		t.Fatal("MoveBotLocal into empty cell returned false")
+	}
+	if want := []int{util.Idx(next), util.Idx(start)}; !slices.Equal(dirty, want) {
+		t.Fatalf("dirty = %v, want %v", dirty, want)
+	}
+	if got := brd.PullPatch(); len(got) != 0 {
+		t.Fatalf("patch after local move = %v, want empty", got)
+	}
+	if brd.GetBot(next) != &bot || bot.Pos != next || brd.BotCell(id) != util.Idx(next) {
+		t.Fatalf("bot not registered at %v after local move", next)
+	}
+	if !brd.IsEmpty(start) || brd.GetBot(start) != nil {
+		t.Fatal("old cell still holds the bot")
+	}
+	if got := brd.ActiveBotCount(); got != 1 {
+		t.Fatalf("active bot count = %d, want 1", got)
+	}
+}
+
+func TestSpatialIndexMatchesScansThroughBoardWrites(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	brd := NewBoard()
+	var bots []*Bot
+	randomPos := func() Position {
+		return util.NewPos(1+rng.Intn(Rows-2), rng.Intn(Cols))
+	}
+	for round := range 6 {
+		for range 400 {
+			pos := randomPos()
+			switch rng.Intn(9) {
+			case 0, 1:
+				if brd.IsEmpty(pos) {
+					bot := NewBot(pos)
+					brd.AddBot(pos, &bot)
+					bots = append(bots, &bot)
+				}
+			case 2:
+				brd.Set(pos, Controller{Pos: pos, Amount: 1})
+			case 3:
+				brd.Set(pos, Depot{Pos: pos})
+			case 4:
+				brd.Set(pos, Spawner{Pos: pos})
+			case 5:
+				brd.Set(pos, Farm{Pos: pos})
+			case 6:
+				brd.Set(pos, Food{Pos: pos, Amount: 1})
+			case 7:
+				brd.Set(pos, nil)
+			case 8:
+				if bot := brd.GetBot(pos); bot != nil {
+					brd.RemoveBotAt(pos)
+				}
+			}
+		}
+		var dirty []int
+		for _, bot := range bots {
+			if brd.BotIDOf(bot) == NoBotID {
+				continue
+			}
+			next := bot.Pos.AddDir(Dirs[rng.Intn(len(Dirs))])
+			if round%2 == 0 {
+				brd.MoveBot(bot.Pos, next, bot)
+			} else {
+				dirty, _ = brd.MoveBotLocal(bot.Pos, next, bot, dirty)
+			}
+		}
+		brd.FlushLocalMoves(dirty)
+		if round == 5 {
+			brd = brd.Snapshot(NewCloner(0)).Restore(NewCloner(0))
+		}
+
+		for _, kind := range []CellKind{CellBot, CellController, CellDepot, CellSpawner, CellFarm, CellFood} {
+			var want []int
+			for i, other := range brd.Kinds() {
+				if other == kind {
+					want = append(want, i)
+				}
+			}
+			if got := slices.Collect(brd.CellsOfKind(kind)); !slices.Equal(got, want) {
+				t.Fatalf("round %d: CellsOfKind(%v) has %d cells, want %d", round, kind, len(got), len(want))
+			}
+			if got := brd.CountOfKind(kind); got != len(want) {
+				t.Fatalf("round %d: CountOfKind(%v) = %d, want %d", round, kind, got, len(want))
+			}
+			for _, radius := range []int{0, 3, 19, 20, 45, Cols / 2} {
+				center := util.NewPos(rng.Intn(Rows), rng.Intn(Cols))
+				want := scanRadiusReference(brd, kind, center, radius)
+				if got := slices.Collect(brd.InRadius(kind, center, radius)); !slices.Equal(got, want) {
+					t.Fatalf("round %d: InRadius(%v, %v, %d) = %v, want %v", round, kind, center, radius, got, want)
+				}
+				gotNear, gotOK := brd.NearestOfKind(kind, center, radius, nil)
+				wantNear, wantOK := nearestReference(want, center)
+				if gotNear != wantNear || gotOK != wantOK {
+					t.Fatalf("round %d: NearestOfKind(%v, %v, %d) = %d, %v; want %d, %v", round, kind, center, radius, gotNear, gotOK, wantNear, wantOK)
+				}
+			}
+		}
+	}
+}
+
+func scanRadiusReference(brd *Board, kind CellKind, center Position, radius int) []int {
+	var out []int
+	for r := center.R - radius; r <= center.R+radius; r++ {
+		if r < 0 || r >= Rows {
+			continue
+		}
+		for dc := -radius; dc <= radius; dc++ {
+			if i := util.Idx(util.NewPos(r, center.C+dc)); brd.KindAtIdx(i) == kind {
+				out = append(out, i)
+			}
+		}
+	}
+	return out
+}
+
+func nearestReference(cells []int, center Position) (int, bool) {
+	best, bestDist := -1, 0
+	for _, i := range cells {
+		pos := util.PosOf(i)
+		dist := max(absInt(pos.R-center.R), toroidalColDistance(pos.C, center.C))
+		if best < 0 || dist < bestDist || (dist == bestDist && i < best) {
+			best, bestDist = i, dist
+		}
+	}
+	return best, best >= 0
+}
+
 func TestSortedActiveEnvironmentCellsInvalidatesOnSetAndClear(t *testing.T) {
 	brd := NewBoard()
 	low := util.NewPos(10, 10)
@@ -407,6 +553,426 @@ func TestCopyPheromonesPreservesLayerAndOwners(t *testing.T) {
 	}
 }
 
+func TestPheromoneDecayAndDiffusionMatchReferenceAcrossSeeds(t *testing.T) {
+	banded := false
+	for seed := int64(1); seed <= 4; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		ref, serial, parallel := NewBoard(), NewBoard(), NewBoard()
+		boards := []*Board{ref, serial, parallel}
+		colonies := []*Colony{nil, {}, {}, {}}
+		for range 300 {
+			pos := util.PosOf(rng.Intn(util.Cells))
+			for _, brd := range boards {
+				brd.Set(pos, Water{GroupId: 1, Amount: 1})
+			}
+		}
+
+		for round := range 10 {
+			for range 3000 {
+				pos := util.PosOf(rng.Intn(util.Cells))
+				channel := PheromoneChannel(rng.Intn(int(pheromoneChannelCount)))
+				amount := 1 + rng.Intn(255)
+				owner := colonies[rng.Intn(len(colonies))]
+				for _, brd := range boards {
+					brd.DepositPheromone(pos, channel, amount, owner)
+				}
+			}
+			if len(parallel.pheromoneActive) >= 2*pheromoneMinBand {
+				banded = true
+			}
+
+			if round%2 == 0 {
+				amount := 1 + rng.Intn(3)
+				want := referenceDecayPheromones(ref, amount)
+				if got := serial.DecayPheromones(amount); got != want {
+					t.Fatalf("seed %d round %d: serial decay changed %d, want %d", seed, round, got, want)
+				}
+				if got := parallel.DecayPheromonesParallel(amount, 4); got != want {
+					t.Fatalf("seed %d round %d: parallel decay changed %d, want %d", seed, round, got, want)
+				}
+				assertPheromoneBoardsEqual(t, seed, round, "decay", ref, serial, parallel)
+			}
+
+			amount := 1 + rng.Intn(4)
+			want := referenceDiffusePheromones(ref, amount)
+			if got := serial.DiffusePheromones(amount); got != want {
+				t.Fatalf("seed %d round %d: serial diffusion changed %d, want %d", seed, round, got, want)
+			}
+			if got := parallel.DiffusePheromonesParallel(amount, 4); got != want {
+				t.Fatalf("seed %d round %d: parallel diffusion changed %d, want %d", seed, round, got, want)
+			}
+			assertPheromoneBoardsEqual(t, seed, round, "diffusion", ref, serial, parallel)
+		}
+	}
+	if !banded {
+		t.Fatal("active set never grew large enough to split into bands")
+	}
+}
+
+func assertPheromoneBoardsEqual(t *testing.T, seed int64, round int, step string, want *Board, others ...*Board) {
+	t.Helper()
+	wantPatch := want.PullPatch()
+	for _, got := range others {
+		switch {
+		case !slices.Equal(got.pheromones, want.pheromones):
+			t.Fatalf("seed %d round %d: pheromones differ after %s", seed, round, step)
+		case !slices.Equal(got.pheromoneHomeOwner, want.pheromoneHomeOwner):
+			t.Fatalf("seed %d round %d: home owners differ after %s", seed, round, step)
+		case !slices.Equal(got.pheromoneActive, want.pheromoneActive):
+			t.Fatalf("seed %d round %d: active lists differ after %s", seed, round, step)
+		case !slices.Equal(got.pheromoneActiveMask, want.pheromoneActiveMask):
+			t.Fatalf("seed %d round %d: active masks differ after %s", seed, round, step)
+		case !slices.Equal(got.PullPatch(), wantPatch):
+			t.Fatalf("seed %d round %d: dirty patches differ after %s", seed, round, step)
+		}
+	}
+}
+
+// referenceDecayPheromones is the original single-pass decay, kept as
+// the oracle for the banded implementation.
+func referenceDecayPheromones(b *Board, amount int) int {
+	if amount <= 0 {
+		return 0
+	}
+	changed := 0
+	write := 0
+	active := b.pheromoneActive
+	for _, i := range active {
+		if i < 0 || i >= len(b.pheromones) || !b.pheromoneActiveMask[i] {
+			continue
+		}
+		before := b.pheromones[i]
+		for channel := PheromoneChannel(0); channel < pheromoneChannelCount; channel++ {
+			b.pheromones[i][channel] = decayPheromoneValue(
+				b.pheromones[i][channel],
+				b.adjustedPheromoneDecay(i, channel, amount),
+			)
+		}
+		if b.pheromones[i][PheromoneHome] == 0 {
+			b.pheromoneHomeOwner[i] = nil
+		}
+		if before != b.pheromones[i] {
+			changed++
+			b.MarkDirty(i)
+		}
+		if b.pheromoneCellNonZero(i) {
+			b.pheromoneActive[write] = i
+			write++
+			continue
+		}
+		b.pheromoneActiveMask[i] = false
+		b.pheromoneHomeOwner[i] = nil
+	}
+	b.pheromoneActive = b.pheromoneActive[:write]
+	return changed
+}
+
+// referenceDiffusePheromones is the original scatter-into-maps diffusion,
+// kept as the oracle for the gather implementation.
+func referenceDiffusePheromones(b *Board, amount int) int {
+	if amount <= 0 {
+		return 0
+	}
+	type deltaCell [pheromoneChannelCount]int
+
+	active := append([]int(nil), b.pheromoneActive...)
+	sort.Ints(active)
+	deltas := make(map[int]deltaCell, len(active)*2)
+	homeOwners := make(map[int]*Colony, len(active))
+	for _, i := range active {
+		if i < 0 || i >= len(b.pheromones) || !b.pheromoneActiveMask[i] {
+			continue
+		}
+		neighbors, neighborCount := cardinalNeighborIndexes(i)
+		if neighborCount == 0 {
+			continue
+		}
+		source := b.pheromones[i]
+		for channel := PheromoneChannel(0); channel < pheromoneChannelCount; channel++ {
+			totalOut := amount * neighborCount
+			if int(source[channel]) <= totalOut {
+				continue
+			}
+			sourceDelta := deltas[i]
+			sourceDelta[channel] -= totalOut
+			deltas[i] = sourceDelta
+			for neighborIdx := 0; neighborIdx < neighborCount; neighborIdx++ {
+				n := neighbors[neighborIdx]
+				neighborDelta := deltas[n]
+				neighborDelta[channel] += amount
+				deltas[n] = neighborDelta
+				if channel == PheromoneHome && b.pheromoneHomeOwner[i] != nil {
+					homeOwners[n] = b.pheromoneHomeOwner[i]
+				}
+			}
+		}
+	}
+
+	changed := 0
+	deltaCells := make([]int, 0, len(deltas))
+	for i := range deltas {
+		deltaCells = append(deltaCells, i)
+	}
+	sort.Ints(deltaCells)
+	for _, i := range deltaCells {
+		delta := deltas[i]
+		if i < 0 || i >= len(b.pheromones) {
+			continue
+		}
+		before := b.pheromones[i]
+		for channel := PheromoneChannel(0); channel < pheromoneChannelCount; channel++ {
+			b.pheromones[i][channel] = cappedPheromone(int(b.pheromones[i][channel]) + delta[channel])
+		}
+		if owner := homeOwners[i]; owner != nil && before[PheromoneHome] == 0 {
+			b.pheromoneHomeOwner[i] = owner
+		}
+		if b.pheromones[i][PheromoneHome] == 0 {
+			b.pheromoneHomeOwner[i] = nil
+		}
+		if before == b.pheromones[i] {
+			continue
+		}
+		changed++
+		if b.pheromoneCellNonZero(i) {
+			b.markPheromoneActive(i)
+		} else {
+			b.pheromoneActiveMask[i] = false
+			b.pheromoneHomeOwner[i] = nil
+		}
+		b.MarkDirty(i)
+	}
+	b.compactPheromoneActive()
+	return changed
+}
+
+func TestTypedCellsRoundTripThroughAtAndSet(t *testing.T) {
+	brd := NewBoard()
+	owner := NewBot(util.NewPos(30, 30))
+	colony := NewColony(util.NewPos(30, 30))
+	at := func(c int) util.Position { return util.NewPos(10, c) }
+	occupants := []Occupant{
+		Wall{Pos: at(1)},
+		Resource{Pos: at(2), Amount: 7},
+		Water{GroupId: 3, Amount: 10000},
+		Organics{Pos: at(4), Amount: 5},
+		Food{Pos: at(5), Amount: 1},
+		Farm{Pos: at(6), Owner: &owner, Colony: &colony, Amount: 4},
+		Spawner{Pos: at(7), Owner: &owner, Colony: &colony, Amount: 2, AutoBirth: true},
+		Mine{Pos: at(8), Owner: &owner, Amount: 90},
+		Poison{Pos: at(9)},
+		Building{Pos: at(10), Owner: &owner, Hp: 12},
+		Depot{Pos: at(11), Owner: &owner, Colony: &colony, Food: 6, Ore: 8},
+		Controller{Pos: at(12), Colony: &colony, Owner: &owner, Amount: 40, WaterAmount: 9},
+		ColonyFlag{Pos: at(13)},
+	}
+	for i, o := range occupants {
+		brd.Set(at(i+1), o)
+	}
+	for i, want := range occupants {
+		if got := brd.At(at(i + 1)); got != want {
+			t.Fatalf("At(%v) = %#v, want %#v", at(i+1), got, want)
+		}
+		if kind := brd.KindAt(at(i + 1)); kind != CellKind(i+2) {
+			t.Fatalf("KindAt(%v) = %v, want %v", at(i+1), kind, CellKind(i+2))
+		}
+	}
+	if cell := brd.CellAtIdx(idx(at(11))); cell.Amount != 6 || cell.Extra != 8 || cell.Owner != &owner || cell.Colony != &colony {
+		t.Fatalf("depot cell = %+v, want food 6 ore 8 with owner and colony", cell)
+	}
+
+	brd.Set(at(12), Controller{Colony: &colony, Amount: 41})
+	if ctrl, ok := brd.At(at(12)).(Controller); !ok || ctrl.Amount != 41 {
+		t.Fatalf("controller stored as %#v, want a Controller value with amount 41", brd.At(at(12)))
+	}
+	env := brd.ActiveEnvironmentCells()
+	for _, i := range []int{4, 6, 7, 9, 11, 12} {
+		if !slices.Contains(env, idx(at(i))) {
+			t.Fatalf("%v not tracked for environment ticks", brd.KindAt(at(i)))
+		}
+	}
+	if len(env) != 6 {
+		t.Fatalf("environment cells = %d, want 6", len(env))
+	}
+
+	brd.SetAmountIdx(idx(at(4)), 2)
+	if got := brd.At(at(4)).(Organics).Amount; got != 2 {
+		t.Fatalf("organics after SetAmountIdx = %d, want 2", got)
+	}
+	// The int32 columns saturate rather than wrap.
+	brd.Set(at(11), Depot{Owner: &owner, Colony: &colony, Food: math.MaxInt32 + 5, Ore: math.MinInt32 - 5})
+	if depot := brd.At(at(11)).(Depot); depot.Food != math.MaxInt32 || depot.Ore != math.MinInt32 {
+		t.Fatalf("oversized depot stored as food %d ore %d, want the int32 limits", depot.Food, depot.Ore)
+	}
+	brd.SetAmountIdx(idx(at(4)), math.MaxInt32+1)
+	if got := brd.At(at(4)).(Organics).Amount; got != math.MaxInt32 {
+		t.Fatalf("organics after an oversized SetAmountIdx = %d, want MaxInt32", got)
+	}
+	bot := NewBot(at(14))
+	brd.Set(at(14), &bot)
+	if brd.KindAt(at(14)) != CellBot || brd.At(at(14)) != &bot {
+		t.Fatalf("bot cell = %v %v", brd.KindAt(at(14)), brd.At(at(14)))
+	}
+	brd.SetAmountIdx(idx(at(14)), 5)
+	brd.Set(at(14), Food{Pos: at(14), Amount: 3})
+	if brd.GetBot(at(14)) != nil || brd.At(at(14)) != (Food{Pos: at(14), Amount: 3}) {
+		t.Fatalf("food over bot = %#v", brd.At(at(14)))
+	}
+
+	brd.Clear(at(6))
+	if brd.At(at(6)) != nil || brd.CellAtIdx(idx(at(6))) != (Cell{}) {
+		t.Fatalf("cleared farm left %+v", brd.CellAtIdx(idx(at(6))))
+	}
+	brd.SetCellIdx(idx(at(15)), Cell{Kind: CellWater, Amount: 5, Extra: 2})
+	brd.SetCellIdx(idx(at(16)), Cell{Kind: CellBot})
+	if brd.At(at(15)) != (Water{GroupId: 2, Amount: 5}) || brd.At(at(16)) != nil {
+		t.Fatalf("SetCellIdx wrote %#v and %#v", brd.At(at(15)), brd.At(at(16)))
+	}
+	count := 0
+	for i, o := range brd.Occupants() {
+		if o != nil {
+			count++
+			if o != brd.AtIdx(i) {
+				t.Fatalf("Occupants()[%d] = %#v, want %#v", i, o, brd.AtIdx(i))
+			}
+		}
+	}
+	if count != len(occupants)+1 {
+		t.Fatalf("occupied cells = %d, want %d", count, len(occupants)+1)
+	}
+}
+
+func BenchmarkBoardSetCellAndKindScan(b *testing.B) {
+	brd := NewBoard()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := range b.N {
+		for i := Cols; i < util.Cells-Cols; i += 7 {
+			brd.SetCellIdx(i, Cell{Kind: CellFood, Amount: n})
+		}
+		food := 0
+		for _, kind := range brd.Kinds() {
+			if kind == CellFood {
+				food++
+			}
+		}
+		if food == 0 {
+			b.Fatal("no food counted")
+		}
+	}
+}
+
+func TestBoardHashSurvivesSnapshotAndTracksEachComponent(t *testing.T) {
+	brd := NewBoard()
+	colony := NewColony(util.NewPos(10, 10))
+	brd.Set(colony.Center, Controller{Pos: colony.Center, Amount: 1})
+	brd.Set(util.NewPos(12, 12), Food{Pos: util.NewPos(12, 12), Amount: 3})
+	bot := NewBot(util.NewPos(11, 11))
+	colony.AddFamily(&bot)
+	brd.AddBot(bot.Pos, &bot)
+	brd.DepositPheromone(colony.Center, PheromoneHome, 9, &colony)
+	before := brd.HashParts()
+
+	restored := brd.Snapshot(NewCloner(0)).Restore(NewCloner(0))
+	if got := restored.HashParts(); got != before {
+		t.Fatalf("restored board hash = %+v, want %+v", got, before)
+	}
+	if restored.Hash() != brd.Hash() {
+		t.Fatal("restored board Hash differs from the original")
+	}
+
+	brd.Set(util.NewPos(12, 12), Food{Pos: util.NewPos(12, 12), Amount: 2})
+	afterFood := brd.HashParts()
+	if afterFood.Grid == before.Grid || afterFood.Bots != before.Bots || afterFood.Pheromones != before.Pheromones {
+		t.Fatalf("food change hash = %+v from %+v, want only Grid to change", afterFood, before)
+	}
+	bot.Hp--
+	afterBot := brd.HashParts()
+	if afterBot.Bots == afterFood.Bots || afterBot.Grid != afterFood.Grid {
+		t.Fatalf("bot change hash = %+v from %+v, want only Bots to change", afterBot, afterFood)
+	}
+	brd.DepositPheromone(util.NewPos(20, 20), PheromoneFood, 1, nil)
+	if got := brd.HashParts(); got.Pheromones == afterBot.Pheromones || got.Grid != afterBot.Grid || got.Bots != afterBot.Bots {
+		t.Fatalf("scent change hash = %+v from %+v, want only Pheromones to change", got, afterBot)
+	}
+}
+
+func TestBoardCheckInvariantsReportsBrokenMirrors(t *testing.T) {
+	build := func() (*Board, *Bot) {
+		brd := NewBoard()
+		colony := NewColony(util.NewPos(10, 10))
+		brd.Set(colony.Center, Controller{Pos: colony.Center, Amount: 1, Colony: &colony})
+		bot := NewBot(util.NewPos(11, 11))
+		brd.AddBot(bot.Pos, &bot)
+		brd.Set(util.NewPos(12, 12), Building{Pos: util.NewPos(12, 12), Owner: &bot, Hp: 3})
+		brd.DepositPheromone(colony.Center, PheromoneHome, 5, &colony)
+		return brd, &bot
+	}
+	if brd, _ := build(); brd.CheckInvariants() != nil {
+		t.Fatalf("consistent board reports %v", brd.CheckInvariants())
+	}
+
+	for _, tc := range []struct {
+		name    string
+		corrupt func(brd *Board, bot *Bot)
+	}{
+		{"bot cell unregistered", func(brd *Board, bot *Bot) { brd.botAtCell[idx(bot.Pos)] = NoBotID }},
+		{"bot position stale", func(brd *Board, bot *Bot) { bot.Pos = util.NewPos(30, 30) }},
+		{"environment cell dropped", func(brd *Board, _ *Bot) { brd.unmarkEnvironmentActive(idx(util.NewPos(10, 10))) }},
+		{"scent unlisted", func(brd *Board, _ *Bot) { brd.pheromones[idx(util.NewPos(40, 40))][PheromoneFood] = 1 }},
+		{"spatial index stale", func(brd *Board, _ *Bot) { brd.writeCellColumns(idx(util.NewPos(50, 50)), Cell{Kind: CellDepot}) }},
+		{"ref shared", func(brd *Board, _ *Bot) { brd.refIdx[idx(util.NewPos(60, 60))] = brd.refIdx[idx(util.NewPos(12, 12))] }},
+	} {
+		brd, bot := build()
+		tc.corrupt(brd, bot)
+		if problems := brd.CheckInvariants(); len(problems) == 0 {
+			t.Errorf("%s: CheckInvariants found nothing", tc.name)
+		}
+	}
+}
+
+func BenchmarkSpatialInRadius(b *testing.B) {
+	brd := NewBoard()
+	rng := rand.New(rand.NewSource(1))
+	bots := make([]Bot, 5000)
+	for n := range bots {
+		pos := util.NewPos(1+rng.Intn(Rows-2), rng.Intn(Cols))
+		if brd.IsEmpty(pos) {
+			bots[n] = NewBot(pos)
+			brd.AddBot(pos, &bots[n])
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	found := 0
+	for n := range b.N {
+		for range brd.InRadius(CellBot, util.PosOf((n*7919)%util.Cells), 12) {
+			found++
+		}
+	}
+	if found == 0 {
+		b.Fatal("no bots in radius")
+	}
+}
+
+func BenchmarkPheromoneDecayAndDiffusion(b *testing.B) {
+	brd := NewBoard()
+	rng := rand.New(rand.NewSource(1))
+	for range 20000 {
+		brd.DepositPheromone(util.PosOf(rng.Intn(util.Cells)), PheromoneChannel(rng.Intn(int(pheromoneChannelCount))), 200, nil)
+	}
+	brd.DiffusePheromones(1)
+	brd.DecayPheromones(1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		brd.DiffusePheromones(1)
+		brd.DecayPheromones(1)
+		brd.PullPatch()
+	}
+}
+
 func BenchmarkBoardDirtyPatch(b *testing.B) {
 	brd := NewBoard()
 	indices := make([]int, 1024)
@@ -426,3 +992,48 @@ func BenchmarkBoardDirtyPatch(b *testing.B) {
 		}
 	}
 }
+
+func TestHeatmapsAccumulateSamplesAndScaleAgainstHottestCell(t *testing.T) {
+	brd := NewBoard()
+	pos := util.NewPos(4, 4)
+	brd.AddHeat(HeatDeaths, idx(pos), 1)
+	if brd.HeatAt(HeatDeaths, pos) != 0 {
+		t.Fatal("heat collected before EnableHeat")
+	}
+
+	brd.EnableHeat()
+	hot := util.NewPos(5, 5)
+	brd.AddHeat(HeatDeaths, idx(pos), 1)
+	brd.AddHeat(HeatDeaths, idx(hot), 9)
+	if got := brd.HeatIntensity(HeatDeaths, hot); got != 1 {
+		t.Fatalf("hottest cell intensity = %v, want 1", got)
+	}
+	if got := brd.HeatIntensity(HeatDeaths, pos); got <= 0 || got >= 1 {
+		t.Fatalf("single death intensity = %v, want between 0 and 1", got)
+	}
+	if got := brd.HeatIntensity(HeatKills, pos); got != 0 {
+		t.Fatalf("empty layer intensity = %v, want 0", got)
+	}
+
+	bot := NewBot(pos)
+	brd.AddBot(pos, &bot)
+	brd.DepositPheromone(hot, PheromoneDanger, 120, nil)
+	brd.SampleHeat()
+	brd.SampleHeat()
+	if got := brd.HeatAt(HeatOccupancy, pos); got != 2 {
+		t.Fatalf("occupancy after two samples = %d, want 2", got)
+	}
+	if got := brd.HeatAt(HeatPheromone, hot); got != 240 {
+		t.Fatalf("pheromone exposure after two samples = %d, want 240", got)
+	}
+
+	for _, layer := range HeatLayers() {
+		parsed, err := ParseHeatLayer(layer.String())
+		if err != nil || parsed != layer {
+			t.Fatalf("ParseHeatLayer(%q) = %v, %v", layer.String(), parsed, err)
+		}
+	}
+	if _, err := ParseHeatLayer("weather"); err == nil {
+		t.Fatal("unknown heat layer parsed")
+	}
+}
@@ -3,7 +3,6 @@ package core
 import (
 	"golab/internal/assert"
 	"golab/internal/util"
-	"math/rand"
 	"sync"
 	"time"
 )
@@ -97,6 +96,8 @@ type Bot struct {
 	HasSpawner         bool
 	Pos                util.Position
 	CurrTask           *ColonyTask
+	LastHarm           DeathCause
+	LastHarmAge        int
 	// Path               []util.Position
 	CooldownUntil time.Time
 }
@@ -290,7 +291,7 @@ func (parent *Bot) NewChild(pos util.Position, shouldMutateColor bool) *Bot {
 
 func (parent *Bot) NewChildWithMutationRate(pos util.Position, shouldMutateColor bool, mutationRate int) *Bot {
 	// Keep the historical RNG stream stable while initializing fresh child bots.
-	_ = rand.Intn(1000)
+	_ = util.RandIntn(1000)
 	doMutation := util.RollChance(25)
 
 	b := &Bot{}
@@ -374,7 +375,7 @@ func mutatedColor(f [3]float32, doMutation bool) [3]float32 {
 	const mutationStrength = 0.05
 	var newColor [3]float32
 	for i := range 3 {
-		delta := (rand.Float32()*2 - 1) * mutationStrength
+		delta := (util.RandFloat32()*2 - 1) * mutationStrength
 		v := f[i] + delta
 		if v < 0 {
 			v = 0
@@ -407,5 +408,5 @@ var (
 var Dirs = []Direction{Up, Right, Down, Left}
 
 func RandomDir() Direction {
-	return Dirs[rand.Intn(4)]
+	return Dirs[util.RandIntn(4)]
 }
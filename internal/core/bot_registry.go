@@ -27,6 +27,19 @@ func (b *Board) BotCell(id BotID) int {
 	return b.botCell[int(id)]
 }
 
+// BotIDOf returns the registry ID of bot at its current cell, or NoBotID when
+// bot is not on the board.
+func (b *Board) BotIDOf(bot *Bot) BotID {
+	if bot == nil || !Inside(bot.Pos) {
+		return NoBotID
+	}
+	id := b.botAtCell[idx(bot.Pos)]
+	if !b.validBotID(id) || b.botSlots[int(id)] != bot {
+		return NoBotID
+	}
+	return id
+}
+
 func (b *Board) BotPosition(id BotID) (Position, bool) {
 	cell := b.BotCell(id)
 	if cell < 0 {
@@ -49,9 +62,8 @@ func (b *Board) RemoveBotAt(pos Position) *Bot {
 	}
 	cellIdx := idx(pos)
 	bot := b.unregisterBotAtIdx(cellIdx)
-	if _, ok := b.grid[cellIdx].(*Bot); ok {
-		b.grid[cellIdx] = nil
-		b.occupied[cellIdx] = false
+	if b.kinds[cellIdx] == CellBot {
+		b.clearCell(cellIdx)
 	}
 	b.MarkDirty(cellIdx)
 	return bot
@@ -156,27 +168,66 @@ func (b *Board) allocateBotID() BotID {
 
 func (b *Board) writeRegisteredBotCell(id BotID, cellIdx int, pos Position, bot *Bot) {
 	b.unmarkEnvironmentActive(cellIdx)
+	b.placeRegisteredBot(id, cellIdx, pos, bot)
+	b.spatial.update(cellIdx, CellBot)
+	b.MarkDirty(cellIdx)
+}
+
+func (b *Board) placeRegisteredBot(id BotID, cellIdx int, pos Position, bot *Bot) {
 	b.botAtCell[cellIdx] = id
 	b.Bots[cellIdx] = bot
-	b.grid[cellIdx] = bot
-	b.occupied[cellIdx] = true
+	b.writeCellColumns(cellIdx, Cell{Kind: CellBot})
 	bot.Pos = pos
-	b.MarkDirty(cellIdx)
 }
 
 func (b *Board) moveRegisteredBot(id BotID, oldIdx, newIdx int, newPos Position, bot *Bot) {
 	b.unregisterDestinationBotIfDifferent(newIdx, bot)
 	b.botAtCell[oldIdx] = NoBotID
 	b.Bots[oldIdx] = nil
-	if _, ok := b.grid[oldIdx].(*Bot); ok {
-		b.grid[oldIdx] = nil
-		b.occupied[oldIdx] = false
+	if b.kinds[oldIdx] == CellBot {
+		b.clearCell(oldIdx)
 	}
 	b.botCell[int(id)] = newIdx
 	b.writeRegisteredBotCell(id, newIdx, newPos, bot)
 	b.MarkDirty(oldIdx)
 }
 
+// MoveBotLocal moves a registered bot into an empty cell touching only the
+// two cells and the bot's own registry slot, so workers owning disjoint rows
+// can call it concurrently. The cells it dirties are appended to dirty for
+// the caller to pass to FlushLocalMoves afterwards; until then they are
+// neither marked dirty nor refiled in the spatial index. It reports false,
+// changing nothing, unless bot sits at oldPos and newPos is empty.
+func (b *Board) MoveBotLocal(oldPos, newPos Position, bot *Bot, dirty []int) ([]int, bool) {
+	if !Inside(oldPos) || !Inside(newPos) || bot == nil {
+		return dirty, false
+	}
+	oldIdx := idx(oldPos)
+	newIdx := idx(newPos)
+	id := b.botAtCell[oldIdx]
+	if oldIdx == newIdx || !b.validBotID(id) || b.botSlots[int(id)] != bot {
+		return dirty, false
+	}
+	if b.kinds[newIdx] != CellEmpty || b.botAtCell[newIdx] != NoBotID {
+		return dirty, false
+	}
+	b.botAtCell[oldIdx] = NoBotID
+	b.Bots[oldIdx] = nil
+	b.writeCellColumns(oldIdx, Cell{})
+	b.botCell[int(id)] = newIdx
+	b.placeRegisteredBot(id, newIdx, newPos, bot)
+	return append(dirty, newIdx, oldIdx), true
+}
+
+// FlushLocalMoves marks the cells MoveBotLocal reported and refiles them in
+// the spatial index. Cells listed more than once are fine.
+func (b *Board) FlushLocalMoves(dirty []int) {
+	for _, i := range dirty {
+		b.spatial.update(i, b.kinds[i])
+		b.MarkDirty(i)
+	}
+}
+
 func (b *Board) unregisterDestinationBotIfDifferent(cellIdx int, bot *Bot) {
 	if existingID := b.botAtCell[cellIdx]; b.validBotID(existingID) && b.botSlots[int(existingID)] != bot {
 		b.unregisterBotAtIdx(cellIdx)
@@ -216,25 +267,14 @@ func (b *Board) unregisterBotAtIdx(cellIdx int) *Bot {
 	return bot
 }
 
-func (b *Board) updateEnvironmentActive(cellIdx int, o Occupant) {
-	if environmentNeedsTick(o) {
+func (b *Board) updateEnvironmentActive(cellIdx int) {
+	if b.CellAtIdx(cellIdx).environmentNeedsTick() {
 		b.markEnvironmentActive(cellIdx)
 		return
 	}
 	b.unmarkEnvironmentActive(cellIdx)
 }
 
-func environmentNeedsTick(o Occupant) bool {
-	switch v := o.(type) {
-	case Organics, Farm, Poison, Controller, *Controller, Depot, *Depot:
-		return true
-	case Spawner:
-		return v.Colony != nil && v.AutoBirth
-	default:
-		return false
-	}
-}
-
 func (b *Board) markEnvironmentActive(cellIdx int) {
 	if cellIdx < 0 || cellIdx >= len(b.envActiveIndex) || b.envActiveIndex[cellIdx] >= 0 {
 		return
@@ -2,13 +2,12 @@ package core
 
 import (
 	"golab/internal/util"
-	"math/rand"
 	"testing"
 	"time"
 )
 
 func TestNewChildFullyInitializesPooledBotAndLinksLineage(t *testing.T) {
-	rand.Seed(1)
+	util.SeedRandom(1)
 
 	parent := NewBot(util.NewPos(12, 12))
 	colony := NewColony(parent.Pos)
@@ -110,3 +109,21 @@ func colonyContains(colony *Colony, child *Bot) bool {
 	}
 	return false
 }
+
+func TestHpDeathCauseBlamesOnlyRecentHarm(t *testing.T) {
+	bot := NewBot(util.NewPos(4, 4))
+	if got := bot.HpDeathCause(); got != DeathStarvation {
+		t.Fatalf("unharmed HP death = %s, want starvation", got)
+	}
+
+	bot.Age = 10
+	bot.Harm(DeathCrowding)
+	bot.Age = 11
+	if got := bot.HpDeathCause(); got != DeathCrowding {
+		t.Fatalf("HP death one tick after crowding = %s, want crowding", got)
+	}
+	bot.Age = 13
+	if got := bot.HpDeathCause(); got != DeathStarvation {
+		t.Fatalf("HP death long after crowding = %s, want starvation", got)
+	}
+}
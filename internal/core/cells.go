@@ -0,0 +1,303 @@
+package core
+
+import (
+	"fmt"
+	"iter"
+	"math"
+)
+
+// CellKind tags what occupies a board cell. Cells are stored as a kind byte
+// plus per-cell amount and extra columns, so reading or writing a cell never
+// boxes a struct. Owner and colony pointers live in a small reference table
+// that only owned structures use, which keeps the columns pointer-free.
+type CellKind uint8
+
+const (
+	CellEmpty CellKind = iota
+	CellBot
+	CellWall
+	CellResource
+	CellWater
+	CellOrganics
+	CellFood
+	CellFarm
+	CellSpawner
+	CellMine
+	CellPoison
+	CellBuilding
+	CellDepot
+	CellController
+	CellFlag
+)
+
+var cellKindNames = [...]string{
+	CellEmpty:      "empty",
+	CellBot:        "bot",
+	CellWall:       "wall",
+	CellResource:   "resource",
+	CellWater:      "water",
+	CellOrganics:   "organics",
+	CellFood:       "food",
+	CellFarm:       "farm",
+	CellSpawner:    "spawner",
+	CellMine:       "mine",
+	CellPoison:     "poison",
+	CellBuilding:   "building",
+	CellDepot:      "depot",
+	CellController: "controller",
+	CellFlag:       "flag",
+}
+
+func (k CellKind) String() string {
+	if int(k) < len(cellKindNames) {
+		return cellKindNames[k]
+	}
+	return fmt.Sprintf("CellKind(%d)", uint8(k))
+}
+
+// Cell is the typed content of one board cell. Amount and Extra are stored
+// as int32 and saturate at its range when written.
+type Cell struct {
+	Kind CellKind
+	// Amount is the cell's Amount field, a building's Hp or a depot's Food.
+	Amount int
+	// Extra is water's GroupId, a depot's Ore, a controller's WaterAmount,
+	// or 1 for a spawner with AutoBirth.
+	Extra  int
+	Owner  *Bot
+	Colony *Colony
+}
+
+// Kinds returns the kind column, one entry per cell. Callers must not write
+// to it.
+func (b *Board) Kinds() []CellKind {
+	return b.kinds
+}
+
+// KindAtIdx returns the kind of cell i without building an Occupant.
+func (b *Board) KindAtIdx(i int) CellKind {
+	if i < 0 || i >= len(b.kinds) {
+		return CellEmpty
+	}
+	return b.kinds[i]
+}
+
+func (b *Board) KindAt(pos Position) CellKind {
+	if !Inside(pos) {
+		return CellEmpty
+	}
+	return b.kinds[idx(pos)]
+}
+
+// CellAtIdx returns the typed content of cell i. Bots are not stored in the
+// columns; use GetBot or Bots for them.
+func (b *Board) CellAtIdx(i int) Cell {
+	if i < 0 || i >= len(b.kinds) {
+		return Cell{}
+	}
+	refs := b.refs[b.refIdx[i]]
+	return Cell{
+		Kind:   b.kinds[i],
+		Amount: int(b.amount[i]),
+		Extra:  int(b.extra[i]),
+		Owner:  refs.owner,
+		Colony: refs.colony,
+	}
+}
+
+// SetAmountIdx rewrites the amount column of a non-empty, non-bot cell in
+// place. It does not change whether the cell needs environment ticks.
+func (b *Board) SetAmountIdx(i int, amount int) {
+	if k := b.KindAtIdx(i); k == CellEmpty || k == CellBot {
+		return
+	}
+	b.amount[i] = cellColumn(amount)
+	b.MarkDirty(i)
+}
+
+// AtIdx is At for a cell index.
+func (b *Board) AtIdx(i int) Occupant {
+	if i < 0 || i >= len(b.kinds) {
+		return nil
+	}
+	return b.occupantAt(i)
+}
+
+// occupantAt builds the Occupant value the columns hold for cell i. Pos is
+// always the cell's own position.
+func (b *Board) occupantAt(i int) Occupant {
+	pos := Position{R: i / Cols, C: i % Cols}
+	amount := int(b.amount[i])
+	refs := b.refs[b.refIdx[i]]
+	switch b.kinds[i] {
+	case CellBot:
+		if bot := b.Bots[i]; bot != nil {
+			return bot
+		}
+	case CellWall:
+		return Wall{Pos: pos}
+	case CellResource:
+		return Resource{Pos: pos, Amount: amount}
+	case CellWater:
+		return Water{GroupId: int(b.extra[i]), Amount: amount}
+	case CellOrganics:
+		return Organics{Pos: pos, Amount: amount}
+	case CellFood:
+		return Food{Pos: pos, Amount: amount}
+	case CellFarm:
+		return Farm{Pos: pos, Owner: refs.owner, Colony: refs.colony, Amount: amount}
+	case CellSpawner:
+		return Spawner{Pos: pos, Owner: refs.owner, Colony: refs.colony, Amount: amount, AutoBirth: b.extra[i] != 0}
+	case CellMine:
+		return Mine{Pos: pos, Owner: refs.owner, Amount: amount}
+	case CellPoison:
+		return Poison{Pos: pos}
+	case CellBuilding:
+		return Building{Pos: pos, Owner: refs.owner, Hp: amount}
+	case CellDepot:
+		return Depot{Pos: pos, Owner: refs.owner, Colony: refs.colony, Food: amount, Ore: int(b.extra[i])}
+	case CellController:
+		return Controller{Pos: pos, Colony: refs.colony, Owner: refs.owner, Amount: amount, WaterAmount: int(b.extra[i])}
+	case CellFlag:
+		return ColonyFlag{Pos: pos}
+	}
+	return nil
+}
+
+// cellOf converts a non-bot occupant to its typed cell. Occupants are stored
+// by value; a pointer panics like any other unknown type.
+func cellOf(o Occupant) Cell {
+	var cell Cell
+	switch v := o.(type) {
+	case Wall:
+		cell = Cell{Kind: CellWall}
+	case Resource:
+		cell = Cell{Kind: CellResource, Amount: v.Amount}
+	case Water:
+		cell = Cell{Kind: CellWater, Amount: v.Amount, Extra: v.GroupId}
+	case Organics:
+		cell = Cell{Kind: CellOrganics, Amount: v.Amount}
+	case Food:
+		cell = Cell{Kind: CellFood, Amount: v.Amount}
+	case Farm:
+		cell = Cell{Kind: CellFarm, Amount: v.Amount, Owner: v.Owner, Colony: v.Colony}
+	case Spawner:
+		cell = Cell{Kind: CellSpawner, Amount: v.Amount, Owner: v.Owner, Colony: v.Colony}
+		if v.AutoBirth {
+			cell.Extra = 1
+		}
+	case Mine:
+		cell = Cell{Kind: CellMine, Amount: v.Amount, Owner: v.Owner}
+	case Poison:
+		cell = Cell{Kind: CellPoison}
+	case Building:
+		cell = Cell{Kind: CellBuilding, Amount: v.Hp, Owner: v.Owner}
+	case Depot:
+		cell = Cell{Kind: CellDepot, Amount: v.Food, Extra: v.Ore, Owner: v.Owner, Colony: v.Colony}
+	case Controller:
+		cell = Cell{Kind: CellController, Amount: v.Amount, Extra: v.WaterAmount, Owner: v.Owner, Colony: v.Colony}
+	case ColonyFlag:
+		cell = Cell{Kind: CellFlag}
+	default:
+		panic(fmt.Sprintf("core: cannot store %T on the board", o))
+	}
+	return cell
+}
+
+// SetCellIdx is Set for a typed cell. Unlike Set it takes no interface, so
+// callers do not box a struct per write. Bots go through AddBot instead.
+func (b *Board) SetCellIdx(i int, cell Cell) {
+	if i < 0 || i >= len(b.kinds) || cell.Kind == CellBot {
+		return
+	}
+	if cell.Kind == CellEmpty {
+		b.Clear(Position{R: i / Cols, C: i % Cols})
+		return
+	}
+	b.unregisterBotAtIdx(i)
+	b.writeCell(i, cell)
+	b.updateEnvironmentActive(i)
+	b.MarkDirty(i)
+}
+
+// cellRefs holds the pointers of one owned structure. refs[0] is always
+// empty, so cells without an entry read nil owner and colony.
+type cellRefs struct {
+	owner  *Bot
+	colony *Colony
+}
+
+func (b *Board) writeCell(i int, cell Cell) {
+	if passableKind(b.kinds[i]) != passableKind(cell.Kind) {
+		b.passableVersion++
+		b.chunkPassable[ChunkOf(i)]++
+	}
+	b.writeCellColumns(i, cell)
+	b.spatial.update(i, cell.Kind)
+}
+
+// writeCellColumns is writeCell without the spatial index update, for
+// MoveBotLocal. Moving a bot between empty cells leaves PassableVersion as
+// it is, so it is skipped here too.
+func (b *Board) writeCellColumns(i int, cell Cell) {
+	b.kinds[i] = cell.Kind
+	b.amount[i] = cellColumn(cell.Amount)
+	b.extra[i] = cellColumn(cell.Extra)
+	ref := b.refIdx[i]
+	if cell.Owner == nil && cell.Colony == nil {
+		if ref != 0 {
+			b.refs[ref] = cellRefs{}
+			b.freeRefs = append(b.freeRefs, ref)
+			b.refIdx[i] = 0
+		}
+		return
+	}
+	if ref == 0 {
+		if n := len(b.freeRefs); n > 0 {
+			ref = b.freeRefs[n-1]
+			b.freeRefs = b.freeRefs[:n-1]
+		} else {
+			ref = int32(len(b.refs))
+			b.refs = append(b.refs, cellRefs{})
+		}
+		b.refIdx[i] = ref
+	}
+	b.refs[ref] = cellRefs{owner: cell.Owner, colony: cell.Colony}
+}
+
+// cellColumn narrows v to an int32 column, saturating instead of wrapping.
+// No simulated amount comes near the limits, but a wrapped depot or
+// controller stock would silently turn negative.
+func cellColumn(v int) int32 {
+	return int32(max(math.MinInt32, min(v, math.MaxInt32)))
+}
+
+// clearCell empties the columns of cell i, dropping its pointers.
+func (b *Board) clearCell(i int) {
+	b.writeCell(i, Cell{})
+}
+
+// environmentNeedsTick reports whether environmentActions visits the cell.
+func (c Cell) environmentNeedsTick() bool {
+	switch c.Kind {
+	case CellOrganics, CellFarm, CellPoison, CellController, CellDepot:
+		return true
+	case CellSpawner:
+		return c.Colony != nil && c.Extra != 0
+	default:
+		return false
+	}
+}
+
+// Occupants ranges over every cell with its At value, nil for empty cells.
+// Each occupant is boxed; loops that only count or filter by kind should use
+// KindAtIdx and CellAtIdx.
+func (b *Board) Occupants() iter.Seq2[int, Occupant] {
+	return func(yield func(int, Occupant) bool) {
+		for i := range b.kinds {
+			if !yield(i, b.occupantAt(i)) {
+				return
+			}
+		}
+	}
+}
@@ -237,3 +237,92 @@ func TestFlagHealingRequiresControllerAmount(t *testing.T) {
 		t.Fatalf("controller amount after flag heal = %d, want 0", ctrl.Amount)
 	}
 }
+
+func TestTerritorySplitsBoardByControllerReachAndHomeScent(t *testing.T) {
+	brd := NewBoard()
+	a, b := NewColony(util.NewPos(100, 100)), NewColony(util.NewPos(100, 140))
+	brd.Set(a.Center, Controller{Pos: a.Center, Colony: &a})
+	brd.Set(b.Center, Controller{Pos: b.Center, Colony: &b})
+
+	territory := brd.Territory()
+	if len(territory.Colonies) != 2 || territory.Colonies[0] != &a || territory.Colonies[1] != &b {
+		t.Fatalf("colonies = %v, want a then b", territory.Colonies)
+	}
+	if got := territory.OwnerAt(util.NewPos(100, 120)); got != &a {
+		t.Fatalf("midpoint owner = %p, want first colony %p on a tie", got, &a)
+	}
+	if got := territory.OwnerAt(util.NewPos(100, 121)); got != &b {
+		t.Fatalf("owner right of midpoint = %p, want %p", got, &b)
+	}
+	if got := territory.OwnerAt(util.NewPos(100, 67)); got != nil {
+		t.Fatalf("owner beyond reach = %p, want unclaimed", got)
+	}
+	if border, contested := territory.Border(util.NewPos(100, 68)); !border || contested {
+		t.Fatalf("edge of reach border = %v contested = %v, want frontier", border, contested)
+	}
+	if border, contested := territory.Border(util.NewPos(100, 120)); !border || !contested {
+		t.Fatalf("midpoint border = %v contested = %v, want contested", border, contested)
+	}
+	if border, _ := territory.Border(util.NewPos(100, 100)); border {
+		t.Fatal("controller cell should be interior")
+	}
+	if territory.Area(&a) != territory.Areas[0] || territory.Areas[0] <= territory.Areas[1] {
+		t.Fatalf("areas = %v, want the first colony ahead on tied cells", territory.Areas)
+	}
+
+	for col := 121; col < 140; col++ {
+		brd.DepositPheromone(util.NewPos(100, col), PheromoneHome, 255, &b)
+	}
+	scented := brd.Territory()
+	if got := scented.OwnerAt(util.NewPos(100, 115)); got != &b {
+		t.Fatalf("owner past scent trail = %p, want scented colony %p", got, &b)
+	}
+	if got := scented.OwnerAt(util.NewPos(100, 110)); got != &a {
+		t.Fatalf("owner near first controller = %p, want %p", got, &a)
+	}
+	total := 0
+	for row := 0; row < Rows; row++ {
+		for col := 0; col < Cols; col++ {
+			if scented.OwnerAt(util.NewPos(row, col)) != nil {
+				total++
+			}
+		}
+	}
+	if sum := scented.Areas[0] + scented.Areas[1]; sum != total {
+		t.Fatalf("area sum = %d, want %d owned cells", sum, total)
+	}
+}
+
+func TestTerritoryStopsAtWallsAndStructures(t *testing.T) {
+	brd := NewBoard()
+	a, b := NewColony(util.NewPos(100, 100)), NewColony(util.NewPos(100, 140))
+	brd.Set(a.Center, Controller{Pos: a.Center, Colony: &a})
+	brd.Set(b.Center, Controller{Pos: b.Center, Colony: &b})
+	for row := 1; row < Rows-1; row++ {
+		pos := util.NewPos(row, 125)
+		brd.Set(pos, Wall{Pos: pos})
+	}
+	building := util.NewPos(100, 90)
+	brd.Set(building, Building{Pos: building})
+
+	territory := brd.Territory()
+	if got := territory.OwnerAt(util.NewPos(100, 124)); got != &a {
+		t.Fatalf("owner left of the wall = %p, want %p", got, &a)
+	}
+	if got := territory.OwnerAt(util.NewPos(100, 125)); got != nil {
+		t.Fatalf("wall owner = %p, want unclaimed", got)
+	}
+	if got := territory.OwnerAt(util.NewPos(100, 126)); got != &b {
+		t.Fatalf("owner right of the wall = %p, want %p", got, &b)
+	}
+	if got := territory.OwnerAt(building); got != &a {
+		t.Fatalf("building owner = %p, want the colony that reaches it %p", got, &a)
+	}
+	// Influence goes around the building, two steps longer than straight on.
+	if got := territory.OwnerAt(util.NewPos(100, 69)); got != nil {
+		t.Fatalf("owner 31 steps out behind the building = %p, want unclaimed", got)
+	}
+	if got := territory.OwnerAt(util.NewPos(100, 70)); got != &a {
+		t.Fatalf("owner 30 steps out behind the building = %p, want %p", got, &a)
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+type DeathCause uint8
+
+const (
+	DeathNone DeathCause = iota
+	DeathAge
+	DeathStarvation
+	DeathPoison
+	DeathCombat
+	DeathCurse
+	DeathCrowding
+	numDeathCauses
+)
+
+// harmAttributionTicks is how long a harm source keeps the blame for a bot's
+// HP death. Curses and crowding clamp HP at 1, so the killing blow lands on the
+// following tick's metabolism.
+const harmAttributionTicks = 1
+
+func DeathCauses() []DeathCause {
+	causes := make([]DeathCause, 0, numDeathCauses-1)
+	for c := DeathAge; c < numDeathCauses; c++ {
+		causes = append(causes, c)
+	}
+	return causes
+}
+
+func (c DeathCause) String() string {
+	switch c {
+	case DeathAge:
+		return "age"
+	case DeathStarvation:
+		return "starvation"
+	case DeathPoison:
+		return "poison"
+	case DeathCombat:
+		return "combat"
+	case DeathCurse:
+		return "curse"
+	case DeathCrowding:
+		return "crowding"
+	}
+	return "none"
+}
+
+// Harm records cause as the most recent external HP loss.
+func (b *Bot) Harm(cause DeathCause) {
+	b.LastHarm = cause
+	b.LastHarmAge = b.Age
+}
+
+// HpDeathCause attributes an HP death to a recent harm, falling back to
+// starvation when the bot simply ran out of HP.
+func (b *Bot) HpDeathCause() DeathCause {
+	if b.LastHarm != DeathNone && b.Age-b.LastHarmAge <= harmAttributionTicks {
+		return b.LastHarm
+	}
+	return DeathStarvation
+}
+
+// RecordDeath marks cellIdx with the cause of the latest death there.
+func (b *Board) RecordDeath(cellIdx int, cause DeathCause) {
+	if cellIdx < 0 || cellIdx >= len(b.kinds) {
+		return
+	}
+	if b.deaths == nil {
+		b.deaths = make([]DeathCause, len(b.kinds))
+	}
+	b.deaths[cellIdx] = cause
+}
+
+func (b *Board) DeathAt(pos Position) DeathCause {
+	if b.deaths == nil || !Inside(pos) {
+		return DeathNone
+	}
+	return b.deaths[idx(pos)]
+}
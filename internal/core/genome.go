@@ -2,7 +2,6 @@ package core
 
 import (
 	"golab/internal/util"
-	"math/rand"
 	"os"
 	"strconv"
 	"strings"
@@ -233,7 +232,7 @@ func NewMutatedGenomeWithRate(genome Genome, mutationRate int) Genome {
 		return genome
 	}
 	for range mutationRate {
-		mutationIdx := rand.Intn(genomeLen)
+		mutationIdx := util.RandIntn(genomeLen)
 		genome.Matrix[mutationIdx] = NewRandomGenomeValue()
 	}
 	return genome
@@ -249,7 +248,7 @@ func NewRandomGenome() Genome {
 }
 
 func NewRandomGenomeValue() int {
-	return rand.Intn(genomeMaxValue + 1)
+	return util.RandIntn(genomeMaxValue + 1)
 }
 
 func readGenome(data string) *Genome {
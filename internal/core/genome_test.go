@@ -2,12 +2,11 @@ package core
 
 import (
 	"golab/internal/util"
-	"math/rand"
 	"testing"
 )
 
 func TestNewRandomGenomeUsesDecodableRandomValuesWithoutBootstrap(t *testing.T) {
-	rand.Seed(1)
+	util.SeedRandom(1)
 
 	genome := NewRandomGenome()
 	for idx, value := range genome.Matrix {
@@ -29,7 +28,7 @@ func TestNewRandomGenomeUsesDecodableRandomValuesWithoutBootstrap(t *testing.T)
 }
 
 func TestNewMutatedGenomeUsesDecodableValues(t *testing.T) {
-	rand.Seed(2)
+	util.SeedRandom(2)
 
 	genome := NewRandomGenome()
 	mutated := NewMutatedGenome(genome, true)
@@ -0,0 +1,179 @@
+package core
+
+// stateHasher is FNV-1a taken a 64-bit word at a time with an extra shift
+// to spread the high bits. Unlike hash/maphash it has no per-process seed,
+// so equal states hash equal across runs, processes and builds.
+type stateHasher uint64
+
+func newStateHasher() stateHasher {
+	return 14695981039346656037
+}
+
+func (h *stateHasher) word(v uint64) {
+	x := (uint64(*h) ^ v) * 1099511628211
+	*h = stateHasher(x ^ x>>29)
+}
+
+func (h *stateHasher) int(v int) {
+	h.word(uint64(v))
+}
+
+func (h *stateHasher) bool(v bool) {
+	if v {
+		h.word(1)
+	} else {
+		h.word(0)
+	}
+}
+
+func (h *stateHasher) pos(p Position) {
+	h.int(p.R)
+	h.int(p.C)
+}
+
+// colony hashes a colony reference by its center, since pointers differ
+// between runs.
+func (h *stateHasher) colony(c *Colony) {
+	if c == nil {
+		h.int(-1)
+		return
+	}
+	h.pos(c.Center)
+}
+
+// bot hashes a bot reference by where it is and how old it is; owners can
+// be dead bots that are no longer on the board.
+func (h *stateHasher) bot(b *Bot) {
+	if b == nil {
+		h.int(-1)
+		return
+	}
+	h.pos(b.Pos)
+	h.int(b.Age)
+}
+
+// BoardHash fingerprints a board by component.
+type BoardHash struct {
+	// Grid covers every non-bot cell's columns, owner and colony, plus
+	// frozen cells.
+	Grid uint64
+	// Bots covers each bot's cell, genome, vitals, inventory and colony.
+	Bots uint64
+	// Pheromones covers every scented cell and its home owner.
+	Pheromones uint64
+}
+
+// Hash combines HashParts into one value.
+func (b *Board) Hash() uint64 {
+	parts := b.HashParts()
+	h := newStateHasher()
+	h.word(parts.Grid)
+	h.word(parts.Bots)
+	h.word(parts.Pheromones)
+	return uint64(h)
+}
+
+// HashParts hashes the board in cell order, so the result depends only on
+// what the board holds, not on registry or active-list order.
+func (b *Board) HashParts() BoardHash {
+	grid, bots, scent := newStateHasher(), newStateHasher(), newStateHasher()
+	for i, kind := range b.kinds {
+		if kind == CellBot {
+			if bot := b.Bots[i]; bot != nil {
+				bots.int(i)
+				hashBot(&bots, bot)
+			}
+		} else if kind != CellEmpty {
+			refs := b.refs[b.refIdx[i]]
+			grid.int(i)
+			grid.int(int(kind))
+			grid.int(int(b.amount[i]))
+			grid.int(int(b.extra[i]))
+			grid.bot(refs.owner)
+			grid.colony(refs.colony)
+		}
+		if b.frozen[i] {
+			grid.int(-i - 1)
+		}
+		if cell := b.pheromones[i]; cell != (PheromoneCell{}) || b.pheromoneHomeOwner[i] != nil {
+			scent.int(i)
+			for _, v := range cell {
+				scent.int(int(v))
+			}
+			scent.colony(b.pheromoneHomeOwner[i])
+		}
+	}
+	return BoardHash{Grid: uint64(grid), Bots: uint64(bots), Pheromones: uint64(scent)}
+}
+
+func hashBot(h *stateHasher, b *Bot) {
+	h.int(b.Dir[0])
+	h.int(b.Dir[1])
+	for _, gene := range b.Genome.Matrix {
+		h.int(gene)
+	}
+	h.word(uint64(b.Genome.Family))
+	h.int(b.Genome.Pointer)
+	h.int(b.Genome.NextArg)
+	h.int(b.Genome.Signal)
+	for _, reg := range b.Genome.Registers {
+		h.int(reg)
+	}
+	h.int(b.Inventory.Food)
+	h.int(b.Inventory.Ore)
+	h.colony(b.Colony)
+	h.bool(b.ConnnectedToColony)
+	h.int(b.OffspringCount)
+	h.int(b.Divisions)
+	h.int(b.LineageDepth)
+	h.int(b.Age)
+	h.int(b.Hp)
+	h.bool(b.HasSpawner)
+	h.int(int(b.LastHarm))
+	h.int(b.LastHarmAge)
+	if task := b.CurrTask; task != nil {
+		h.int(int(task.Type))
+		h.pos(task.Pos)
+	} else {
+		h.int(-1)
+	}
+}
+
+// HashColonies fingerprints the colonies in list order: their banks,
+// membership, flags and task queues.
+func HashColonies(colonies []*Colony) uint64 {
+	h := newStateHasher()
+	for _, c := range colonies {
+		if c == nil {
+			h.int(-1)
+			continue
+		}
+		h.pos(c.Center)
+		h.bool(c.HasWater)
+		h.int(c.FoodBank)
+		h.int(c.OreBank)
+		h.int(len(c.Members))
+		for _, m := range c.Members {
+			h.bot(m)
+		}
+		h.int(len(c.Flags))
+		for _, f := range c.Flags {
+			if f != nil {
+				h.pos(f.Pos)
+			}
+		}
+		h.int(len(c.Tasks))
+		for _, t := range c.Tasks {
+			if t != nil {
+				h.int(int(t.Type))
+				h.pos(t.Pos)
+				h.bool(t.IsDone)
+			}
+		}
+		h.bool(c.HasSpawnerGenome)
+		h.int(c.SpawnerGenomeScore)
+		h.int(c.AssignedTasksCount)
+		h.int(c.Counter)
+	}
+	return uint64(h)
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// HeatLayer is one cumulative per-cell counter collected over a run.
+type HeatLayer uint8
+
+const (
+	HeatBirths HeatLayer = iota
+	HeatDeaths
+	HeatKills
+	HeatRaids
+	// HeatPheromone adds the strongest scent channel of each cell every tick.
+	HeatPheromone
+	// HeatOccupancy counts ticks a bot spent on each cell.
+	HeatOccupancy
+	numHeatLayers
+)
+
+func HeatLayers() []HeatLayer {
+	layers := make([]HeatLayer, 0, numHeatLayers)
+	for layer := HeatBirths; layer < numHeatLayers; layer++ {
+		layers = append(layers, layer)
+	}
+	return layers
+}
+
+func (l HeatLayer) String() string {
+	switch l {
+	case HeatBirths:
+		return "births"
+	case HeatDeaths:
+		return "deaths"
+	case HeatKills:
+		return "kills"
+	case HeatRaids:
+		return "raids"
+	case HeatPheromone:
+		return "pheromone"
+	case HeatOccupancy:
+		return "occupancy"
+	}
+	return "unknown"
+}
+
+func ParseHeatLayer(name string) (HeatLayer, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, layer := range HeatLayers() {
+		if layer.String() == name {
+			return layer, nil
+		}
+	}
+	names := make([]string, 0, numHeatLayers)
+	for _, layer := range HeatLayers() {
+		names = append(names, layer.String())
+	}
+	return 0, fmt.Errorf("unknown heatmap layer %q: use %s", name, strings.Join(names, ", "))
+}
+
+// EnableHeat starts collecting heatmaps. Boards collect nothing until then,
+// so runs that never look at heatmaps pay nothing for them.
+func (b *Board) EnableHeat() {
+	if b.heat != nil {
+		return
+	}
+	b.heat = make([][]uint32, numHeatLayers)
+	for layer := range b.heat {
+		b.heat[layer] = make([]uint32, len(b.kinds))
+	}
+}
+
+// CopyHeatFrom carries other's heatmaps over to a rebuilt board, so they keep
+// accumulating across generations.
+func (b *Board) CopyHeatFrom(other *Board) {
+	if other == nil || other.heat == nil || len(other.kinds) != len(b.kinds) {
+		return
+	}
+	b.EnableHeat()
+	for layer := range b.heat {
+		copy(b.heat[layer], other.heat[layer])
+	}
+	b.heatMax = other.heatMax
+}
+
+func (b *Board) HeatEnabled() bool {
+	return b.heat != nil
+}
+
+func (b *Board) AddHeat(layer HeatLayer, cellIdx int, amount uint32) {
+	if b.heat == nil || layer >= numHeatLayers || cellIdx < 0 || cellIdx >= len(b.kinds) {
+		return
+	}
+	v := b.heat[layer][cellIdx] + amount
+	if v < amount {
+		v = math.MaxUint32
+	}
+	b.heat[layer][cellIdx] = v
+	b.heatMax[layer] = max(b.heatMax[layer], v)
+}
+
+// SampleHeat adds one tick of bot occupancy and pheromone exposure.
+func (b *Board) SampleHeat() {
+	if b.heat == nil {
+		return
+	}
+	for _, id := range b.activeBotIDs {
+		b.AddHeat(HeatOccupancy, b.BotCell(id), 1)
+	}
+	for _, i := range b.pheromoneActive {
+		if i < 0 || i >= len(b.pheromones) || !b.pheromoneActiveMask[i] {
+			continue
+		}
+		strongest := uint8(0)
+		for _, v := range b.pheromones[i] {
+			strongest = max(strongest, v)
+		}
+		if strongest > 0 {
+			b.AddHeat(HeatPheromone, i, uint32(strongest))
+		}
+	}
+}
+
+func (b *Board) HeatAt(layer HeatLayer, pos Position) uint32 {
+	if b.heat == nil || layer >= numHeatLayers || !Inside(pos) {
+		return 0
+	}
+	return b.heat[layer][idx(pos)]
+}
+
+func (b *Board) HeatMax(layer HeatLayer) uint32 {
+	if layer >= numHeatLayers {
+		return 0
+	}
+	return b.heatMax[layer]
+}
+
+// HeatIntensity scales a cell's heat to 0..1 against the hottest cell of the
+// layer on a log scale, so single events stay visible next to hot-spots.
+func (b *Board) HeatIntensity(layer HeatLayer, pos Position) float32 {
+	v, peak := b.HeatAt(layer, pos), b.HeatMax(layer)
+	if v == 0 || peak == 0 {
+		return 0
+	}
+	return float32(math.Log1p(float64(v)) / math.Log1p(float64(peak)))
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"golab/internal/util"
+)
+
+// maxInvariantProblems caps one report; a broken mirror tends to break every
+// cell it covers, and the first few entries say where.
+const maxInvariantProblems = 20
+
+// invariantReport collects problems up to maxInvariantProblems and counts
+// the rest.
+type invariantReport struct {
+	problems []string
+	dropped  int
+}
+
+func (r *invariantReport) addf(format string, args ...any) {
+	if len(r.problems) < maxInvariantProblems {
+		r.problems = append(r.problems, fmt.Sprintf(format, args...))
+		return
+	}
+	r.dropped++
+}
+
+func (r *invariantReport) list() []string {
+	if r.dropped > 0 {
+		return append(r.problems, fmt.Sprintf("... and %d more", r.dropped))
+	}
+	return r.problems
+}
+
+// CheckInvariants cross-checks the board's mirrored bookkeeping: the bot
+// registry against the cell columns, the environment, pheromone and spatial
+// indexes against the cells they cover, and the owner reference table. It
+// returns one line per broken invariant, or nil when the board is
+// consistent. It reads every cell, so it is meant for debugging runs.
+func (b *Board) CheckInvariants() []string {
+	var r invariantReport
+	b.checkBotRegistry(&r)
+	b.checkEnvironmentIndex(&r)
+	b.checkPheromoneIndex(&r)
+	b.checkSpatialIndex(&r)
+	b.checkCellRefs(&r)
+	return r.list()
+}
+
+func (b *Board) checkBotRegistry(r *invariantReport) {
+	seen := make(map[*Bot]BotID, len(b.activeBotIDs))
+	for k, id := range b.activeBotIDs {
+		if !b.validBotID(id) {
+			r.addf("activeBotIDs[%d] = %d has no bot in botSlots", k, id)
+			continue
+		}
+		bot, cell := b.botSlots[id], b.botCell[id]
+		if other, ok := seen[bot]; ok {
+			r.addf("bot %p holds ids %d and %d", bot, other, id)
+		}
+		seen[bot] = id
+		if b.botActiveIndex[id] != k {
+			r.addf("botActiveIndex[%d] = %d, want %d", id, b.botActiveIndex[id], k)
+		}
+		if cell < 0 || cell >= len(b.kinds) {
+			r.addf("botCell[%d] = %d is off the board", id, cell)
+			continue
+		}
+		if b.botAtCell[cell] != id || b.Bots[cell] != bot || b.kinds[cell] != CellBot {
+			r.addf("bot id %d at cell %v: botAtCell %d, Bots %p, kind %v", id, util.PosOf(cell), b.botAtCell[cell], b.Bots[cell], b.kinds[cell])
+		}
+		if bot.Pos != util.PosOf(cell) {
+			r.addf("bot id %d at cell %v has Pos %v", id, util.PosOf(cell), bot.Pos)
+		}
+	}
+	free := 0
+	for _, id := range b.freeBotIDs {
+		if int(id) < 0 || int(id) >= len(b.botSlots) || b.botSlots[id] != nil {
+			r.addf("free bot id %d is still in use", id)
+		}
+		free++
+	}
+	if len(b.activeBotIDs)+free != len(b.botSlots) {
+		r.addf("%d active and %d free bot ids, but %d slots", len(b.activeBotIDs), free, len(b.botSlots))
+	}
+	for i, kind := range b.kinds {
+		id := b.botAtCell[i]
+		switch {
+		case kind == CellBot && (!b.validBotID(id) || b.botCell[id] != i):
+			r.addf("bot cell %v is not registered (botAtCell %d)", util.PosOf(i), id)
+		case kind != CellBot && (id != NoBotID || b.Bots[i] != nil):
+			r.addf("%v cell %v still maps to bot id %d, Bots %p", kind, util.PosOf(i), id, b.Bots[i])
+		}
+	}
+}
+
+func (b *Board) checkEnvironmentIndex(r *invariantReport) {
+	for k, i := range b.activeEnvCells {
+		if i < 0 || i >= len(b.kinds) || b.envActiveIndex[i] != k {
+			r.addf("activeEnvCells[%d] = %d does not match envActiveIndex", k, i)
+		}
+	}
+	for i := range b.kinds {
+		active := b.envActiveIndex[i] >= 0
+		if needs := b.CellAtIdx(i).environmentNeedsTick(); needs != active {
+			r.addf("%v cell %v needs environment ticks %v, listed %v", b.kinds[i], util.PosOf(i), needs, active)
+		}
+	}
+}
+
+func (b *Board) checkPheromoneIndex(r *invariantReport) {
+	listed := 0
+	for k, i := range b.pheromoneActive {
+		if i < 0 || i >= len(b.pheromones) || !b.pheromoneActiveMask[i] {
+			r.addf("pheromoneActive[%d] = %d is not in pheromoneActiveMask", k, i)
+			continue
+		}
+		listed++
+	}
+	marked := 0
+	for i, on := range b.pheromoneActiveMask {
+		if on {
+			marked++
+		} else if b.pheromoneCellNonZero(i) || b.pheromoneHomeOwner[i] != nil {
+			r.addf("scented cell %v is not in pheromoneActive", util.PosOf(i))
+		}
+	}
+	if listed != marked {
+		r.addf("pheromoneActive lists %d cells, mask marks %d", listed, marked)
+	}
+}
+
+func (b *Board) checkSpatialIndex(r *invariantReport) {
+	s := &b.spatial
+	var counts [numSpatialKinds]int
+	for i, kind := range b.kinds {
+		k := spatialKindSlot[kind]
+		want := kind
+		if k == 0 {
+			want = CellEmpty
+		}
+		if s.kind[i] != want {
+			r.addf("spatial index files %v cell %v as %v", kind, util.PosOf(i), s.kind[i])
+			continue
+		}
+		if k == 0 {
+			continue
+		}
+		counts[k-1]++
+		bucket := s.buckets[k-1][ChunkOf(i)]
+		if slot := int(s.slot[i]); slot < 0 || slot >= len(bucket) || bucket[slot] != int32(i) {
+			r.addf("spatial bucket slot of %v cell %v is stale", kind, util.PosOf(i))
+		}
+	}
+	if counts != s.counts {
+		r.addf("spatial counts %v, cells hold %v", s.counts, counts)
+	}
+}
+
+func (b *Board) checkCellRefs(r *invariantReport) {
+	used := make([]bool, len(b.refs))
+	for _, ref := range b.freeRefs {
+		if ref <= 0 || int(ref) >= len(used) {
+			r.addf("free ref %d is out of range", ref)
+			continue
+		}
+		used[ref] = true
+	}
+	for i, ref := range b.refIdx {
+		if ref == 0 {
+			continue
+		}
+		if ref < 0 || int(ref) >= len(used) || used[ref] {
+			r.addf("cell %v ref %d is out of range, free or shared", util.PosOf(i), ref)
+			continue
+		}
+		used[ref] = true
+		if refs := b.refs[ref]; refs == (cellRefs{}) || b.kinds[i] == CellEmpty || b.kinds[i] == CellBot {
+			r.addf("%v cell %v holds ref %d with owner %p colony %p", b.kinds[i], util.PosOf(i), ref, refs.owner, refs.colony)
+		}
+	}
+	for ref := 1; ref < len(used); ref++ {
+		if !used[ref] {
+			r.addf("ref %d is neither used nor free", ref)
+		}
+	}
+}
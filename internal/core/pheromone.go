@@ -2,7 +2,9 @@ package core
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"sync"
 )
 
 type PheromoneChannel uint8
@@ -181,27 +183,35 @@ func (b *Board) ClearPheromones() {
 }
 
 func (b *Board) DecayPheromones(amount int) int {
+	return b.DecayPheromonesParallel(amount, 1)
+}
+
+// DecayPheromonesParallel is DecayPheromones with the active cells split into
+// up to workers bands. Each band only writes its own cells; dirty marks and
+// compaction run afterwards in active order, so the result matches the
+// serial pass exactly.
+func (b *Board) DecayPheromonesParallel(amount, workers int) int {
 	if amount <= 0 {
 		return 0
 	}
+	s := &b.pheromoneScratch
+	active := b.pheromoneActive
+	s.decayed = growScratch(s.decayed, len(active))
+	if bands := pheromoneBands(len(active), workers); bands <= 1 {
+		b.decayPheromoneRange(amount, 0, len(active))
+	} else {
+		runPheromoneBands(len(active), bands, func(lo, hi int) {
+			b.decayPheromoneRange(amount, lo, hi)
+		})
+	}
+
 	changed := 0
 	write := 0
-	active := b.pheromoneActive
-	for _, i := range active {
+	for k, i := range active {
 		if i < 0 || i >= len(b.pheromones) || !b.pheromoneActiveMask[i] {
 			continue
 		}
-		before := b.pheromones[i]
-		for channel := PheromoneChannel(0); channel < pheromoneChannelCount; channel++ {
-			b.pheromones[i][channel] = decayPheromoneValue(
-				b.pheromones[i][channel],
-				b.adjustedPheromoneDecay(i, channel, amount),
-			)
-		}
-		if b.pheromones[i][PheromoneHome] == 0 {
-			b.pheromoneHomeOwner[i] = nil
-		}
-		if before != b.pheromones[i] {
+		if s.decayed[k] {
 			changed++
 			b.MarkDirty(i)
 		}
@@ -217,61 +227,60 @@ func (b *Board) DecayPheromones(amount int) int {
 	return changed
 }
 
-func (b *Board) DiffusePheromones(amount int) int {
-	if amount <= 0 {
-		return 0
-	}
-	type deltaCell [pheromoneChannelCount]int
-
-	active := append([]int(nil), b.pheromoneActive...)
-	sort.Ints(active)
-	deltas := make(map[int]deltaCell, len(active)*2)
-	homeOwners := make(map[int]*Colony, len(active))
-	for _, i := range active {
+func (b *Board) decayPheromoneRange(amount, lo, hi int) {
+	for k := lo; k < hi; k++ {
+		i := b.pheromoneActive[k]
+		b.pheromoneScratch.decayed[k] = false
 		if i < 0 || i >= len(b.pheromones) || !b.pheromoneActiveMask[i] {
 			continue
 		}
-		neighbors, neighborCount := cardinalNeighborIndexes(i)
-		if neighborCount == 0 {
-			continue
-		}
-		source := b.pheromones[i]
+		before := b.pheromones[i]
 		for channel := PheromoneChannel(0); channel < pheromoneChannelCount; channel++ {
-			totalOut := amount * neighborCount
-			if int(source[channel]) <= totalOut {
-				continue
-			}
-			sourceDelta := deltas[i]
-			sourceDelta[channel] -= totalOut
-			deltas[i] = sourceDelta
-			for neighborIdx := 0; neighborIdx < neighborCount; neighborIdx++ {
-				n := neighbors[neighborIdx]
-				neighborDelta := deltas[n]
-				neighborDelta[channel] += amount
-				deltas[n] = neighborDelta
-				if channel == PheromoneHome && b.pheromoneHomeOwner[i] != nil {
-					homeOwners[n] = b.pheromoneHomeOwner[i]
-				}
-			}
+			b.pheromones[i][channel] = decayPheromoneValue(
+				b.pheromones[i][channel],
+				b.adjustedPheromoneDecay(i, channel, amount),
+			)
 		}
+		if b.pheromones[i][PheromoneHome] == 0 {
+			b.pheromoneHomeOwner[i] = nil
+		}
+		b.pheromoneScratch.decayed[k] = before != b.pheromones[i]
+	}
+}
+
+func (b *Board) DiffusePheromones(amount int) int {
+	return b.DiffusePheromonesParallel(amount, 1)
+}
+
+// DiffusePheromonesParallel is DiffusePheromones with the target cells split
+// into up to workers row bands. An active cell whose channel holds more than
+// amount per cardinal neighbour gives amount to each of them; a neighbour
+// that gains home scent takes the owner of its highest-index giver if it
+// had none. Every target is computed from the untouched grid into scratch
+// buffers, then written back in cell order, so no band sees another's
+// writes and the result does not depend on workers.
+func (b *Board) DiffusePheromonesParallel(amount, workers int) int {
+	if amount <= 0 {
+		return 0
+	}
+	s := &b.pheromoneScratch
+	b.collectDiffusionTargets(amount)
+	n := len(s.targets)
+	s.next = growScratch(s.next, n)
+	s.nextOwner = growScratch(s.nextOwner, n)
+	if bands := pheromoneBands(n, workers); bands <= 1 {
+		b.diffusePheromoneRange(amount, 0, n)
+	} else {
+		runPheromoneBands(n, bands, func(lo, hi int) {
+			b.diffusePheromoneRange(amount, lo, hi)
+		})
 	}
 
 	changed := 0
-	deltaCells := make([]int, 0, len(deltas))
-	for i := range deltas {
-		deltaCells = append(deltaCells, i)
-	}
-	sort.Ints(deltaCells)
-	for _, i := range deltaCells {
-		delta := deltas[i]
-		if i < 0 || i >= len(b.pheromones) {
-			continue
-		}
+	for k, i := range s.targets {
 		before := b.pheromones[i]
-		for channel := PheromoneChannel(0); channel < pheromoneChannelCount; channel++ {
-			b.pheromones[i][channel] = cappedPheromone(int(b.pheromones[i][channel]) + delta[channel])
-		}
-		if owner := homeOwners[i]; owner != nil && before[PheromoneHome] == 0 {
+		b.pheromones[i] = s.next[k]
+		if owner := s.nextOwner[k]; owner != nil && before[PheromoneHome] == 0 {
 			b.pheromoneHomeOwner[i] = owner
 		}
 		if b.pheromones[i][PheromoneHome] == 0 {
@@ -289,10 +298,94 @@ func (b *Board) DiffusePheromones(amount int) int {
 		}
 		b.MarkDirty(i)
 	}
+	clear(s.nextOwner[:n])
 	b.compactPheromoneActive()
 	return changed
 }
 
+// collectDiffusionTargets lists, in cell order, every active cell that gives
+// scent this tick and every neighbour of one.
+func (b *Board) collectDiffusionTargets(amount int) {
+	s := &b.pheromoneScratch
+	if s.stamp == nil {
+		s.stamp = make([]uint32, len(b.pheromones))
+	}
+	s.epoch++
+	if s.epoch == 0 {
+		clear(s.stamp)
+		s.epoch = 1
+	}
+	s.targets = s.targets[:0]
+	add := func(i int) {
+		if s.stamp[i] != s.epoch {
+			s.stamp[i] = s.epoch
+			s.targets = append(s.targets, i)
+		}
+	}
+	for _, i := range b.pheromoneActive {
+		if i < 0 || i >= len(b.pheromones) || !b.pheromoneActiveMask[i] || !b.pheromoneGives(i, amount) {
+			continue
+		}
+		add(i)
+		neighbors, neighborCount := cardinalNeighborIndexes(i)
+		for k := range neighborCount {
+			add(neighbors[k])
+		}
+	}
+	slices.Sort(s.targets)
+}
+
+// pheromoneGives reports whether active cell i gives scent on any channel.
+func (b *Board) pheromoneGives(i, amount int) bool {
+	totalOut := amount * cardinalNeighborCount(i)
+	for _, value := range b.pheromones[i] {
+		if int(value) > totalOut {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Board) diffusePheromoneRange(amount, lo, hi int) {
+	s := &b.pheromoneScratch
+	for k := lo; k < hi; k++ {
+		i := s.targets[k]
+		var delta [pheromoneChannelCount]int
+		if b.pheromoneActiveMask[i] {
+			totalOut := amount * cardinalNeighborCount(i)
+			for channel, value := range b.pheromones[i] {
+				if int(value) > totalOut {
+					delta[channel] -= totalOut
+				}
+			}
+		}
+		var owner *Colony
+		neighbors, neighborCount := cardinalNeighborIndexes(i)
+		giver := -1
+		for _, n := range neighbors[:neighborCount] {
+			if !b.pheromoneActiveMask[n] {
+				continue
+			}
+			totalOut := amount * cardinalNeighborCount(n)
+			for channel, value := range b.pheromones[n] {
+				if int(value) <= totalOut {
+					continue
+				}
+				delta[channel] += amount
+				if PheromoneChannel(channel) == PheromoneHome && b.pheromoneHomeOwner[n] != nil && n > giver {
+					owner, giver = b.pheromoneHomeOwner[n], n
+				}
+			}
+		}
+		next := b.pheromones[i]
+		for channel := range next {
+			next[channel] = cappedPheromone(int(next[channel]) + delta[channel])
+		}
+		s.next[k] = next
+		s.nextOwner[k] = owner
+	}
+}
+
 func (b *Board) PheromoneTotals() PheromoneTotals {
 	var totals PheromoneTotals
 	for _, i := range b.pheromoneActive {
@@ -309,6 +402,15 @@ func (b *Board) PheromoneTotals() PheromoneTotals {
 	return totals
 }
 
+// cardinalNeighborCount is the count cardinalNeighborIndexes returns: rows
+// wrap at the sides but not at the top and bottom.
+func cardinalNeighborCount(i int) int {
+	if r := i / Cols; r == 0 || r == Rows-1 {
+		return 3
+	}
+	return 4
+}
+
 func cardinalNeighborIndexes(i int) ([4]int, int) {
 	pos := Position{R: i / Cols, C: i % Cols}
 	var out [4]int
@@ -333,7 +435,7 @@ func (b *Board) adjustedPheromoneDecay(i int, channel PheromoneChannel, base int
 	case channel == PheromoneDanger && b.BiomeAtIdx(i) == BiomeToxic:
 		decay = max(1, decay-1)
 	}
-	if _, ok := b.grid[i].(Water); ok && isPositivePheromone(channel) {
+	if b.kinds[i] == CellWater && isPositivePheromone(channel) {
 		decay += 2
 	}
 	return decay
@@ -372,6 +474,45 @@ func (b *Board) compactPheromoneActive() {
 	sort.Ints(b.pheromoneActive)
 }
 
+// pheromoneScratch holds the buffers decay and diffusion reuse between ticks,
+// so neither allocates once they have grown to the active set.
+type pheromoneScratch struct {
+	stamp     []uint32
+	epoch     uint32
+	targets   []int
+	next      []PheromoneCell
+	nextOwner []*Colony
+	decayed   []bool
+}
+
+// pheromoneMinBand is the fewest cells worth handing to a worker.
+const pheromoneMinBand = 4096
+
+func pheromoneBands(cells, workers int) int {
+	return max(1, min(workers, cells/pheromoneMinBand))
+}
+
+// runPheromoneBands splits [0, n) into bands contiguous ranges and runs fn on
+// each in its own goroutine.
+func runPheromoneBands(n, bands int, fn func(lo, hi int)) {
+	var wg sync.WaitGroup
+	for band := range bands {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(band*n/bands, (band+1)*n/bands)
+		}()
+	}
+	wg.Wait()
+}
+
+func growScratch[T any](buf []T, n int) []T {
+	if cap(buf) < n {
+		return make([]T, n, n+n/4)
+	}
+	return buf[:n]
+}
+
 func (b *Board) pheromoneCellNonZero(i int) bool {
 	cell := b.pheromones[i]
 	for channel := PheromoneChannel(0); channel < pheromoneChannelCount; channel++ {
@@ -0,0 +1,277 @@
+package core
+
+import (
+	"slices"
+	"time"
+)
+
+// Cloner deep-copies bots, colonies and tasks, keeping references that are
+// shared in the source shared in the copy. Wall-clock deadlines (task
+// expiries and bot cooldowns) are moved by Shift, so a copy restored later
+// keeps the time it had left.
+type Cloner struct {
+	Shift time.Duration
+
+	bots     map[*Bot]*Bot
+	colonies map[*Colony]*Colony
+	tasks    map[*ColonyTask]*ColonyTask
+}
+
+func NewCloner(shift time.Duration) *Cloner {
+	return &Cloner{
+		Shift:    shift,
+		bots:     map[*Bot]*Bot{},
+		colonies: map[*Colony]*Colony{},
+		tasks:    map[*ColonyTask]*ColonyTask{},
+	}
+}
+
+func (c *Cloner) Bot(b *Bot) *Bot {
+	if b == nil {
+		return nil
+	}
+	if clone, ok := c.bots[b]; ok {
+		return clone
+	}
+	clone := new(Bot)
+	c.bots[b] = clone
+	*clone = *b
+	clone.Colony = c.Colony(b.Colony)
+	clone.Parent = c.Bot(b.Parent)
+	clone.CurrTask = c.Task(b.CurrTask)
+	clone.CooldownUntil = c.shiftTime(b.CooldownUntil)
+	if b.Offsprings != nil {
+		clone.Offsprings = make(map[*Bot]struct{}, len(b.Offsprings))
+		for offspring := range b.Offsprings {
+			clone.Offsprings[c.Bot(offspring)] = struct{}{}
+		}
+	}
+	return clone
+}
+
+// Colony copies col with its members, flags, markers and tasks. The water
+// flow field is shared: it is only ever replaced, never written in place.
+func (c *Cloner) Colony(col *Colony) *Colony {
+	if col == nil {
+		return nil
+	}
+	if clone, ok := c.colonies[col]; ok {
+		return clone
+	}
+	clone := new(Colony)
+	c.colonies[col] = clone
+	*clone = *col
+	clone.Members = make([]*Bot, len(col.Members))
+	for i, m := range col.Members {
+		clone.Members[i] = c.Bot(m)
+	}
+	clone.Flags = make([]*ColonyFlag, len(col.Flags))
+	for i, f := range col.Flags {
+		flag := *f
+		clone.Flags[i] = &flag
+	}
+	clone.Markers = make([]*ColonyMarker, len(col.Markers))
+	for i, m := range col.Markers {
+		marker := *m
+		clone.Markers[i] = &marker
+	}
+	clone.Tasks = make([]*ColonyTask, len(col.Tasks))
+	for i, t := range col.Tasks {
+		clone.Tasks[i] = c.Task(t)
+		if t.FlowField == &col.WaterPathFlowField {
+			clone.Tasks[i].FlowField = &clone.WaterPathFlowField
+		}
+	}
+	clone.PathToWater = slices.Clone(col.PathToWater)
+	clone.pathToWaterMask = slices.Clone(col.pathToWaterMask)
+	clone.pathToWaterIndex = slices.Clone(col.pathToWaterIndex)
+	clone.WaterPositions = slices.Clone(col.WaterPositions)
+	clone.WaterGroupIds = slices.Clone(col.WaterGroupIds)
+	return clone
+}
+
+func (c *Cloner) Task(t *ColonyTask) *ColonyTask {
+	if t == nil {
+		return nil
+	}
+	if clone, ok := c.tasks[t]; ok {
+		return clone
+	}
+	clone := new(ColonyTask)
+	c.tasks[t] = clone
+	*clone = *t
+	clone.Owner = c.Bot(t.Owner)
+	clone.ExpiresAt = c.shiftTime(t.ExpiresAt)
+	if t.FlowField != nil {
+		field := *t.FlowField
+		clone.FlowField = &field
+	}
+	return clone
+}
+
+func (c *Cloner) shiftTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Add(c.Shift)
+}
+
+// cell copies the bot and colony references inside cell.
+func (c *Cloner) cell(cell Cell) Cell {
+	cell.Owner, cell.Colony = c.Bot(cell.Owner), c.Colony(cell.Colony)
+	return cell
+}
+
+// BoardSnapshot is a compact copy of a board's simulation state. Only
+// occupied cells, scented cells and the bot registry are stored; lookup
+// tables are rebuilt on restore. Heatmaps and the dirty set are not kept.
+type BoardSnapshot struct {
+	cells        []snapshotCell
+	frozen       []int32
+	deaths       []snapshotDeath
+	scent        []snapshotScent
+	bots         []snapshotBot
+	slots        int
+	activeBotIDs []BotID
+	freeBotIDs   []BotID
+	envCells     []int32
+	paths        []Position
+}
+
+type snapshotCell struct {
+	idx  int32
+	cell Cell
+}
+
+type snapshotDeath struct {
+	idx   int32
+	cause DeathCause
+}
+
+type snapshotScent struct {
+	idx   int32
+	cell  PheromoneCell
+	owner *Colony
+}
+
+type snapshotBot struct {
+	id   BotID
+	cell int32
+	bot  *Bot
+}
+
+// Snapshot copies the board through c. Bots and colonies the board points at
+// are cloned along with it.
+func (b *Board) Snapshot(c *Cloner) *BoardSnapshot {
+	s := &BoardSnapshot{
+		slots:        len(b.botSlots),
+		activeBotIDs: slices.Clone(b.activeBotIDs),
+		freeBotIDs:   slices.Clone(b.freeBotIDs),
+		envCells:     make([]int32, len(b.activeEnvCells)),
+		paths:        slices.Clone(b.PathsToRenderR),
+	}
+	for i, kind := range b.kinds {
+		if kind != CellEmpty && kind != CellBot {
+			s.cells = append(s.cells, snapshotCell{idx: int32(i), cell: c.cell(b.CellAtIdx(i))})
+		}
+	}
+	for i, frozen := range b.frozen {
+		if frozen {
+			s.frozen = append(s.frozen, int32(i))
+		}
+	}
+	for i, cause := range b.deaths {
+		if cause != DeathNone {
+			s.deaths = append(s.deaths, snapshotDeath{idx: int32(i), cause: cause})
+		}
+	}
+	for _, i := range b.pheromoneActive {
+		s.scent = append(s.scent, snapshotScent{idx: int32(i), cell: b.pheromones[i], owner: c.Colony(b.pheromoneHomeOwner[i])})
+	}
+	for id, bot := range b.botSlots {
+		if bot != nil {
+			s.bots = append(s.bots, snapshotBot{id: BotID(id), cell: int32(b.botCell[id]), bot: c.Bot(bot)})
+		}
+	}
+	for i, cell := range b.activeEnvCells {
+		s.envCells[i] = int32(cell)
+	}
+	return s
+}
+
+// Restore builds a new board from s through c, so s can be restored again.
+// Bot IDs and the iteration order of bots, environment cells and scent are
+// the same as on the snapshotted board.
+func (s *BoardSnapshot) Restore(c *Cloner) *Board {
+	b := NewBoard()
+	for _, cell := range s.cells {
+		b.writeCell(int(cell.idx), c.cell(cell.cell))
+	}
+	for _, i := range s.frozen {
+		b.frozen[i] = true
+	}
+	if len(s.deaths) > 0 {
+		b.deaths = make([]DeathCause, len(b.kinds))
+		for _, death := range s.deaths {
+			b.deaths[death.idx] = death.cause
+		}
+	}
+	for _, scent := range s.scent {
+		b.pheromones[scent.idx] = scent.cell
+		b.pheromoneHomeOwner[scent.idx] = c.Colony(scent.owner)
+		b.pheromoneActiveMask[scent.idx] = true
+		b.pheromoneActive = append(b.pheromoneActive, int(scent.idx))
+	}
+
+	b.botSlots = make([]*Bot, s.slots)
+	b.botCell = make([]int, s.slots)
+	b.botActiveIndex = make([]int, s.slots)
+	for id := range s.slots {
+		b.botCell[id], b.botActiveIndex[id] = -1, -1
+	}
+	for _, entry := range s.bots {
+		bot := c.Bot(entry.bot)
+		b.botSlots[entry.id] = bot
+		b.botCell[entry.id] = int(entry.cell)
+		b.botAtCell[entry.cell] = entry.id
+		b.Bots[entry.cell] = bot
+		b.writeCell(int(entry.cell), Cell{Kind: CellBot})
+	}
+	b.activeBotIDs = slices.Clone(s.activeBotIDs)
+	for i, id := range b.activeBotIDs {
+		b.botActiveIndex[id] = i
+	}
+	b.freeBotIDs = slices.Clone(s.freeBotIDs)
+	for i, cell := range s.envCells {
+		b.envActiveIndex[cell] = i
+		b.activeEnvCells = append(b.activeEnvCells, int(cell))
+	}
+	b.activeEnvOrderDirty = true
+
+	b.AddPathsToRender(s.paths...)
+	return b
+}
+
+// Colonies copies a colony list through c.
+func (c *Cloner) Colonies(colonies []*Colony) []*Colony {
+	if colonies == nil {
+		return nil
+	}
+	clone := make([]*Colony, len(colonies))
+	for i, col := range colonies {
+		clone[i] = c.Colony(col)
+	}
+	return clone
+}
+
+// ColonyKeys copies a map keyed by colony, remapping the keys through c.
+func ColonyKeys[V any](c *Cloner, m map[*Colony]V) map[*Colony]V {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[*Colony]V, len(m))
+	for col, v := range m {
+		clone[c.Colony(col)] = v
+	}
+	return clone
+}
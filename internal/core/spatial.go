@@ -0,0 +1,235 @@
+package core
+
+import (
+	"iter"
+	"slices"
+)
+
+// ChunkSize is the side, in cells, of the chunks the board is split into
+// for the spatial index and passability versions.
+const ChunkSize = 20
+
+const (
+	ChunkRows       = (Rows + ChunkSize - 1) / ChunkSize
+	ChunkCols       = (Cols + ChunkSize - 1) / ChunkSize
+	Chunks          = ChunkRows * ChunkCols
+	numSpatialKinds = 5
+)
+
+// spatialKindSlot maps the kinds the spatial index tracks to their bucket
+// set plus one; every other kind maps to 0.
+var spatialKindSlot = [CellFlag + 1]int8{
+	CellBot:        1,
+	CellController: 2,
+	CellDepot:      3,
+	CellSpawner:    4,
+	CellFarm:       5,
+}
+
+// spatialIndex files the cells holding bots and colony structures into
+// chunked buckets per kind, so radius queries read a few buckets instead of
+// every cell in the square. writeCell keeps it current; bucket order is
+// arbitrary and queries sort what they collect.
+type spatialIndex struct {
+	buckets [numSpatialKinds][Chunks][]int32
+	counts  [numSpatialKinds]int
+	// kind is the kind each cell is filed under, CellEmpty when untracked,
+	// and slot its position in that bucket.
+	kind []CellKind
+	slot []int32
+	// scratch is a stack of query results, so a query may run inside
+	// another's loop.
+	scratch []int
+}
+
+func newSpatialIndex() spatialIndex {
+	return spatialIndex{
+		kind: make([]CellKind, Rows*Cols),
+		slot: make([]int32, Rows*Cols),
+	}
+}
+
+// ChunkOf returns the chunk holding cell i; chunks are numbered row-major.
+func ChunkOf(i int) int {
+	return (i/Cols/ChunkSize)*ChunkCols + i%Cols/ChunkSize
+}
+
+// update files cell i under kind, moving it out of the bucket it was in.
+func (s *spatialIndex) update(i int, kind CellKind) {
+	if spatialKindSlot[kind] == 0 {
+		kind = CellEmpty
+	}
+	old := s.kind[i]
+	if old == kind {
+		return
+	}
+	chunk := ChunkOf(i)
+	if old != CellEmpty {
+		k := spatialKindSlot[old] - 1
+		bucket := s.buckets[k][chunk]
+		last := len(bucket) - 1
+		moved := bucket[last]
+		bucket[s.slot[i]] = moved
+		s.slot[moved] = s.slot[i]
+		s.buckets[k][chunk] = bucket[:last]
+		s.counts[k]--
+	}
+	s.kind[i] = kind
+	if kind != CellEmpty {
+		k := spatialKindSlot[kind] - 1
+		s.slot[i] = int32(len(s.buckets[k][chunk]))
+		s.buckets[k][chunk] = append(s.buckets[k][chunk], int32(i))
+		s.counts[k]++
+	}
+}
+
+// CountOfKind returns how many cells hold kind. It is only tracked for bots,
+// controllers, depots, spawners and farms; other kinds count every cell.
+func (b *Board) CountOfKind(kind CellKind) int {
+	if k := spatialKindSlot[kind]; k != 0 {
+		return b.spatial.counts[k-1]
+	}
+	n := 0
+	for _, other := range b.kinds {
+		if other == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// CellsOfKind ranges over the cells holding kind in ascending index order.
+func (b *Board) CellsOfKind(kind CellKind) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		k := spatialKindSlot[kind]
+		if k == 0 {
+			for i, other := range b.kinds {
+				if other == kind && !yield(i) {
+					return
+				}
+			}
+			return
+		}
+		s := &b.spatial
+		start := len(s.scratch)
+		for _, bucket := range s.buckets[k-1] {
+			for _, i := range bucket {
+				s.scratch = append(s.scratch, int(i))
+			}
+		}
+		end := len(s.scratch)
+		slices.Sort(s.scratch[start:end])
+		for n := start; n < end; n++ {
+			if i := s.scratch[n]; b.kinds[i] == kind && !yield(i) {
+				break
+			}
+		}
+		s.scratch = s.scratch[:start]
+	}
+}
+
+// InRadius ranges over the cells holding kind in the square of the given
+// radius around center, in the order a scan of rows top to bottom and of
+// columns from center.C-radius to center.C+radius visits them. A cell that
+// stops holding kind before its turn is skipped. Squares holding fewer cells
+// than the buckets they overlap are scanned directly.
+func (b *Board) InRadius(kind CellKind, center Position, radius int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if radius < 0 {
+			return
+		}
+		k := int(spatialKindSlot[kind]) - 1
+		width := 2*radius + 1
+		r0, r1, c0 := max(center.R-radius, 0), min(center.R+radius, Rows-1), center.C-radius
+		if k < 0 || width >= Cols || r0 > r1 {
+			b.scanRadius(kind, center, radius, yield)
+			return
+		}
+		s := &b.spatial
+		load := 0
+		forWindowChunks(r0, r1, c0, width, func(chunk int) {
+			load += len(s.buckets[k][chunk])
+		})
+		if load > (r1-r0+1)*width {
+			b.scanRadius(kind, center, radius, yield)
+			return
+		}
+		start := len(s.scratch)
+		forWindowChunks(r0, r1, c0, width, func(chunk int) {
+			for _, cell := range s.buckets[k][chunk] {
+				i := int(cell)
+				r, off := i/Cols, wrapCol(i%Cols-c0)
+				if r >= r0 && r <= r1 && off < width {
+					s.scratch = append(s.scratch, (r-r0)*width+off)
+				}
+			}
+		})
+		end := len(s.scratch)
+		slices.Sort(s.scratch[start:end])
+		for n := start; n < end; n++ {
+			key := s.scratch[n]
+			i := (r0+key/width)*Cols + wrapCol(c0+key%width)
+			if b.kinds[i] == kind && !yield(i) {
+				break
+			}
+		}
+		s.scratch = s.scratch[:start]
+	}
+}
+
+// forWindowChunks calls fn once for every chunk overlapping rows r0..r1 and
+// the width columns from c0, wrapping. width must be less than Cols.
+func forWindowChunks(r0, r1, c0, width int, fn func(chunk int)) {
+	first := wrapCol(c0)
+	for cr := r0 / ChunkSize; cr <= r1/ChunkSize; cr++ {
+		c := first
+		for covered, n := 0, 0; covered < width && n < ChunkCols; n++ {
+			cc := c / ChunkSize
+			fn(cr*ChunkCols + cc)
+			next := min((cc+1)*ChunkSize, Cols)
+			covered += next - c
+			c = next % Cols
+		}
+	}
+}
+
+// scanRadius is InRadius without the index, for untracked kinds and squares
+// as wide as the board.
+func (b *Board) scanRadius(kind CellKind, center Position, radius int, yield func(int) bool) {
+	first := wrapCol(center.C - radius)
+	for r := max(center.R-radius, 0); r <= min(center.R+radius, Rows-1); r++ {
+		row := b.kinds[r*Cols : (r+1)*Cols]
+		c := first
+		for range 2*radius + 1 {
+			if row[c] == kind && !yield(r*Cols+c) {
+				return
+			}
+			if c++; c == Cols {
+				c = 0
+			}
+		}
+	}
+}
+
+// NearestOfKind returns the cell holding kind closest to center, by
+// Chebyshev distance with wrapped columns and then by index, among those
+// within radius that accept allows. A nil accept allows every cell. It
+// searches outward in doubling squares, so a near hit stays cheap.
+func (b *Board) NearestOfKind(kind CellKind, center Position, radius int, accept func(i int) bool) (int, bool) {
+	best, bestDist := -1, 0
+	for r := min(ChunkSize, radius); r >= 0; r = min(2*r, radius) {
+		for i := range b.InRadius(kind, center, r) {
+			if accept != nil && !accept(i) {
+				continue
+			}
+			dist := max(absInt(i/Cols-center.R), toroidalColDistance(i%Cols, wrapCol(center.C)))
+			if best < 0 || dist < bestDist || (dist == bestDist && i < best) {
+				best, bestDist = i, dist
+			}
+		}
+		if best >= 0 || r >= radius {
+			break
+		}
+	}
+	return best, best >= 0
+}
@@ -0,0 +1,139 @@
+package core
+
+// Territory reach is measured in steps of territoryStep; a colony's own home
+// scent makes a step as cheap as territoryStep-territoryScentDiscount, so
+// well-trodden ground pulls the border outward.
+const (
+	territoryStep          = 4
+	territoryScentDiscount = 3
+	territoryReach         = 32 * territoryStep
+)
+
+// Territory is a Voronoi-like influence map: every cell belongs to the
+// colony whose controller reaches it most cheaply, or to nobody when no
+// controller reaches it within range.
+type Territory struct {
+	// Colonies lists each controlled colony once, in board scan order.
+	Colonies []*Colony
+	// Areas holds the number of owned cells for each entry of Colonies.
+	Areas []int
+
+	owners []int16
+}
+
+// Territory computes the current influence map. Controllers grow their
+// colony outward through cells bots can walk; other structures are claimed
+// where reached but pass no influence on, and walls and water are never
+// claimed. Ties go to the colony seen first.
+func (b *Board) Territory() Territory {
+	t := Territory{owners: make([]int16, len(b.kinds))}
+	for i := range t.owners {
+		t.owners[i] = -1
+	}
+	cost := make([]int, len(b.kinds))
+	buckets := make([][]int, territoryReach+1)
+	index := map[*Colony]int16{}
+	for i, kind := range b.kinds {
+		if kind != CellController {
+			continue
+		}
+		colony := b.refs[b.refIdx[i]].colony
+		if colony == nil {
+			continue
+		}
+		owner, ok := index[colony]
+		if !ok {
+			owner = int16(len(t.Colonies))
+			index[colony] = owner
+			t.Colonies = append(t.Colonies, colony)
+			t.Areas = append(t.Areas, 0)
+		}
+		if t.owners[i] < 0 {
+			t.owners[i] = owner
+			buckets[0] = append(buckets[0], i)
+		}
+	}
+
+	for reach := range buckets {
+		for n := 0; n < len(buckets[reach]); n++ {
+			i := buckets[reach][n]
+			if cost[i] != reach {
+				continue
+			}
+			if reach > 0 && !passableKind(b.kinds[i]) {
+				continue
+			}
+			owner := t.owners[i]
+			colony := t.Colonies[owner]
+			pos := Position{R: i / Cols, C: i % Cols}
+			for _, dir := range Dirs {
+				next := pos.AddDir(dir)
+				if b.IsWall(next) {
+					continue
+				}
+				j := idx(next)
+				if kind := b.kinds[j]; kind == CellWall || kind == CellWater {
+					continue
+				}
+				step := territoryStep
+				if b.pheromoneHomeOwner[j] == colony {
+					step -= territoryScentDiscount * int(b.pheromones[j][PheromoneHome]) / 255
+				}
+				nextCost := reach + step
+				if nextCost > territoryReach || (t.owners[j] >= 0 && cost[j] <= nextCost) {
+					continue
+				}
+				t.owners[j] = owner
+				cost[j] = nextCost
+				buckets[nextCost] = append(buckets[nextCost], j)
+			}
+		}
+		buckets[reach] = nil
+	}
+	for _, owner := range t.owners {
+		if owner >= 0 {
+			t.Areas[owner]++
+		}
+	}
+	return t
+}
+
+// OwnerAt returns the colony owning pos, or nil for unclaimed cells.
+func (t Territory) OwnerAt(pos Position) *Colony {
+	if !Inside(pos) || t.owners == nil || t.owners[idx(pos)] < 0 {
+		return nil
+	}
+	return t.Colonies[t.owners[idx(pos)]]
+}
+
+// Area returns the number of cells colony owns.
+func (t Territory) Area(colony *Colony) int {
+	for i, c := range t.Colonies {
+		if c == colony {
+			return t.Areas[i]
+		}
+	}
+	return 0
+}
+
+// Border reports whether pos is an owned cell on the edge of its territory,
+// and whether that edge touches another colony rather than unclaimed ground.
+func (t Territory) Border(pos Position) (border, contested bool) {
+	owner := t.OwnerAt(pos)
+	if owner == nil {
+		return false, false
+	}
+	for _, dir := range Dirs {
+		next := pos.AddDir(dir)
+		if next.R <= 0 || next.R >= Rows-1 {
+			continue
+		}
+		switch other := t.OwnerAt(next); {
+		case other == nil:
+			border = true
+		case other != owner:
+			return true, true
+		}
+	}
+	return border, false
+}
@@ -140,7 +140,7 @@ func (g *Game) tryColonySpawnerAutoBirth(pos core.Position, spawner *core.Spawne
 		g.Board.AddBot(childPos, &child)
 	}
 
-	g.successfulDivisions++
+	g.recordBirth(parent, g.Board.GetBot(childPos))
 	g.totalSpawnerBirths++
 	g.emitConnectedColonyHome(childPos, g.Board.GetBot(childPos))
 	g.emitEventPheromone(pos, core.PheromoneFood)
@@ -152,17 +152,12 @@ func (g *Game) connectedColonyBotsNear(colony *core.Colony, center core.Position
 		return 0
 	}
 	count := 0
-	for r := center.R - radius; r <= center.R+radius; r++ {
-		if r < 0 || r >= core.Rows {
-			continue
-		}
-		for dc := -radius; dc <= radius; dc++ {
-			bot := g.Board.GetBot(util.NewPos(r, center.C+dc))
-			if bot != nil && bot.Colony == colony && bot.ConnnectedToColony {
-				count++
-			}
+	g.forEachLiveBotInRadius(center, radius, func(bot *core.Bot) bool {
+		if bot.Colony == colony && bot.ConnnectedToColony {
+			count++
 		}
-	}
+		return true
+	})
 	return count
 }
 
@@ -183,16 +178,9 @@ func (g *Game) activeColonies() map[*core.Colony]struct{} {
 	if g == nil || g.Board == nil {
 		return active
 	}
-	for _, cell := range *g.Board.GetGrid() {
-		switch v := cell.(type) {
-		case core.Controller:
-			if v.Colony != nil && g.controllerOwnerAlive(&v) {
-				active[v.Colony] = struct{}{}
-			}
-		case *core.Controller:
-			if v != nil && v.Colony != nil && g.controllerOwnerAlive(v) {
-				active[v.Colony] = struct{}{}
-			}
+	for i := range g.Board.CellsOfKind(core.CellController) {
+		if ctrl := g.Board.AtIdx(i).(core.Controller); ctrl.Colony != nil && g.controllerOwnerAlive(&ctrl) {
+			active[ctrl.Colony] = struct{}{}
 		}
 	}
 	for _, id := range g.Board.ActiveBotIDs() {
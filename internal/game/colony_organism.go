@@ -117,7 +117,7 @@ func (g *Game) tryColonyOrganismDivision(parentPos core.Position, bot *core.Bot)
 	g.inheritColonyConnection(bot, child)
 	bot.Hp -= g.config.DivisionCost
 	g.Board.AddBot(childPos, child)
-	g.successfulDivisions++
+	g.recordBirth(bot, child)
 	g.emitConnectedColonyHome(childPos, child)
 	g.Board.MarkDirty(util.Idx(parentPos))
 	return true
@@ -557,12 +557,8 @@ func (g *Game) isColonyAnchorAt(colony *core.Colony, pos core.Position) bool {
 	switch cell := g.Board.At(pos).(type) {
 	case core.Controller:
 		return cell.Colony == colony
-	case *core.Controller:
-		return cell != nil && cell.Colony == colony
 	case core.Depot:
 		return cell.Colony == colony
-	case *core.Depot:
-		return cell != nil && cell.Colony == colony
 	case core.Farm:
 		return cell.Colony == colony || (cell.Owner != nil && cell.Owner.Colony == colony && g.farmOwnerAlive(cell.Owner))
 	case core.Spawner:
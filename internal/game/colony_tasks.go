@@ -230,8 +230,8 @@ func (g *Game) resourceTaskCandidates(center core.Position, radius int) []core.P
 		}
 		for dc := -radius; dc <= radius; dc++ {
 			pos := util.NewPos(r, center.C+dc)
-			switch g.Board.At(pos).(type) {
-			case core.Food, core.Resource:
+			switch g.Board.KindAt(pos) {
+			case core.CellFood, core.CellResource:
 				out = append(out, pos)
 			}
 		}
@@ -255,8 +255,8 @@ func (g *Game) farmingTaskCandidates(colony *core.Colony, center core.Position,
 		}
 		for dc := -radius; dc <= radius; dc++ {
 			pos := util.NewPos(r, center.C+dc)
-			switch farm := g.Board.At(pos).(type) {
-			case core.Farm:
+			if g.Board.KindAt(pos) == core.CellFarm {
+				farm := g.Board.CellAtIdx(util.Idx(pos))
 				if farm.Colony == colony || (farm.Owner != nil && farm.Owner.Colony == colony) {
 					out = append(out, pos)
 				}
@@ -272,8 +272,8 @@ func (g *Game) farmingTaskCandidates(colony *core.Colony, center core.Position,
 		}
 	}
 	sort.Slice(out, func(i, j int) bool {
-		leftFarm := g.Board.At(out[i]) != nil
-		rightFarm := g.Board.At(out[j]) != nil
+		leftFarm := g.Board.KindAt(out[i]) != core.CellEmpty
+		rightFarm := g.Board.KindAt(out[j]) != core.CellEmpty
 		if leftFarm != rightFarm {
 			return leftFarm
 		}
@@ -680,6 +680,9 @@ func (g *Game) releaseColonyTask(colony *core.Colony, task *core.ColonyTask, mar
 	}
 	if markDone {
 		task.MarkDone()
+		if g != nil {
+			g.emitEvent(EventTaskCompleted, task.Pos, task.Owner, nil, nil, task.Type.String())
+		}
 	}
 	owner := task.Owner
 	if owner != nil && owner.CurrTask == task {
@@ -752,7 +755,7 @@ func (g *Game) roleTaskBuildSatisfied(task *core.ColonyTask) bool {
 			return ok
 		case core.BuildDepot:
 			switch g.Board.At(task.Pos).(type) {
-			case core.Depot, *core.Depot:
+			case core.Depot:
 				return true
 			default:
 				return false
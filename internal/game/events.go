@@ -0,0 +1,179 @@
+package game
+
+import (
+	"encoding/json"
+	"golab/internal/core"
+	"io"
+)
+
+type EventKind string
+
+const (
+	EventBirth           EventKind = "birth"
+	EventImmigration     EventKind = "immigration"
+	EventDeath           EventKind = "death"
+	EventKill            EventKind = "kill"
+	EventControllerRaid  EventKind = "controller_raid"
+	EventDepotRaid       EventKind = "depot_raid"
+	EventBuild           EventKind = "build"
+	EventColonyFounded   EventKind = "colony_founded"
+	EventColonyDissolved EventKind = "colony_dissolved"
+	EventTaskCompleted   EventKind = "task_completed"
+)
+
+// Event is one simulation occurrence. Bot and Other are board registry IDs,
+// which are reused after a bot dies; Colony and OtherColony are stable per
+// game and start at 1, with 0 meaning no colony. Seed is left for subscribers
+// that label runs sharing one sink.
+type Event struct {
+	Seed        int64      `json:"seed,omitempty"`
+	Tick        int        `json:"tick"`
+	Kind        EventKind  `json:"kind"`
+	Row         int        `json:"row"`
+	Col         int        `json:"col"`
+	Bot         core.BotID `json:"bot"`
+	Other       core.BotID `json:"other"`
+	Colony      int        `json:"colony,omitempty"`
+	OtherColony int        `json:"other_colony,omitempty"`
+	Count       int        `json:"count,omitempty"`
+	Detail      string     `json:"detail,omitempty"`
+}
+
+type EventHandler func(Event)
+
+// EventBus fans simulation events out to subscribers in subscription order.
+// Emitting with no subscribers costs a length check.
+type EventBus struct {
+	handlers []EventHandler
+}
+
+func (b *EventBus) Subscribe(handler EventHandler) {
+	if handler != nil {
+		b.handlers = append(b.handlers, handler)
+	}
+}
+
+func (b *EventBus) Active() bool {
+	return len(b.handlers) > 0
+}
+
+func (b *EventBus) Emit(event Event) {
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+}
+
+// EventRecorder keeps every event in memory, for tests and UI panels.
+type EventRecorder struct {
+	Events []Event
+}
+
+func (r *EventRecorder) Record(event Event) {
+	r.Events = append(r.Events, event)
+}
+
+func (r *EventRecorder) Count(kind EventKind) int {
+	count := 0
+	for _, event := range r.Events {
+		if event.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// EventSink writes events as NDJSON, one object per line.
+type EventSink struct {
+	out  io.Writer
+	json *json.Encoder
+	err  error
+}
+
+func NewEventSink(out io.Writer) *EventSink {
+	return &EventSink{out: out, json: json.NewEncoder(out)}
+}
+
+func (s *EventSink) Write(event Event) {
+	if s.err == nil {
+		s.err = s.json.Encode(event)
+	}
+}
+
+func (s *EventSink) Close() error {
+	if closer, ok := s.out.(io.Closer); ok {
+		if err := closer.Close(); err != nil && s.err == nil {
+			s.err = err
+		}
+	}
+	return s.err
+}
+
+func (g *Game) Events() *EventBus {
+	return &g.events
+}
+
+// ColonyID returns the stable event ID for colony, assigning the next one on
+// first use.
+func (g *Game) ColonyID(colony *core.Colony) int {
+	if colony == nil {
+		return 0
+	}
+	if id, ok := g.colonyIDs[colony]; ok {
+		return id
+	}
+	if g.colonyIDs == nil {
+		g.colonyIDs = map[*core.Colony]int{}
+	}
+	id := len(g.colonyIDs) + 1
+	g.colonyIDs[colony] = id
+	return id
+}
+
+// emitEvent reports kind at pos. otherColony overrides other's colony for
+// events that target a structure rather than a bot, such as raids.
+func (g *Game) emitEvent(kind EventKind, pos core.Position, bot, other *core.Bot, otherColony *core.Colony, detail string) {
+	if !g.events.Active() {
+		return
+	}
+	event := Event{
+		Tick:   g.logicTick,
+		Kind:   kind,
+		Row:    pos.R,
+		Col:    pos.C,
+		Bot:    g.eventBotID(bot),
+		Other:  g.eventBotID(other),
+		Detail: detail,
+	}
+	if bot != nil {
+		event.Colony = g.ColonyID(bot.Colony)
+	}
+	if otherColony == nil && other != nil {
+		otherColony = other.Colony
+	}
+	event.OtherColony = g.ColonyID(otherColony)
+	g.events.Emit(event)
+}
+
+func (g *Game) emitColonyEvent(kind EventKind, colony *core.Colony, bot *core.Bot, detail string) {
+	if !g.events.Active() || colony == nil {
+		return
+	}
+	g.events.Emit(Event{
+		Tick:   g.logicTick,
+		Kind:   kind,
+		Row:    colony.Center.R,
+		Col:    colony.Center.C,
+		Bot:    g.eventBotID(bot),
+		Other:  core.NoBotID,
+		Colony: g.ColonyID(colony),
+		Count:  len(colony.Members),
+		Detail: detail,
+	})
+}
+
+func (g *Game) eventBotID(bot *core.Bot) core.BotID {
+	if bot == nil || g.Board == nil {
+		return core.NoBotID
+	}
+	return g.Board.BotIDOf(bot)
+}
@@ -9,6 +9,7 @@ import (
 	"golab/internal/tasking"
 	"golab/internal/ui"
 	"golab/internal/util"
+	"maps"
 	"math/rand"
 	"sort"
 	"time"
@@ -45,12 +46,29 @@ type Game struct {
 	totalControllerRaids int
 	totalDepotRaids      int
 	totalSpawnerBirths   int
+	totalDeaths          int
+	totalImmigrants      int
+	metrics              *MetricsExporter
+	metricsSeed          int64
+	metricsLast          metricsCounters
+	events               EventBus
+	heatmaps             bool
+	colonyIDs            map[*core.Colony]int
+	deathsByCause        map[core.DeathCause]int
+	colonyDeaths         map[*core.Colony]map[core.DeathCause]int
 	selectedColony       *core.Colony
 	botIterationIDs      []core.BotID
+	botWorkers           int
+	random               *util.Random
+	botStripes           []botStripe
+	planner              *tasking.Planner
 	envIterationCells    []int
 	tpsWindowStart       time.Time
 	tpsWindowTick        int
 	scaleMode            bool
+	rewind               *rewindBuffer
+	invariants           *invariantChecker
+	phases               *phaseClock
 }
 
 const (
@@ -111,10 +129,14 @@ func NewGame(config *conf.Config) *Game {
 		maxHp:         0,
 		currGen:       0,
 		gameMaster:    NewMockGameMaster(),
+		phases:        newPhaseClock(false),
 	}
 }
 
 func (g *Game) Initialize() {
+	if g.random != nil {
+		defer util.UseRandom(util.UseRandom(g.random))
+	}
 	g.initialBotsGeneration()
 	g.generateWater()
 	g.populateBoard()
@@ -124,6 +146,9 @@ func (g *Game) Initialize() {
 
 func (g *Game) ResetSimulation() {
 	g.Board = core.NewBoard()
+	if g.heatmaps {
+		g.Board.EnableHeat()
+	}
 	g.Colonies = nil
 	g.InitialGenome = core.GetInitialGenome(g.config.UseInitialGenome)
 	g.maxHp = 0
@@ -144,9 +169,18 @@ func (g *Game) ResetSimulation() {
 	g.totalControllerRaids = 0
 	g.totalDepotRaids = 0
 	g.totalSpawnerBirths = 0
+	g.totalDeaths = 0
+	g.totalImmigrants = 0
+	g.metricsLast = metricsCounters{}
+	g.colonyIDs = nil
+	g.deathsByCause = nil
+	g.colonyDeaths = nil
 	g.selectedColony = nil
 	g.tpsWindowStart = time.Time{}
 	g.tpsWindowTick = 0
+	if g.rewind != nil {
+		g.rewind.clear()
+	}
 	g.config.LiveBots = 0
 	g.State = &conf.GameState{LastLogic: time.Now()}
 	if g.gameMasterEnabled {
@@ -196,6 +230,9 @@ func (g *Game) SeedTargetBots(targetBots int, seed int64) error {
 	if targetBots < 0 {
 		return errors.New("target bot count must be non-negative")
 	}
+	if g.random != nil {
+		defer util.UseRandom(util.UseRandom(g.random))
+	}
 	candidates := make([]int, 0, util.Cells)
 	for cellIdx := 0; cellIdx < util.Cells; cellIdx++ {
 		pos := util.PosOf(cellIdx)
@@ -227,6 +264,14 @@ func (g *Game) SeedTargetBots(targetBots int, seed int64) error {
 	return nil
 }
 
+// SetRandom gives g its own PRNG state. Initialization, bot seeding and
+// ticks make it current while they run and put the previous state back
+// afterwards, so several games in one process each replay their seed as if
+// they ran alone. Step such games from one goroutine; see util.UseRandom.
+func (g *Game) SetRandom(r *util.Random) {
+	g.random = r
+}
+
 func (g *Game) RunHeadlessFrames(count int) {
 	for range count {
 		g.runLogicTick()
@@ -269,6 +314,28 @@ func (g *Game) SpawnerBirths() int {
 	return g.totalSpawnerBirths
 }
 
+func (g *Game) Deaths() int {
+	return g.totalDeaths
+}
+
+func (g *Game) DeathsByCause() map[core.DeathCause]int {
+	return maps.Clone(g.deathsByCause)
+}
+
+// ColonyDeathsByCause returns per-colony death counts, including colonies that
+// have since dissolved.
+func (g *Game) ColonyDeathsByCause() map[*core.Colony]map[core.DeathCause]int {
+	out := make(map[*core.Colony]map[core.DeathCause]int, len(g.colonyDeaths))
+	for colony, causes := range g.colonyDeaths {
+		out[colony] = maps.Clone(causes)
+	}
+	return out
+}
+
+func (g *Game) Immigrants() int {
+	return g.totalImmigrants
+}
+
 func (g *Game) BotEvolutionScore(bot *core.Bot) int {
 	return g.botEvolutionScoreWithProfile(bot, g.BotEvolutionProfile(bot))
 }
@@ -362,15 +429,15 @@ func (g *Game) colonyCenterController(colony *core.Colony) (bool, *core.Bot) {
 	if g == nil || g.Board == nil || colony == nil {
 		return false, nil
 	}
-	switch ctrl := g.Board.At(colony.Center).(type) {
-	case core.Controller:
-		if ctrl.Colony == colony && g.controllerOwnerAlive(&ctrl) {
-			return true, ctrl.Owner
-		}
-	case *core.Controller:
-		if ctrl != nil && ctrl.Colony == colony && g.controllerOwnerAlive(ctrl) {
-			return true, ctrl.Owner
-		}
+	// Every colony bot's rank asks this, so read the columns rather than
+	// boxing the controller through At.
+	cell := g.Board.CellAtIdx(util.Idx(colony.Center))
+	if cell.Kind != core.CellController || cell.Colony != colony {
+		return false, nil
+	}
+	ctrl := core.Controller{Pos: colony.Center, Colony: cell.Colony, Owner: cell.Owner, Amount: cell.Amount, WaterAmount: cell.Extra}
+	if g.controllerOwnerAlive(&ctrl) {
+		return true, ctrl.Owner
 	}
 	return false, nil
 }
@@ -389,6 +456,19 @@ func (g *Game) BestEvolutionScore() int {
 	return 0
 }
 
+func (g *Game) ChampionGenome() (core.Genome, bool) {
+	if len(g.eliteGenomes) > 0 {
+		return g.eliteGenomes[0].genome, true
+	}
+	if g.hasGenerationSeedGenome {
+		return g.generationSeedGenome, true
+	}
+	if bot := g.bestLiveBot(); bot != nil {
+		return normalizedEvolutionGenome(bot.Genome), true
+	}
+	return core.Genome{}, false
+}
+
 func (g *Game) EnableGameMaster(interval int) {
 	if interval <= 0 {
 		interval = 1
@@ -418,64 +498,43 @@ func (g *Game) EnableExternalGameMaster(interval int, command []string, timeout
 }
 
 func (g *Game) environmentActions() {
-	grid := *g.Board.GetGrid()
 	g.envIterationCells = g.Board.SortedActiveEnvironmentCells(g.envIterationCells[:0])
 	for _, cellIdx := range g.envIterationCells {
-		if cellIdx < 0 || cellIdx >= len(grid) {
+		if cellIdx < 0 || cellIdx >= util.Cells {
 			continue
 		}
 		if g.Board.IsFrozenIdx(cellIdx) {
 			continue
 		}
-		cell := grid[cellIdx]
-		switch v := cell.(type) {
-		case core.Organics:
-			pos := util.PosOf(cellIdx)
-			if v.Amount <= 1 {
-				g.Board.Clear(pos)
+		switch cell := g.Board.CellAtIdx(cellIdx); cell.Kind {
+		case core.CellOrganics:
+			if cell.Amount <= 1 {
+				g.Board.Clear(util.PosOf(cellIdx))
 				continue
 			}
-			v.Amount--
-			g.Board.Set(pos, v)
+			g.Board.SetAmountIdx(cellIdx, cell.Amount-1)
 			continue
-		case core.Controller:
+		case core.CellController:
+			v := g.Board.AtIdx(cellIdx).(core.Controller)
 			pos := util.PosOf(cellIdx)
 			g.handleController(&v, pos)
 			if g.Board.At(pos) != nil {
 				g.Board.Set(pos, v)
 			}
 			continue
-		case *core.Controller:
-			if v == nil {
-				continue
-			}
-			pos := util.PosOf(cellIdx)
-			g.handleController(v, pos)
-			if g.Board.At(pos) != nil {
-				g.Board.Set(pos, *v)
-			}
-			continue
-		case core.Depot:
+		case core.CellDepot:
+			v := g.Board.AtIdx(cellIdx).(core.Depot)
 			pos := util.PosOf(cellIdx)
 			g.handleDepot(&v, pos)
 			if g.Board.At(pos) != nil {
 				g.Board.Set(pos, v)
 			}
 			continue
-		case *core.Depot:
-			if v == nil {
-				continue
-			}
-			pos := util.PosOf(cellIdx)
-			g.handleDepot(v, pos)
-			if g.Board.At(pos) != nil {
-				g.Board.Set(pos, *v)
-			}
-			continue
-		case core.Farm:
-			if v.Amount <= 0 {
+		case core.CellFarm:
+			if cell.Amount <= 0 {
 				continue
 			}
+			v := g.Board.AtIdx(cellIdx).(core.Farm)
 			pos := util.PosOf(cellIdx)
 			produced := 0
 			for range g.farmFoodOutputs(pos, v) {
@@ -486,23 +545,23 @@ func (g *Game) environmentActions() {
 				if g.Board.IsFrozen(foodPos) {
 					continue
 				}
-				g.Board.Set(foodPos, core.Food{Pos: foodPos, Amount: 1})
+				g.Board.SetCellIdx(idx(foodPos), core.Cell{Kind: core.CellFood, Amount: 1})
 				g.emitEventPheromone(foodPos, core.PheromoneFood)
 				produced++
 			}
 			if produced > 0 {
-				v.Amount--
-				g.Board.Set(pos, v)
+				g.Board.SetAmountIdx(cellIdx, cell.Amount-1)
 				g.emitEventPheromone(pos, core.PheromoneFood)
 			}
 			continue
-		case core.Spawner:
+		case core.CellSpawner:
+			v := g.Board.AtIdx(cellIdx).(core.Spawner)
 			pos := util.PosOf(cellIdx)
 			if g.tryColonySpawnerAutoBirth(pos, &v) {
 				g.Board.Set(pos, v)
 			}
 			continue
-		case core.Poison:
+		case core.CellPoison:
 			g.emitEventPheromone(util.PosOf(cellIdx), core.PheromoneDanger)
 			continue
 		}
@@ -539,20 +598,29 @@ func (g *Game) regrowBiomeFood(cellIdx int) {
 	if (cellIdx+g.logicTick*7919)%g.config.FertileFoodRegrowPeriod != 0 {
 		return
 	}
-	pos := util.PosOf(cellIdx)
-	g.Board.Set(pos, core.Food{Pos: pos, Amount: 1})
-	g.emitEventPheromone(pos, core.PheromoneFood)
+	// Food only grows into empty ground; writing over a bot would drop it
+	// from the board without a death.
+	if g.Board.KindAtIdx(cellIdx) != core.CellEmpty || g.Board.IsFrozenIdx(cellIdx) {
+		return
+	}
+	g.Board.SetCellIdx(cellIdx, core.Cell{Kind: core.CellFood, Amount: 1})
+	g.emitEventPheromone(util.PosOf(cellIdx), core.PheromoneFood)
 }
 
-func (g *Game) killBot(b *core.Bot, botIdx int) {
+func (g *Game) killBot(b *core.Bot, botIdx int, cause core.DeathCause) {
 	// if b.Path != nil {
 	// 	b.Path = nil
 	// }
+	g.emitEvent(EventDeath, util.PosOf(botIdx), b, nil, nil, cause.String())
+	g.recordDeath(b, botIdx, cause)
 	if b.CurrTask != nil {
 		b.CurrTask.Owner = nil
 	}
 	if c := b.Colony; c != nil {
 		c.RemoveMember(b)
+		if len(c.Members) == 0 {
+			g.emitColonyEvent(EventColonyDissolved, c, nil, "")
+		}
 	}
 	if p := b.Parent; p != nil {
 		p.RemoveOffspring(b)
@@ -618,7 +686,16 @@ func (g *Game) handleController(ctrl *core.Controller, pos util.Position) {
 		c.AddTask(task)
 	}
 
-	tasking.ProcessColonyTasks(ctrl, g.Board)
+	tasking.ProcessColonyTasks(ctrl, g.Board, g.pathPlanner())
+}
+
+// pathPlanner returns the path graph and flow-field cache for the current
+// board, starting over when the board was replaced by a restore.
+func (g *Game) pathPlanner() *tasking.Planner {
+	if g.planner == nil || g.planner.Board() != g.Board {
+		g.planner = tasking.NewPlanner(g.Board)
+	}
+	return g.planner
 }
 
 func (g *Game) releaseColonyWaterBridgeTasks(colony *core.Colony) {
@@ -729,19 +806,13 @@ func (g *Game) forEachLiveBotInRadius(center core.Position, radius int, visit fu
 	if g == nil || g.Board == nil || radius < 0 || visit == nil {
 		return
 	}
-	for r := center.R - radius; r <= center.R+radius; r++ {
-		if r < 0 || r >= core.Rows {
+	for i := range g.Board.InRadius(core.CellBot, center, radius) {
+		bot := g.Board.GetBot(util.PosOf(i))
+		if bot == nil {
 			continue
 		}
-		for dc := -radius; dc <= radius; dc++ {
-			pos := util.NewPos(r, center.C+dc)
-			bot := g.Board.GetBot(pos)
-			if bot == nil {
-				continue
-			}
-			if !visit(bot) {
-				return
-			}
+		if !visit(bot) {
+			return
 		}
 	}
 }
@@ -808,23 +879,16 @@ func (g *Game) claimNearbyColonyFarms(colony *core.Colony, center util.Position,
 		return 0
 	}
 	claimed := 0
-	for r := center.R - radius; r <= center.R+radius; r++ {
-		if r < 0 || r >= core.Rows {
+	for i := range g.Board.InRadius(core.CellFarm, center, radius) {
+		cell := g.Board.CellAtIdx(i)
+		if cell.Colony != nil || cell.Owner == nil || cell.Owner.Colony != colony {
 			continue
 		}
-		for dc := -radius; dc <= radius; dc++ {
-			pos := util.NewPos(r, center.C+dc)
-			farm, ok := g.Board.At(pos).(core.Farm)
-			if !ok || farm.Colony == colony || farm.Colony != nil {
-				continue
-			}
-			if farm.Owner == nil || farm.Owner.Colony != colony {
-				continue
-			}
-			farm.Colony = colony
-			g.Board.Set(pos, farm)
-			claimed++
-		}
+		pos := util.PosOf(i)
+		farm := g.Board.At(pos).(core.Farm)
+		farm.Colony = colony
+		g.Board.Set(pos, farm)
+		claimed++
 	}
 	return claimed
 }
@@ -849,6 +913,7 @@ func (g *Game) applyControllerCrowdingPressure(ctrl *core.Controller, pos util.P
 	}
 	for _, m := range nearby {
 		m.Hp = max(1, m.Hp-controllerCrowdHpTax)
+		m.Harm(core.DeathCrowding)
 		g.Board.MarkDirty(util.Idx(m.Pos))
 	}
 }
@@ -904,6 +969,13 @@ func (g *Game) Run() {
 			ui.BuildStaticLayer(g.Board)
 			ui.MarkSimulationResetComplete()
 		}
+		if tick, ok := ui.ConsumeRewindRequest(); ok {
+			if restored, ok := g.RewindTo(tick); ok {
+				ui.SetBoard(g.Board)
+				ui.BuildStaticLayer(g.Board)
+				ui.MarkRewindComplete(restored)
+			}
+		}
 		if g.config.Pause {
 			ui.DrawGrid(g.Board, g.Board.Bots)
 			sleepUntilNextInteractiveFrame(frameStart)
@@ -915,6 +987,49 @@ func (g *Game) Run() {
 	}
 }
 
+// RunSplit drives left and right side by side in one window. The window's
+// config paces left and right then runs exactly as many ticks, so the two
+// stay on the same tick. Resets and rewinds apply to both.
+func RunSplit(left, right *Game, labels [2]string) {
+	fmt.Println("Running split simulation...")
+	setPanes := func() {
+		ui.SetSplitPanes(left.splitPane(labels[0]), right.splitPane(labels[1]))
+	}
+	left.ResetSimulation()
+	right.ResetSimulation()
+	setPanes()
+
+	for !ui.Window.ShouldClose() {
+		frameStart := time.Now()
+		if ui.ConsumeSimulationResetRequest() {
+			left.ResetSimulation()
+			right.ResetSimulation()
+			setPanes()
+			ui.MarkSimulationResetComplete()
+		}
+		if tick, ok := ui.ConsumeRewindRequest(); ok {
+			if restored, ok := left.RewindTo(tick); ok {
+				right.RewindTo(restored)
+				setPanes()
+				ui.MarkRewindComplete(restored)
+			}
+		}
+		if left.config.Pause {
+			// Unpausing resets the clock of the active pane only; keep
+			// left's current so it does not catch up on the paused time.
+			left.State.LastLogic = frameStart
+		} else {
+			right.RunHeadlessFrames(left.step())
+		}
+		ui.DrawSplit()
+		sleepUntilNextInteractiveFrame(frameStart)
+	}
+}
+
+func (g *Game) splitPane(label string) ui.SplitPane {
+	return ui.SplitPane{Label: label, Board: g.Board, State: g.State, Actions: g}
+}
+
 func sleepUntilNextInteractiveFrame(frameStart time.Time) {
 	if remaining := interactiveFrameInterval - time.Since(frameStart); remaining > 0 {
 		time.Sleep(remaining)
@@ -933,36 +1048,36 @@ func (g *Game) generateWater() {
 func (g *Game) generateWaterBody(groupID int) {
 	center := core.NewRandomPosition()
 	if center.R <= 1 || center.R >= core.Rows-2 {
-		center.R = 2 + rand.Intn(core.Rows-4)
+		center.R = 2 + util.RandIntn(core.Rows-4)
 	}
 
-	isRiver := rand.Intn(100) >= 35
-	steps := 16 + rand.Intn(18)
-	radius := 2 + rand.Intn(3)
+	isRiver := util.RandIntn(100) >= 35
+	steps := 16 + util.RandIntn(18)
+	radius := 2 + util.RandIntn(3)
 	if isRiver {
-		steps = 34 + rand.Intn(38)
-		radius = 1 + rand.Intn(2)
+		steps = 34 + util.RandIntn(38)
+		radius = 1 + util.RandIntn(2)
 	}
 
-	dirIdx := rand.Intn(len(core.PosClock))
+	dirIdx := util.RandIntn(len(core.PosClock))
 	for step := 0; step < steps; step++ {
 		stampRadius := radius
-		if rand.Intn(100) < 28 {
+		if util.RandIntn(100) < 28 {
 			stampRadius++
 		}
-		if !isRiver && rand.Intn(100) < 20 {
+		if !isRiver && util.RandIntn(100) < 20 {
 			stampRadius++
 		}
 		g.stampWaterBrush(center, groupID, stampRadius)
 
-		turn := rand.Intn(3) - 1
+		turn := util.RandIntn(3) - 1
 		if !isRiver {
-			turn = rand.Intn(5) - 2
+			turn = util.RandIntn(5) - 2
 		}
 		dirIdx = (dirIdx + turn + len(core.PosClock)) % len(core.PosClock)
 
 		stride := 1
-		if isRiver && rand.Intn(100) < 35 {
+		if isRiver && util.RandIntn(100) < 35 {
 			stride = 2
 		}
 		for range stride {
@@ -991,7 +1106,7 @@ func (g *Game) stampWaterBrush(center core.Position, groupID, radius int) {
 			if dist2 > outer2 {
 				continue
 			}
-			if dist2 > inner2 && rand.Intn(100) < 35 {
+			if dist2 > inner2 && util.RandIntn(100) < 35 {
 				continue
 			}
 			pos := center.AddRowCol(dr, dc)
@@ -1002,7 +1117,8 @@ func (g *Game) stampWaterBrush(center core.Position, groupID, radius int) {
 	}
 }
 
-func (g *Game) step() {
+// step runs the logic ticks that are due this frame and returns how many ran.
+func (g *Game) step() int {
 	var maxLogicPerFrame int
 	if g.config.LiveBots < 2000 {
 		maxLogicPerFrame = 64
@@ -1014,38 +1130,59 @@ func (g *Game) step() {
 		maxLogicPerFrame = 8
 	}
 
-	for executed := 0; executed < maxLogicPerFrame &&
+	executed := 0
+	for ; executed < maxLogicPerFrame &&
 		time.Since(g.State.LastLogic) >= g.config.LogicStep; executed++ {
 
 		g.runLogicTick()
 		g.State.LastLogic = g.State.LastLogic.Add(g.config.LogicStep)
 	}
+	return executed
 }
 
 func (g *Game) runLogicTick() {
+	if g.random != nil {
+		defer util.UseRandom(util.UseRandom(g.random))
+	}
+	clock := g.phases
+	clock.begin()
+	g.captureRewind()
 	g.logicTick++
+	clock.lap(PhaseOther)
 	liveBots, champion := g.liveBotCountAndGenerationChampion()
 	g.config.LiveBots = liveBots
 	if champion != nil {
 		g.rememberGenerationChampion(champion)
 	}
+	clock.lap(PhaseChampionScan)
 	switch n := g.config.LiveBots; {
 	case n == 0:
 		g.currGen++
 		g.initialBotsGeneration()
 		g.populateBoard()
+		g.config.LiveBots = g.liveBotCount()
+		clock.lap(PhaseGeneration)
 	default:
 		g.botsActions()
+		clock.lap(PhaseBots)
 		g.environmentActions()
+		clock.lap(PhaseEnvironment)
 		liveAfterActions := g.liveBotCount()
 		if liveAfterActions <= g.immigrationThreshold() {
-			g.spawnRandomImmigrants(g.immigrationThreshold() - liveAfterActions)
+			g.totalImmigrants += g.spawnRandomImmigrants(g.immigrationThreshold() - liveAfterActions)
 		}
+		g.config.LiveBots = g.liveBotCount()
+		clock.lap(PhaseImmigration)
 	}
-	g.config.LiveBots = g.liveBotCount()
 	g.updatePheromones()
+	clock.lap(PhasePheromones)
 	g.runGameMasterTick()
+	clock.lap(PhaseGameMaster)
+	g.Board.SampleHeat()
 	g.updateLogicRate()
+	g.recordMetrics()
+	g.checkInvariantsForTick()
+	clock.end()
 }
 
 func (g *Game) updateLogicRate() {
@@ -1068,6 +1205,7 @@ func (g *Game) updateLogicRate() {
 		return
 	}
 	g.State.LogicTicksPerSecond = float64(ticks) / elapsed.Seconds()
+	g.publishTickPhases()
 	g.tpsWindowStart = now
 	g.tpsWindowTick = g.logicTick
 }
@@ -1125,6 +1263,17 @@ func (g *Game) spawnRandomImmigrantAtWithBudget(pos core.Position, budget int) i
 	if hasElite && elite.rank.colonyLinked && budget > spawned {
 		spawned += g.seedEliteCohortAround(pos, elite, min(colonyEliteCohortSize-1, budget-spawned))
 	}
+	if g.events.Active() {
+		g.events.Emit(Event{
+			Tick:  g.logicTick,
+			Kind:  EventImmigration,
+			Row:   pos.R,
+			Col:   pos.C,
+			Bot:   g.eventBotID(g.Board.GetBot(pos)),
+			Other: core.NoBotID,
+			Count: spawned,
+		})
+	}
 	return spawned
 }
 
@@ -1152,6 +1301,9 @@ func (g *Game) newImmigrantBotWithElite(pos core.Position) (core.Bot, eliteGenom
 func (g *Game) liveBotCountAndGenerationChampion() (int, *core.Bot) {
 	count := 0
 	var best *core.Bot
+	// The leader's rank is kept rather than recomputed per comparison: a rank
+	// walks the bot's colony members, which dominated the scan at scale.
+	var bestRank generationChampionRank
 	g.botIterationIDs = g.sortedActiveBotIDs(g.botIterationIDs[:0])
 	for _, id := range g.botIterationIDs {
 		bot := g.Board.BotByID(id)
@@ -1162,23 +1314,14 @@ func (g *Game) liveBotCountAndGenerationChampion() (int, *core.Bot) {
 		if g.smartEvolutionEnabled() && !g.scaleMode {
 			g.rememberEliteCandidate(bot)
 		}
-		if g.generationChampionRanksBefore(bot, best) {
-			best = bot
+		rank := g.generationChampionRankForBot(bot)
+		if best == nil || generationChampionRankBefore(rank, bestRank) {
+			best, bestRank = bot, rank
 		}
 	}
 	return count, best
 }
 
-func (g *Game) generationChampionRanksBefore(candidate, current *core.Bot) bool {
-	if current == nil {
-		return true
-	}
-	return generationChampionRankBefore(
-		g.generationChampionRankForBot(candidate),
-		g.generationChampionRankForBot(current),
-	)
-}
-
 func generationChampionRanksBefore(candidate, current *core.Bot) bool {
 	if current == nil {
 		return true
@@ -1493,6 +1636,7 @@ func (g *Game) populateBoard() {
 	g.Board.CopyFrozenFrom(oldBoard)
 	g.Board.CopyBiomesFrom(oldBoard)
 	g.Board.CopyPheromonesFrom(oldBoard)
+	g.Board.CopyHeatFrom(oldBoard)
 	g.Board.MarkAllDirty()
 	ui.SetBoard(g.Board)
 	for r := range core.Rows {
@@ -1502,46 +1646,14 @@ func (g *Game) populateBoard() {
 				g.Board.Set(pos, core.Wall{Pos: pos})
 				continue
 			}
-			if spawner, ok := oldBoard.At(pos).(core.Spawner); ok {
-				g.Board.Set(pos, spawner)
+			switch oldBoard.KindAt(pos) {
+			case core.CellSpawner, core.CellFarm, core.CellFood, core.CellBuilding, core.CellWater, core.CellDepot, core.CellController:
+				g.Board.Set(pos, oldBoard.At(pos))
 				continue
-			}
-			if _, ok := oldBoard.At(pos).(core.Organics); ok {
+			case core.CellOrganics:
 				g.Board.Clear(pos)
 				continue
 			}
-			if f, ok := oldBoard.At(pos).(core.Farm); ok {
-				g.Board.Set(pos, f)
-				continue
-			}
-			if fd, ok := oldBoard.At(pos).(core.Food); ok {
-				g.Board.Set(pos, fd)
-				continue
-			}
-			if bld, ok := oldBoard.At(pos).(core.Building); ok {
-				g.Board.Set(pos, bld)
-				continue
-			}
-			if c, ok := oldBoard.At(pos).(core.Water); ok {
-				g.Board.Set(pos, c)
-				continue
-			}
-			if d, ok := oldBoard.At(pos).(core.Depot); ok {
-				g.Board.Set(pos, d)
-				continue
-			}
-			if d, ok := oldBoard.At(pos).(*core.Depot); ok && d != nil {
-				g.Board.Set(pos, *d)
-				continue
-			}
-			if c, ok := oldBoard.At(pos).(core.Controller); ok {
-				g.Board.Set(pos, c)
-				continue
-			}
-			if c, ok := oldBoard.At(pos).(*core.Controller); ok && c != nil {
-				g.Board.Set(pos, *c)
-				continue
-			}
 			if b := oldBoard.GetBot(pos); b != nil {
 				g.Board.AddBot(pos, b)
 				continue
@@ -1593,7 +1705,7 @@ func (g *Game) biomeSpawnProfile(biome core.Biome) biomeSpawnProfile {
 
 func (g *Game) shouldSpawnOre(pos core.Position, profile biomeSpawnProfile) bool {
 	chance := g.oreSpawnChancePerMille(pos, profile)
-	return chance > 0 && rand.Intn(1000) < chance
+	return chance > 0 && util.RandIntn(1000) < chance
 }
 
 func (g *Game) oreSpawnChancePerMille(pos core.Position, profile biomeSpawnProfile) int {
@@ -1813,50 +1925,63 @@ func (g *Game) eliteMutationRate(eliteIdx int) int {
 }
 
 func (g *Game) botsActions() {
+	if g.botWorkers > 1 {
+		g.botsActionsParallel()
+		return
+	}
 	g.botIterationIDs = g.sortedActiveBotIDs(g.botIterationIDs[:0])
 	for _, id := range g.botIterationIDs {
-		b := g.Board.BotByID(id)
-		if b == nil {
-			continue
-		}
-		i := g.Board.BotCell(id)
-		if i < 0 {
-			continue
-		}
-		pos := util.PosOf(i)
-		if g.Board.IsFrozen(pos) {
-			continue
-		}
-		b.Age++
-		b.Hp -= g.calcHpChange()
-		heartProtected := g.applyColonyHeartProtection(pos, b)
-		b.Hp = min(b.Hp, 500)
-		ageExpired := g.config.MaxBotAge > 0 && b.Age > g.config.MaxBotAge && !g.colonyHeartAgeProtected(pos, b)
-		if b.Hp <= 0 || ageExpired {
-			g.emitEventPheromone(pos, core.PheromoneDanger)
-			g.killBot(b, i)
-			if rand.Intn(100) < 33 {
-				g.Board.Set(pos, core.Organics{Pos: pos, Amount: g.config.OrganicInitialAmount})
-			} else {
-				g.Board.Clear(pos)
-			}
-			continue
-		}
-		if b.CurrTask != nil && b.CurrTask.Type == core.MaintainConnectionTask && b.CurrTask.IsDone {
-			continue
+		g.stepBot(id)
+	}
+}
+
+// stepBot runs one tick of upkeep and genome for the bot registered as id.
+func (g *Game) stepBot(id core.BotID) {
+	b := g.Board.BotByID(id)
+	if b == nil {
+		return
+	}
+	i := g.Board.BotCell(id)
+	if i < 0 {
+		return
+	}
+	pos := util.PosOf(i)
+	if g.Board.IsFrozen(pos) {
+		return
+	}
+	b.Age++
+	b.Hp -= g.calcHpChange()
+	heartProtected := g.applyColonyHeartProtection(pos, b)
+	b.Hp = min(b.Hp, 500)
+	ageExpired := g.config.MaxBotAge > 0 && b.Age > g.config.MaxBotAge && !g.colonyHeartAgeProtected(pos, b)
+	if b.Hp <= 0 || ageExpired {
+		cause := core.DeathAge
+		if b.Hp <= 0 {
+			cause = b.HpDeathCause()
 		}
-		if g.tryColonyCohesion(pos, b) {
-			if g.Board.GetBot(b.Pos) == b {
-				g.applyColonyHeartProtection(b.Pos, b)
-				b.Hp = min(b.Hp, 500)
-			}
-			continue
+		g.emitEventPheromone(pos, core.PheromoneDanger)
+		g.killBot(b, i, cause)
+		if util.RandIntn(100) < 33 {
+			g.Board.Set(pos, core.Organics{Pos: pos, Amount: g.config.OrganicInitialAmount})
+		} else {
+			g.Board.Clear(pos)
 		}
-		g.botAction(pos, b)
-		if heartProtected && g.Board.GetBot(b.Pos) == b {
+		return
+	}
+	if b.CurrTask != nil && b.CurrTask.Type == core.MaintainConnectionTask && b.CurrTask.IsDone {
+		return
+	}
+	if g.tryColonyCohesion(pos, b) {
+		if g.Board.GetBot(b.Pos) == b {
 			g.applyColonyHeartProtection(b.Pos, b)
 			b.Hp = min(b.Hp, 500)
 		}
+		return
+	}
+	g.botAction(pos, b)
+	if heartProtected && g.Board.GetBot(b.Pos) == b {
+		g.applyColonyHeartProtection(b.Pos, b)
+		b.Hp = min(b.Hp, 500)
 	}
 }
 
@@ -1997,7 +2122,7 @@ func (g *Game) trySpawnerAssistedDivision(parentPos core.Position, b *core.Bot)
 	g.inheritColonyConnection(b, child)
 	b.Hp -= g.config.DivisionCost
 	g.Board.AddBot(target.childPos, child)
-	g.successfulDivisions++
+	g.recordBirth(b, child)
 	g.totalSpawnerBirths++
 	g.emitEventPheromone(target.pos, core.PheromoneFood)
 	g.Board.MarkDirty(idx(parentPos))
@@ -2012,37 +2137,32 @@ func (g *Game) findSpawnerDivisionTarget(b *core.Bot) (spawnerDivisionTarget, bo
 	radius := max(0, g.config.SpawnerAccessRadius)
 	found := false
 	best := spawnerDivisionTarget{}
-	for r := b.Pos.R - radius; r <= b.Pos.R+radius; r++ {
-		if r < 0 || r >= core.Rows {
+	for i := range g.Board.InRadius(core.CellSpawner, b.Pos, radius) {
+		pos := util.PosOf(i)
+		spawner := g.Board.At(pos).(core.Spawner)
+		if spawner.Amount <= 0 || !g.spawnerFriendlyToBot(spawner, b) {
 			continue
 		}
-		for dc := -radius; dc <= radius; dc++ {
-			pos := util.NewPos(r, b.Pos.C+dc)
-			spawner, ok := g.Board.At(pos).(core.Spawner)
-			if !ok || spawner.Amount <= 0 || !g.spawnerFriendlyToBot(spawner, b) {
-				continue
-			}
-			childPos, ok := g.findDivisionChildPosAround(pos, b)
-			if !ok {
-				continue
-			}
-			distance := boardDistance(b.Pos, pos)
-			if distance > radius {
-				continue
-			}
-			candidate := spawnerDivisionTarget{
-				pos:      pos,
-				spawner:  spawner,
-				childPos: childPos,
-				distance: distance,
-				index:    idx(pos),
-			}
-			if !found ||
-				candidate.distance < best.distance ||
-				(candidate.distance == best.distance && candidate.index < best.index) {
-				best = candidate
-				found = true
-			}
+		childPos, ok := g.findDivisionChildPosAround(pos, b)
+		if !ok {
+			continue
+		}
+		distance := boardDistance(b.Pos, pos)
+		if distance > radius {
+			continue
+		}
+		candidate := spawnerDivisionTarget{
+			pos:      pos,
+			spawner:  spawner,
+			childPos: childPos,
+			distance: distance,
+			index:    i,
+		}
+		if !found ||
+			candidate.distance < best.distance ||
+			(candidate.distance == best.distance && candidate.index < best.index) {
+			best = candidate
+			found = true
 		}
 	}
 	return best, found
@@ -2065,6 +2185,13 @@ func (g *Game) findEmptyUnfrozenPosAround(center core.Position) (core.Position,
 }
 
 func (g *Game) botAction(pos core.Position, b *core.Bot) {
+	g.botActionIn(nil, pos, b)
+}
+
+// botActionIn runs the genome of b. With a stripe it is running on a stripe
+// worker: the first opcode that is not stripe-local sets s.bailed and returns
+// at once, leaving the caller to roll the bot back.
+func (g *Game) botActionIn(s *botStripe, pos core.Position, b *core.Bot) {
 	for range 5 {
 		op := core.DecodeOpcode(b.Genome.Matrix[b.Genome.Pointer])
 		if b.MaintainingConn() {
@@ -2073,6 +2200,10 @@ func (g *Game) botAction(pos core.Position, b *core.Bot) {
 		if taskOp, ok := g.colonyTaskOpcode(pos, b, op); ok {
 			op = taskOp
 		}
+		if s != nil && !stripeLocalOpcode(op) {
+			s.bailed = true
+			return
+		}
 		// fmt.Printf("opcode: %v\n", op)
 		switch op {
 		case core.OpDivide:
@@ -2104,7 +2235,7 @@ func (g *Game) botAction(pos core.Position, b *core.Bot) {
 			g.spendShared(b, g.config.DivisionFoodCost, g.config.DivisionOreCost)
 			b.Hp -= g.config.DivisionCost
 			g.Board.AddBot(newPos, child)
-			g.successfulDivisions++
+			g.recordBirth(b, child)
 			b.PointerJumpBy(6)
 			return
 
@@ -2120,7 +2251,11 @@ func (g *Game) botAction(pos core.Position, b *core.Bot) {
 
 		case core.OpMoveAbs:
 			b.Dir = util.PosClock[b.CmdArg(1)%8]
-			g.tryMove(pos, b)
+			if s != nil {
+				g.stripeMove(s, pos, b)
+			} else {
+				g.tryMove(pos, b)
+			}
 			b.PointerJumpBy(1)
 			return
 
@@ -2164,24 +2299,12 @@ func (g *Game) botAction(pos core.Position, b *core.Bot) {
 						b.PointerJumpBy(2)
 						return
 					}
-				case *core.Controller:
-					if g.raidController(b, ctrl) {
-						g.Board.MarkDirty(idx(attackPos))
-						b.PointerJumpBy(2)
-						return
-					}
 				case core.Depot:
 					if g.raidDepot(b, &ctrl) {
 						g.Board.Set(attackPos, ctrl)
 						b.PointerJumpBy(2)
 						return
 					}
-				case *core.Depot:
-					if g.raidDepot(b, ctrl) {
-						g.Board.MarkDirty(idx(attackPos))
-						b.PointerJumpBy(2)
-						return
-					}
 				}
 				b.PointerJumpBy(1)
 				continue
@@ -2198,14 +2321,19 @@ func (g *Game) botAction(pos core.Position, b *core.Bot) {
 				g.recordFoodStolen(b, other.Inventory.Food)
 				g.recordOreStolen(b, other.Inventory.Ore)
 				g.recordCombatKill(b)
+				g.emitEvent(EventKill, attackPos, b, other, nil, "")
+				g.killBot(other, idx(attackPos), core.DeathCombat)
 				g.Board.Clear(attackPos)
-				g.killBot(other, idx(attackPos))
 			}
 			b.PointerJumpBy(2)
 			return
 
 		case core.OpMove:
-			g.tryMove(pos, b)
+			if s != nil {
+				g.stripeMove(s, pos, b)
+			} else {
+				g.tryMove(pos, b)
+			}
 			b.PointerJumpBy(1)
 			return
 
@@ -2550,6 +2678,7 @@ func (g *Game) tryMove(oldPos core.Position, b *core.Bot) {
 		} else {
 			if b.CurrTask.Type == core.MaintainConnectionTask && oldPos == b.CurrTask.Pos {
 				b.CurrTask.MarkDone()
+				g.emitEvent(EventTaskCompleted, oldPos, b, nil, nil, b.CurrTask.Type.String())
 				return
 			}
 			if b.Colony == nil || b.Colony.WaterPathFlowField == nil {
@@ -2591,9 +2720,12 @@ func (g *Game) tryMove(oldPos core.Position, b *core.Bot) {
 
 func (g *Game) autoPickupOnMove(pos core.Position, b *core.Bot) bool {
 	c := g.config
-	switch v := g.Board.At(pos).(type) {
-	case core.Food:
-		foodGain := v.Amount
+	if !core.Inside(pos) {
+		return false
+	}
+	switch g.Board.KindAtIdx(idx(pos)) {
+	case core.CellFood:
+		foodGain := g.Board.CellAtIdx(idx(pos)).Amount
 		if foodGain <= 0 {
 			foodGain = 1
 		}
@@ -2602,14 +2734,13 @@ func (g *Game) autoPickupOnMove(pos core.Position, b *core.Bot) bool {
 		g.Board.Clear(pos)
 		g.emitEventPheromone(pos, core.PheromoneFood)
 		return true
-	case core.Resource:
+	case core.CellResource:
 		g.recordOreGathered(b, c.ResourceGrabGain)
 		b.Hp += c.ResourceGrabHpGain
-		v.Amount -= 10
-		if v.Amount <= 0 {
+		if amount := g.Board.CellAtIdx(idx(pos)).Amount - 10; amount <= 0 {
 			g.Board.Clear(pos)
 		} else {
-			g.Board.Set(pos, v)
+			g.Board.SetAmountIdx(idx(pos), amount)
 		}
 		g.emitEventPheromone(pos, core.PheromoneOre)
 		return true
@@ -2658,6 +2789,31 @@ func (g *Game) recordCombatKill(b *core.Bot) {
 	g.totalCombatKills++
 }
 
+func (g *Game) recordDeath(b *core.Bot, botIdx int, cause core.DeathCause) {
+	g.totalDeaths++
+	if g.deathsByCause == nil {
+		g.deathsByCause = map[core.DeathCause]int{}
+	}
+	g.deathsByCause[cause]++
+	if c := b.Colony; c != nil {
+		if g.colonyDeaths == nil {
+			g.colonyDeaths = map[*core.Colony]map[core.DeathCause]int{}
+		}
+		if g.colonyDeaths[c] == nil {
+			g.colonyDeaths[c] = map[core.DeathCause]int{}
+		}
+		g.colonyDeaths[c][cause]++
+	}
+	g.Board.RecordDeath(botIdx, cause)
+}
+
+func (g *Game) recordBirth(parent, child *core.Bot) {
+	g.successfulDivisions++
+	if child != nil {
+		g.emitEvent(EventBirth, child.Pos, child, parent, nil, "")
+	}
+}
+
 func (g *Game) recordControllerRaid(b *core.Bot) {
 	if b == nil {
 		return
@@ -2763,13 +2919,11 @@ func (g *Game) grab(pos core.Position, b *core.Bot) {
 		b.Genome.NextArg = 8
 		return
 	case core.Poison:
-		b.Hp = 0
+		g.killBot(b, idx(pos), core.DeathPoison)
 		g.Board.Clear(pos)
 		g.Board.Clear(grabPos)
 		g.emitEventPheromone(pos, core.PheromoneDanger)
 		g.emitEventPheromone(grabPos, core.PheromoneDanger)
-		b.PointerJumpBy(4)
-		b.Genome.NextArg = 4
 		return
 	case core.Controller:
 		if !controllerFriendlyToBot(&v, b) {
@@ -2789,24 +2943,6 @@ func (g *Game) grab(pos core.Position, b *core.Bot) {
 		b.PointerJumpBy(5)
 		b.Genome.NextArg = 5
 		return
-	case *core.Controller:
-		if !controllerFriendlyToBot(v, b) {
-			if g.raidController(b, v) {
-				g.Board.MarkDirty(idx(grabPos))
-				b.PointerJumpBy(5)
-				b.Genome.NextArg = 5
-			}
-			return
-		}
-		if !g.spendShared(b, c.ControllerGrabCost, 0) {
-			return
-		}
-		b.Hp += c.ControllerHpGain
-		v.Amount += 1
-		g.Board.MarkDirty(idx(grabPos))
-		b.PointerJumpBy(5)
-		b.Genome.NextArg = 5
-		return
 	case core.Depot:
 		if !depotFriendlyToBot(&v, b) {
 			if g.raidDepot(b, &v) {
@@ -2819,18 +2955,6 @@ func (g *Game) grab(pos core.Position, b *core.Bot) {
 		b.PointerJumpBy(9)
 		b.Genome.NextArg = 9
 		return
-	case *core.Depot:
-		if !depotFriendlyToBot(v, b) {
-			if g.raidDepot(b, v) {
-				g.Board.MarkDirty(idx(grabPos))
-				b.PointerJumpBy(9)
-				b.Genome.NextArg = 9
-			}
-			return
-		}
-		b.PointerJumpBy(9)
-		b.Genome.NextArg = 9
-		return
 	case core.Resource:
 		// if b.Inventory.Total() > 10 {
 		// 	b.Hp -= 10
@@ -2949,6 +3073,7 @@ func (g *Game) raidController(attacker *core.Bot, ctrl *core.Controller) bool {
 	g.recordFoodStolen(attacker, food)
 	g.recordOreStolen(attacker, ore)
 	g.recordControllerRaid(attacker)
+	g.emitEvent(EventControllerRaid, ctrl.Pos, attacker, nil, ctrl.Colony, "")
 	g.emitEventPheromone(attacker.Pos, core.PheromoneDanger)
 	g.emitEventPheromone(ctrl.Pos, core.PheromoneDanger)
 	return true
@@ -2968,6 +3093,7 @@ func (g *Game) raidDepot(attacker *core.Bot, depot *core.Depot) bool {
 	g.recordFoodStolen(attacker, food)
 	g.recordOreStolen(attacker, ore)
 	g.recordDepotRaid(attacker)
+	g.emitEvent(EventDepotRaid, depot.Pos, attacker, nil, depot.Colony, "")
 	g.emitEventPheromone(attacker.Pos, core.PheromoneDanger)
 	g.emitEventPheromone(depot.Pos, core.PheromoneDanger)
 	return true
@@ -3014,6 +3140,7 @@ func (g *Game) build(botPos core.Position, b *core.Bot) {
 			Owner: b,
 			Hp:    20,
 		})
+		g.emitEvent(EventBuild, buildPos, b, nil, nil, core.BuildWall.String())
 		g.spendShared(b, 0, c.BuildingBuildCost)
 		b.Hp += c.BuildingBuildHpGain
 		b.PointerJumpBy(1)
@@ -3034,6 +3161,7 @@ func (g *Game) build(botPos core.Position, b *core.Bot) {
 		}
 		g.Board.Set(buildPos, flag)
 		b.Colony.AddFlag(&flag)
+		g.emitEvent(EventBuild, buildPos, b, nil, nil, core.BuildColonyFlag.String())
 		g.emitHomePheromone(buildPos, b.Colony, c.PheromoneHomeDeposit/2)
 		b.PointerJumpBy(1)
 		return
@@ -3058,6 +3186,7 @@ func (g *Game) build(botPos core.Position, b *core.Bot) {
 			Colony: connectedBuilderColony(b),
 			Amount: c.SpawnerInitialAmount,
 		})
+		g.emitEvent(EventBuild, buildPos, b, nil, nil, core.BuildSpawner.String())
 		g.spendShared(b, 0, c.SpawnerBuildCost)
 		b.Evolution.SpawnerBuilds++
 		g.emitEventPheromone(buildPos, core.PheromoneFood)
@@ -3095,6 +3224,7 @@ func (g *Game) build(botPos core.Position, b *core.Bot) {
 			Amount:      c.ControllerInitialAmount,
 			WaterAmount: 0,
 		})
+		g.emitEvent(EventBuild, buildPos, b, nil, nil, core.BuildController.String())
 		g.emitHomePheromone(buildPos, colony, c.PheromoneHomeDeposit)
 		b.AssignRandomColor()
 		g.spendShared(b, 0, c.ControllerBuildCost)
@@ -3116,6 +3246,7 @@ func (g *Game) build(botPos core.Position, b *core.Bot) {
 			Owner:  b,
 			Amount: g.mineInitialAmount(buildPos),
 		})
+		g.emitEvent(EventBuild, buildPos, b, nil, nil, core.BuildMine.String())
 		g.emitEventPheromone(buildPos, core.PheromoneOre)
 		g.spendShared(b, 0, c.MineBuildCost)
 		b.Evolution.MineBuilds++
@@ -3137,6 +3268,7 @@ func (g *Game) build(botPos core.Position, b *core.Bot) {
 			Colony: connectedBuilderColony(b),
 			Amount: g.colonyFarmInitialAmount(b),
 		})
+		g.emitEvent(EventBuild, buildPos, b, nil, nil, core.BuildFarm.String())
 		g.emitEventPheromone(buildPos, core.PheromoneFood)
 		b.Hp += c.FarmBuildHpGain
 		g.spendShared(b, 0, c.FarmBuildCost)
@@ -3161,24 +3293,14 @@ func (g *Game) tryBuildDepot(buildPos core.Position, b *core.Bot) bool {
 	})
 	g.spendShared(b, 0, g.config.DepotBuildCost)
 	b.Evolution.DepotBuilds++
+	g.emitEvent(EventBuild, buildPos, b, nil, nil, core.BuildDepot.String())
 	g.emitHomePheromone(buildPos, b.Colony, g.config.PheromoneHomeDeposit/3)
 	return true
 }
 
 func (g *Game) hasControllerNear(center core.Position, radius int) bool {
-	for r := center.R - radius; r <= center.R+radius; r++ {
-		if r < 0 || r >= core.Rows {
-			continue
-		}
-		for dc := -radius; dc <= radius; dc++ {
-			pos := util.NewPos(r, center.C+dc)
-			switch g.Board.At(pos).(type) {
-			case core.Controller, *core.Controller:
-				return true
-			}
-		}
-	}
-	return false
+	_, ok := g.Board.NearestOfKind(core.CellController, center, radius, nil)
+	return ok
 }
 
 func (g *Game) controllerBuildColony(builder *core.Bot, buildPos core.Position) (*core.Colony, bool) {
@@ -3208,6 +3330,7 @@ func (g *Game) controllerBuildColony(builder *core.Bot, buildPos core.Position)
 	g.claimNearbyColonyFarms(&colony, buildPos, controllerRecruitRadius)
 	g.connectNearbyColonyMembers(&colony, buildPos, controllerRecruitRadius)
 	g.initializeColonySpawnerGenome(&colony, builder)
+	g.emitColonyEvent(EventColonyFounded, &colony, builder, "controller")
 	return &colony, true
 }
 
@@ -3253,8 +3376,8 @@ func (g *Game) mineInitialAmount(pos core.Position) int {
 
 func (g *Game) lookAround(botPos core.Position, b *core.Bot) {
 	lookPos := b.CmdArgDir(2, botPos)
-	switch v := g.Board.At(lookPos).(type) {
-	case *core.Bot:
+	switch g.Board.KindAt(lookPos) {
+	case core.CellBot:
 		other := g.Board.GetBot(lookPos)
 		if other != nil {
 			if b.IsBro(other) {
@@ -3266,39 +3389,36 @@ func (g *Game) lookAround(botPos core.Position, b *core.Bot) {
 			b.Genome.NextArg = 13
 			return
 		}
-	case core.Building:
+	case core.CellBuilding:
 		b.PointerJumpBy(4)
 		b.Genome.NextArg = 4
-	case core.Wall:
+	case core.CellWall:
 		b.PointerJumpBy(8)
 		b.Genome.NextArg = 8
-	case core.Resource:
+	case core.CellResource:
 		b.PointerJumpBy(11)
 		b.Genome.NextArg = 11
-	case core.Controller:
-		b.PointerJumpBy(50)
-		b.Genome.NextArg = 50
-	case *core.Controller:
+	case core.CellController:
 		b.PointerJumpBy(50)
 		b.Genome.NextArg = 50
-	case core.Depot, *core.Depot:
+	case core.CellDepot:
 		b.PointerJumpBy(52)
 		b.Genome.NextArg = 52
-	case core.Spawner:
+	case core.CellSpawner:
 		b.PointerJumpBy(61)
 		b.Genome.NextArg = 61
-	case core.Farm:
+	case core.CellFarm:
 		b.PointerJumpBy(7)
 		b.Genome.NextArg = 7
-	case core.Food:
+	case core.CellFood:
 		b.PointerJumpBy(9)
 		b.Genome.NextArg = 9
-	case core.Poison:
+	case core.CellPoison:
 		b.PointerJumpBy(14)
 		b.Genome.NextArg = 14
-	case core.Water:
-		if b.Colony != nil && !b.Colony.KnowsWaterGroupId(v.GroupId) {
-			b.Colony.AddWaterPosition(lookPos, v.GroupId)
+	case core.CellWater:
+		if groupID := g.Board.CellAtIdx(idx(lookPos)).Extra; b.Colony != nil && !b.Colony.KnowsWaterGroupId(groupID) {
+			b.Colony.AddWaterPosition(lookPos, groupID)
 		}
 		// if b.Colony != nil {
 		// 	marker := b.Colony.NewMarker(lookPos, bot.WaterMarker)
@@ -3306,7 +3426,7 @@ func (g *Game) lookAround(botPos core.Position, b *core.Bot) {
 		// }
 		b.Genome.NextArg = 15
 		b.PointerJumpBy(15)
-	case core.Organics:
+	case core.CellOrganics:
 		b.PointerJumpBy(3)
 		b.Genome.NextArg = 3
 	default:
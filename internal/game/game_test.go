@@ -2,17 +2,18 @@ package game
 
 import (
 	"encoding/json"
+	"fmt"
 	"golab/internal/config"
 	"golab/internal/core"
 	"golab/internal/ui"
 	"golab/internal/util"
-	"math/rand"
+	"hash/fnv"
 	"os"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
-
-	expRand "golang.org/x/exp/rand"
 )
 
 func firstBiomeCell(t *testing.T, brd *core.Board, biome core.Biome) core.Position {
@@ -1726,7 +1727,7 @@ func TestOreSpawnDistributionFavorsMineralVeins(t *testing.T) {
 	mineralVeinCells := 0
 	mineralVeinOre := 0
 	totalOre := 0
-	for idx, cell := range *g.Board.GetGrid() {
+	for idx, cell := range g.Board.Occupants() {
 		pos := util.PosOf(idx)
 		biome := g.Board.BiomeAt(pos)
 		count := counts[biome]
@@ -1792,7 +1793,7 @@ func generatedWaterSnapshot(cfg *config.Config, seed int64) waterSnapshot {
 	for i := range out.Cells {
 		out.Cells[i] = -1
 	}
-	for idx, cell := range *g.Board.GetGrid() {
+	for idx, cell := range g.Board.Occupants() {
 		water, ok := cell.(core.Water)
 		if !ok {
 			continue
@@ -1822,8 +1823,7 @@ func generatedWaterSnapshot(cfg *config.Config, seed int64) waterSnapshot {
 }
 
 func seedTerrainRNG(seed int64) {
-	rand.Seed(seed)
-	expRand.Seed(uint64(seed))
+	util.SeedRandom(seed)
 }
 
 func irregularWaterGroups(groups map[int]*waterGroupStats) int {
@@ -1963,6 +1963,22 @@ func TestFertileBiomeRegrowsFood(t *testing.T) {
 	}
 }
 
+func TestFertileBiomeRegrowthLeavesOccupiedCells(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.FertileFoodRegrowPeriod = 1
+	g := NewGame(&cfg)
+	g.Board = core.NewBoard()
+
+	fertilePos := firstBiomeCell(t, g.Board, core.BiomeFertile)
+	bot := core.NewBot(fertilePos)
+	g.Board.AddBot(fertilePos, &bot)
+	g.environmentActions()
+
+	if got := g.Board.GetBot(fertilePos); got != &bot {
+		t.Fatalf("fertile cell holding a bot = %T after regrowth, want the bot", g.Board.At(fertilePos))
+	}
+}
+
 func TestNeutralBiomeDoesNotRegrowFood(t *testing.T) {
 	cfg := config.NewConfig()
 	cfg.FertileFoodRegrowPeriod = 1
@@ -2335,7 +2351,7 @@ func TestElitePoolPreservesColonyLinkedGenomesUnderQuota(t *testing.T) {
 }
 
 func TestSmartGenerationSeedingUsesElitePercentRoundRobin(t *testing.T) {
-	rand.Seed(7)
+	util.SeedRandom(7)
 
 	cfg := config.NewConfig()
 	cfg.BotChance = 100
@@ -2388,7 +2404,7 @@ func TestSmartGenerationSeedingUsesElitePercentRoundRobin(t *testing.T) {
 }
 
 func TestSmartGenerationSeedsColonyLinkedEliteCohort(t *testing.T) {
-	rand.Seed(7)
+	util.SeedRandom(7)
 
 	cfg := config.NewConfig()
 	cfg.BotChance = 100
@@ -2472,7 +2488,7 @@ func TestLowPopulationColonyEliteImmigrantsSpawnCohortWithinBudget(t *testing.T)
 }
 
 func TestLowPopulationImmigrantsUseRandomGenomeWithoutElite(t *testing.T) {
-	rand.Seed(9)
+	util.SeedRandom(9)
 
 	cfg := config.NewConfig()
 	g := NewGame(&cfg)
@@ -3999,7 +4015,7 @@ func TestFirstControllerBuildCreatesFreeWallsGatesAndSpawners(t *testing.T) {
 	}
 
 	spawners := 0
-	for _, cell := range *g.Board.GetGrid() {
+	for _, cell := range g.Board.Occupants() {
 		spawner, ok := cell.(core.Spawner)
 		if !ok {
 			continue
@@ -4653,8 +4669,7 @@ func TestInitializeForScaleSeedsExactDeterministicTarget(t *testing.T) {
 	const target = 250
 
 	newSeeded := func() *Game {
-		rand.Seed(seed)
-		expRand.Seed(seed)
+		util.SeedRandom(seed)
 		cfg := config.NewConfig()
 		cfg.LogicStep = 0
 		g := NewGame(&cfg)
@@ -4684,6 +4699,94 @@ func TestInitializeForScaleSeedsExactDeterministicTarget(t *testing.T) {
 	}
 }
 
+func TestParallelBotsActionsDoNotDependOnWorkerCount(t *testing.T) {
+	run := func(workers int) []parallelBotState {
+		util.SeedRandom(7)
+		cfg := config.NewConfig()
+		cfg.LogicStep = 0
+		g := NewGame(&cfg)
+		g.SetBotWorkers(workers)
+		g.InitializeForCommands()
+		g.RunHeadlessFrames(40)
+		assertBotRegistryConsistent(t, g.Board)
+		return parallelBotStates(g.Board)
+	}
+
+	want := run(2)
+	if len(want) == 0 {
+		t.Fatal("no bots left after 40 ticks")
+	}
+	for _, workers := range []int{3, 8} {
+		if got := run(workers); !slices.Equal(got, want) {
+			t.Fatalf("bots after 40 ticks with %d workers differ from 2 workers", workers)
+		}
+	}
+}
+
+func TestParallelBotsActionsStepMostBlankGenomesInStripes(t *testing.T) {
+	util.SeedRandom(42)
+	cfg := config.NewConfig()
+	cfg.LogicStep = 0
+	g := NewGame(&cfg)
+	g.SetBotWorkers(4)
+	if err := g.InitializeForScale(2000, 42); err != nil {
+		t.Fatalf("InitializeForScale: %v", err)
+	}
+	before := activeBotCells(g.Board)
+
+	g.botsActions()
+
+	// Blank genomes only move, so only bots stepping onto food or ore, whose
+	// pickup updates shared counters, fall back to the serial pass.
+	deferred := 0
+	for k := range g.botStripes {
+		deferred += len(g.botStripes[k].deferred)
+	}
+	if deferred > 2000/20 {
+		t.Fatalf("deferred %d of 2000 blank-genome bots to the serial pass", deferred)
+	}
+	if got := g.Board.ActiveBotCount(); got != 2000 {
+		t.Fatalf("active bot count = %d, want 2000", got)
+	}
+	for _, id := range g.Board.ActiveBotIDs() {
+		if age := g.Board.BotByID(id).Age; age != 1 {
+			t.Fatalf("bot %d age = %d, want 1", id, age)
+		}
+	}
+	if slices.Equal(activeBotCells(g.Board), before) {
+		t.Fatal("no bot moved")
+	}
+	assertBotRegistryConsistent(t, g.Board)
+}
+
+type parallelBotState struct {
+	cell, hp, age, pointer int
+}
+
+func parallelBotStates(brd *core.Board) []parallelBotState {
+	var states []parallelBotState
+	for i := range util.Cells {
+		if b := brd.Bots[i]; b != nil {
+			states = append(states, parallelBotState{cell: i, hp: b.Hp, age: b.Age, pointer: b.Genome.Pointer})
+		}
+	}
+	return states
+}
+
+func assertBotRegistryConsistent(t *testing.T, brd *core.Board) {
+	t.Helper()
+	for _, id := range brd.ActiveBotIDs() {
+		cell := brd.BotCell(id)
+		b := brd.BotByID(id)
+		if cell < 0 || b == nil || brd.Bots[cell] != b || b.Pos != util.PosOf(cell) || brd.KindAtIdx(cell) != core.CellBot {
+			t.Fatalf("bot %d registered at cell %d is not on the board there", id, cell)
+		}
+	}
+	if got, want := slices.Collect(brd.CellsOfKind(core.CellBot)), activeBotCells(brd); !slices.Equal(got, want) {
+		t.Fatalf("spatial index holds %d bot cells, registry %d", len(got), len(want))
+	}
+}
+
 func activeBotCells(brd *core.Board) []int {
 	cells := make([]int, 0, brd.ActiveBotCount())
 	for _, id := range brd.ActiveBotIDs() {
@@ -4750,6 +4853,18 @@ func BenchmarkBotsActions100k(b *testing.B) {
 	benchmarkLiveBots = g.Board.ActiveBotCount()
 }
 
+func BenchmarkBotsActions100kParallel(b *testing.B) {
+	g := newScaleBenchmarkGame(b, 100000)
+	g.SetBotWorkers(max(2, runtime.GOMAXPROCS(0)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		g.botsActions()
+	}
+	benchmarkLiveBots = g.Board.ActiveBotCount()
+}
+
 func BenchmarkScaleTest100k(b *testing.B) {
 	for range b.N {
 		g := newScaleBenchmarkGame(b, 100000)
@@ -4761,8 +4876,7 @@ func BenchmarkScaleTest100k(b *testing.B) {
 func newScaleBenchmarkGame(tb testing.TB, target int) *Game {
 	tb.Helper()
 	const seed = 42
-	rand.Seed(seed)
-	expRand.Seed(seed)
+	util.SeedRandom(seed)
 	cfg := config.NewConfig()
 	cfg.LogicStep = 0
 	cfg.NewGenThreshold = 0
@@ -4774,3 +4888,407 @@ func newScaleBenchmarkGame(tb testing.TB, target int) *Game {
 	}
 	return g
 }
+
+func TestMetricsExporterStreamsIntervalDeltas(t *testing.T) {
+	util.SeedRandom(7)
+	cfg := config.NewConfig()
+	cfg.LogicStep = 0
+	g := NewGame(&cfg)
+	g.InitializeForCommands()
+
+	var out strings.Builder
+	exporter, err := NewMetricsExporter(&out, MetricsCSV, 5)
+	if err != nil {
+		t.Fatalf("NewMetricsExporter() error = %v", err)
+	}
+	g.EnableMetrics(exporter, 7)
+	g.RunHeadlessFrames(20)
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("metrics lines = %d, want header plus 4 rows:\n%s", len(lines), out.String())
+	}
+	header := strings.Split(lines[0], ",")
+	if header[0] != "seed" || header[1] != "tick" || header[len(header)-1] != "tps" {
+		t.Fatalf("metrics header = %v, want seed,tick,...,tps", header)
+	}
+	births, deaths := 0, 0
+	birthsCol, deathsCol := slices.Index(header, "births"), slices.Index(header, "deaths")
+	for i, line := range lines[1:] {
+		fields := strings.Split(line, ",")
+		if want := strconv.Itoa((i + 1) * 5); fields[1] != want {
+			t.Fatalf("row %d tick = %s, want %s", i, fields[1], want)
+		}
+		n, _ := strconv.Atoi(fields[birthsCol])
+		births += n
+		n, _ = strconv.Atoi(fields[deathsCol])
+		deaths += n
+	}
+	if births != g.SuccessfulDivisions() || deaths != g.Deaths() {
+		t.Fatalf("summed births/deaths = %d/%d, want %d/%d", births, deaths, g.SuccessfulDivisions(), g.Deaths())
+	}
+}
+
+func TestParseMetricsFormatFallsBackToExtension(t *testing.T) {
+	cases := []struct {
+		name, path string
+		want       MetricsFormat
+	}{
+		{"", "run.csv", MetricsCSV},
+		{"", "run.ndjson", MetricsNDJSON},
+		{"csv", "run.jsonl", MetricsCSV},
+		{"jsonl", "run.csv", MetricsNDJSON},
+	}
+	for _, tc := range cases {
+		got, err := ParseMetricsFormat(tc.name, tc.path)
+		if err != nil || got != tc.want {
+			t.Fatalf("ParseMetricsFormat(%q, %q) = %q, %v; want %q", tc.name, tc.path, got, err, tc.want)
+		}
+	}
+	if _, err := ParseMetricsFormat("xml", "run.csv"); err == nil {
+		t.Fatalf("ParseMetricsFormat(xml) error = nil, want error")
+	}
+}
+
+func TestEventBusReconcilesWithAggregateCounters(t *testing.T) {
+	util.SeedRandom(11)
+	cfg := config.NewConfig()
+	cfg.LogicStep = 0
+	g := NewGame(&cfg)
+	g.InitializeForCommands()
+
+	var recorder EventRecorder
+	g.Events().Subscribe(recorder.Record)
+	g.RunHeadlessFrames(200)
+
+	if got, want := recorder.Count(EventBirth), g.SuccessfulDivisions(); got != want {
+		t.Fatalf("birth events = %d, want %d", got, want)
+	}
+	if got, want := recorder.Count(EventDeath), g.Deaths(); got != want {
+		t.Fatalf("death events = %d, want %d", got, want)
+	}
+	if got, want := recorder.Count(EventKill), g.CombatKills(); got != want {
+		t.Fatalf("kill events = %d, want %d", got, want)
+	}
+	if got, want := recorder.Count(EventControllerRaid), g.ControllerRaids(); got != want {
+		t.Fatalf("controller raid events = %d, want %d", got, want)
+	}
+	immigrants := 0
+	for i, event := range recorder.Events {
+		if i > 0 && event.Tick < recorder.Events[i-1].Tick {
+			t.Fatalf("event %d tick %d precedes previous tick %d", i, event.Tick, recorder.Events[i-1].Tick)
+		}
+		if event.Kind == EventImmigration {
+			immigrants += event.Count
+		}
+		if event.Kind == EventBirth && event.Bot == core.NoBotID {
+			t.Fatalf("birth event %+v has no bot ID", event)
+		}
+	}
+	if immigrants != g.Immigrants() {
+		t.Fatalf("immigration event counts = %d, want %d", immigrants, g.Immigrants())
+	}
+}
+
+func TestKillEmitsKillThenDeathWithBothBotIDs(t *testing.T) {
+	cfg := config.NewConfig()
+	g := NewGame(&cfg)
+	g.Board = core.NewBoard()
+	var recorder EventRecorder
+	g.Events().Subscribe(recorder.Record)
+
+	attackerPos := util.NewPos(10, 10)
+	victimPos := util.NewPos(10, 11)
+	attacker := core.NewBot(attackerPos)
+	victim := core.NewBot(victimPos)
+	g.Board.AddBot(attackerPos, &attacker)
+	g.Board.AddBot(victimPos, &victim)
+	attackerID := g.Board.BotIDOf(&attacker)
+	victimID := g.Board.BotIDOf(&victim)
+
+	g.recordCombatKill(&attacker)
+	g.emitEvent(EventKill, victimPos, &attacker, &victim, nil, "")
+	g.killBot(&victim, util.Idx(victimPos), core.DeathCombat)
+
+	if len(recorder.Events) != 2 {
+		t.Fatalf("events = %+v, want kill and death", recorder.Events)
+	}
+	kill, death := recorder.Events[0], recorder.Events[1]
+	if kill.Kind != EventKill || kill.Bot != attackerID || kill.Other != victimID {
+		t.Fatalf("kill event = %+v, want attacker %d victim %d", kill, attackerID, victimID)
+	}
+	if death.Kind != EventDeath || death.Bot != victimID || death.Row != 10 || death.Col != 11 {
+		t.Fatalf("death event = %+v, want victim %d at 10,11", death, victimID)
+	}
+}
+
+func TestPoisonGrabKillsThroughKillBotWithPoisonCause(t *testing.T) {
+	cfg := config.NewConfig()
+	g := NewGame(&cfg)
+	g.Board = core.NewBoard()
+
+	botPos := util.NewPos(30, 30)
+	poisonPos := botPos.AddRowCol(0, 1)
+	bot := core.NewBot(botPos)
+	bot.Dir = util.Direction{0, 1}
+	colony := core.NewColony(botPos)
+	colony.AddFamily(&bot)
+	addTestBot(g, &bot)
+	g.Board.Set(poisonPos, core.Poison{Pos: poisonPos})
+
+	g.grab(botPos, &bot)
+
+	if g.Board.GetBot(botPos) != nil {
+		t.Fatalf("poisoned bot still on board")
+	}
+	if len(colony.Members) != 0 {
+		t.Fatalf("colony members = %d, want poisoned bot removed", len(colony.Members))
+	}
+	if got := g.DeathsByCause()[core.DeathPoison]; got != 1 || g.Deaths() != 1 {
+		t.Fatalf("poison deaths = %d of %d, want 1 of 1", got, g.Deaths())
+	}
+	if got := g.ColonyDeathsByCause()[&colony][core.DeathPoison]; got != 1 {
+		t.Fatalf("colony poison deaths = %d, want 1", got)
+	}
+	if got := g.Board.DeathAt(botPos); got != core.DeathPoison {
+		t.Fatalf("death map at bot cell = %s, want poison", got)
+	}
+}
+
+func TestHeatmapsSumToEventCountersAndSurviveReset(t *testing.T) {
+	util.SeedRandom(11)
+	cfg := config.NewConfig()
+	cfg.LogicStep = 0
+	g := NewGame(&cfg)
+	g.InitializeForCommands()
+	g.EnableHeatmaps()
+	g.RunHeadlessFrames(150)
+
+	sum := func(layer core.HeatLayer) int {
+		total := 0
+		for cellIdx := range util.Cells {
+			total += int(g.Board.HeatAt(layer, util.PosOf(cellIdx)))
+		}
+		return total
+	}
+	if got, want := sum(core.HeatDeaths), g.Deaths(); got != want {
+		t.Fatalf("death heat = %d, want %d", got, want)
+	}
+	if got, want := sum(core.HeatBirths), g.SuccessfulDivisions(); got != want {
+		t.Fatalf("birth heat = %d, want %d", got, want)
+	}
+	if got, want := sum(core.HeatKills), g.CombatKills(); got != want {
+		t.Fatalf("kill heat = %d, want %d", got, want)
+	}
+	if got, want := sum(core.HeatRaids), g.ControllerRaids()+g.DepotRaids(); got != want {
+		t.Fatalf("raid heat = %d, want %d", got, want)
+	}
+	if sum(core.HeatOccupancy) == 0 {
+		t.Fatal("occupancy heat stayed empty")
+	}
+
+	g.ResetSimulation()
+	if !g.Board.HeatEnabled() || sum(core.HeatOccupancy) != 0 {
+		t.Fatalf("reset board heat enabled=%v occupancy=%d, want fresh heatmaps", g.Board.HeatEnabled(), sum(core.HeatOccupancy))
+	}
+}
+
+func TestRewindRestoresSnapshotAndReplaysTheSameTicks(t *testing.T) {
+	util.SeedRandom(5)
+	cfg := config.NewConfig()
+	cfg.LogicStep = 0
+	g := NewGame(&cfg)
+	g.InitializeForCommands()
+	g.ApplyGodTool(ui.GodToolColony, core.Position{R: 200, C: 300}, 0)
+	g.ApplyGodTool(ui.GodToolFreeze, core.Position{R: 100, C: 100}, 3)
+	g.EnableRewind(10, 3, 99)
+
+	fingerprint := func() string {
+		h := fnv.New64a()
+		for i, cell := range g.Board.Occupants() {
+			if cell == nil {
+				continue
+			}
+			fmt.Fprintf(h, "%d:%T;", i, cell)
+			if bot, ok := cell.(*core.Bot); ok {
+				fmt.Fprintf(h, "%d/%d/%v/%d/%d;", g.Board.BotIDOf(bot), bot.Hp, bot.Inventory, bot.Age, bot.Genome.Pointer)
+			}
+		}
+		members := 0
+		for _, colony := range g.Colonies {
+			members += len(colony.Members)
+		}
+		return fmt.Sprintf("%x tick=%d live=%d births=%d deaths=%d colonies=%d members=%d frozen=%v scent=%+v",
+			h.Sum64(), g.logicTick, g.liveBotCount(), g.SuccessfulDivisions(), g.Deaths(), len(g.Colonies), members,
+			g.Board.IsFrozen(core.Position{R: 100, C: 100}), g.Board.PheromoneTotals())
+	}
+
+	g.RunHeadlessFrames(20)
+	atTwenty := fingerprint()
+	g.RunHeadlessFrames(20)
+	atForty := fingerprint()
+	if got := g.RewindTicks(); !slices.Equal(got, []int{10, 20, 30}) {
+		t.Fatalf("retained ticks = %v, want the last three snapshots", got)
+	}
+
+	tick, ok := g.RewindTo(25)
+	if !ok || tick != 20 {
+		t.Fatalf("RewindTo(25) = %d %v, want the snapshot at tick 20", tick, ok)
+	}
+	if got := g.RewindTicks(); !slices.Equal(got, []int{10, 20}) {
+		t.Fatalf("ticks after rewind = %v, want later snapshots dropped", got)
+	}
+	if got := fingerprint(); got != atTwenty {
+		t.Fatalf("restored state differs from tick 20:\n got %s\nwant %s", got, atTwenty)
+	}
+	g.RunHeadlessFrames(20)
+	if got := fingerprint(); got != atForty {
+		t.Fatalf("replayed state differs from the first run:\n got %s\nwant %s", got, atForty)
+	}
+
+	// The snapshot survives being restored, so it can be rewound to again.
+	if tick, ok := g.RewindTo(20); !ok || tick != 20 || fingerprint() != atTwenty {
+		t.Fatalf("second rewind to tick 20 = %d %v, state %s", tick, ok, fingerprint())
+	}
+	if _, ok := g.RewindTo(5); ok {
+		t.Fatal("RewindTo before the oldest snapshot succeeded")
+	}
+}
+
+func TestInvariantChecksPassForASeedAndReportLostAndReusedBots(t *testing.T) {
+	util.SeedRandom(11)
+	cfg := config.NewConfig()
+	cfg.LogicStep = 0
+	g := NewGame(&cfg)
+	g.InitializeForCommands()
+	var failures []*InvariantError
+	g.EnableInvariantChecks(1, func(err *InvariantError) {
+		failures = append(failures, err)
+	})
+	g.RunHeadlessFrames(60)
+	if len(failures) > 0 {
+		t.Fatalf("seeded run broke invariants: %v", failures[0])
+	}
+
+	// Dropping a bot from the board without killBot leaves a live bot behind.
+	var lost *core.Bot
+	for _, id := range g.Board.ActiveBotIDs() {
+		if b := g.Board.BotByID(id); b.Colony == nil {
+			lost = b
+			break
+		}
+	}
+	if lost == nil {
+		t.Fatal("no colonyless bot to drop")
+	}
+	pos := lost.Pos
+	g.Board.RemoveBotAt(pos)
+	err := g.CheckInvariants()
+	if err == nil || !strings.Contains(err.Error(), "left the board") || !strings.Contains(err.Error(), fmt.Sprint(pos)) {
+		t.Fatalf("CheckInvariants after dropping a bot = %v, want it reported at %v", err, pos)
+	}
+
+	// Putting the same memory back as a new bot is the pool-reuse hazard.
+	*lost = core.NewBot(pos)
+	g.Board.AddBot(pos, lost)
+	if err := g.CheckInvariants(); err == nil || !strings.Contains(err.Error(), "memory was reused") {
+		t.Fatalf("CheckInvariants after reusing a retired bot = %v, want a reuse report", err)
+	}
+	if err := g.CheckInvariants(); err != nil {
+		t.Fatalf("reuse is reported once, then %v", err)
+	}
+}
+
+func TestStateHashRepeatsForASeedAndNamesDivergedComponents(t *testing.T) {
+	run := func() *Game {
+		util.SeedRandom(9)
+		cfg := config.NewConfig()
+		cfg.LogicStep = 0
+		g := NewGame(&cfg)
+		g.InitializeForCommands()
+		g.EnableRewind(10, 2, 99)
+		g.RunHeadlessFrames(30)
+		return g
+	}
+	first, second := run(), run()
+	hash := first.StateHash()
+	if got := second.StateHash(); got != hash {
+		t.Fatalf("same-seed state hash = %+v, want %+v", got, hash)
+	}
+
+	first.RunHeadlessFrames(5)
+	if tick, ok := first.RewindTo(30); !ok || tick != 30 {
+		t.Fatalf("RewindTo(30) = %d %v, want the snapshot at tick 30", tick, ok)
+	}
+	if got := first.StateHash(); got != hash {
+		t.Fatalf("state hash after rewind = %+v, want %+v", got, hash)
+	}
+
+	first.Board.DepositPheromone(core.Position{R: 1, C: 1}, core.PheromoneDanger, 3, nil)
+	if got := first.StateHash().Diverged(hash); !slices.Equal(got, []string{"pheromones"}) {
+		t.Fatalf("diverged after a scent deposit = %v, want [pheromones]", got)
+	}
+	if len(first.Colonies) > 0 {
+		first.Colonies[0].FoodBank++
+		if got := first.StateHash().Diverged(hash); !slices.Equal(got, []string{"pheromones", "colonies"}) {
+			t.Fatalf("diverged after a bank change = %v, want [pheromones colonies]", got)
+		}
+	}
+}
+
+func TestPhaseTimersCountTicksAndPublishWithoutChangingTheRun(t *testing.T) {
+	run := func(timed bool) *Game {
+		util.SeedRandom(4)
+		cfg := config.NewConfig()
+		cfg.LogicStep = 0
+		g := NewGame(&cfg)
+		g.InitializeForCommands()
+		if timed {
+			g.EnablePhaseTimers(true)
+		} else {
+			g.DisablePhaseTimers()
+		}
+		g.RunHeadlessFrames(20)
+		return g
+	}
+	if _, ok := NewGame(&config.Config{}).PhaseTimes(); !ok {
+		t.Fatalf("new game has no phase timers")
+	}
+	plain, timed := run(false), run(true)
+	if _, ok := plain.PhaseTimes(); ok {
+		t.Fatalf("phase timers on after DisablePhaseTimers")
+	}
+	if got, want := timed.StateHash(), plain.StateHash(); got != want {
+		t.Fatalf("timed run state hash = %+v, want %+v", got, want)
+	}
+
+	times, ok := timed.PhaseTimes()
+	if !ok || times.Ticks != 20 {
+		t.Fatalf("phase times = %+v %v, want 20 ticks", times, ok)
+	}
+	if times.Nanos[PhaseBots] <= 0 || times.Nanos[PhaseChampionScan] <= 0 {
+		t.Fatalf("phase nanos = %v, want bots and champion scan timed", times.Nanos)
+	}
+	if nanos, _, _ := times.Total(); nanos < times.Nanos[PhaseBots] {
+		t.Fatalf("total %d below the bots phase %d", nanos, times.Nanos[PhaseBots])
+	}
+
+	if got := timed.State.TickPhases; len(got) != int(NumTickPhases) || got[PhaseBots].Name != "bots" || got[PhaseBots].Millis <= 0 {
+		t.Fatalf("published tick phases = %+v, want every phase with bots timed", got)
+	}
+	if got := timed.State.SlowestTickPhases(2); len(got) != 2 || got[0].Millis < got[1].Millis {
+		t.Fatalf("slowest phases = %+v, want two, slowest first", got)
+	}
+
+	timed.ResetPhaseTimes()
+	timed.RunHeadlessFrames(1)
+	if times, _ := timed.PhaseTimes(); times.Ticks != 1 {
+		t.Fatalf("ticks after reset = %d, want 1", times.Ticks)
+	}
+	if got := TickPhase(NumTickPhases).String(); got != "unknown" {
+		t.Fatalf("out-of-range phase = %q", got)
+	}
+}
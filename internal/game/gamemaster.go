@@ -3,7 +3,6 @@ package game
 import (
 	"golab/internal/core"
 	"golab/internal/util"
-	"math/rand"
 )
 
 const mockGameMasterName = "mock-coolio"
@@ -160,20 +159,11 @@ func (g *Game) ObserveMaster(tick int) MasterObservation {
 			colonies[colony] = struct{}{}
 		}
 	}
-	for _, cell := range *g.Board.GetGrid() {
-		switch v := cell.(type) {
-		case core.Controller:
-			if v.Colony != nil {
-				colonies[v.Colony] = struct{}{}
-				activeColonies[v.Colony] = struct{}{}
-				obs.Controllers++
-			}
-		case *core.Controller:
-			if v.Colony != nil {
-				colonies[v.Colony] = struct{}{}
-				activeColonies[v.Colony] = struct{}{}
-				obs.Controllers++
-			}
+	for i := range g.Board.CellsOfKind(core.CellController) {
+		if colony := g.Board.CellAtIdx(i).Colony; colony != nil {
+			colonies[colony] = struct{}{}
+			activeColonies[colony] = struct{}{}
+			obs.Controllers++
 		}
 	}
 	for _, id := range g.sortedActiveBotIDs(nil) {
@@ -200,21 +190,19 @@ func (g *Game) ObserveMaster(tick int) MasterObservation {
 			obs.ConnectedColonyBots++
 		}
 	}
-	for _, cell := range *g.Board.GetGrid() {
-		switch cell.(type) {
-		case core.Controller:
-		case *core.Controller:
-		case core.Resource:
+	for _, kind := range g.Board.Kinds() {
+		switch kind {
+		case core.CellResource:
 			obs.Resources++
-		case core.Food:
+		case core.CellFood:
 			obs.Food++
-		case core.Poison:
+		case core.CellPoison:
 			obs.Poison++
-		case core.Organics:
+		case core.CellOrganics:
 			obs.Organics++
-		case core.Water:
+		case core.CellWater:
 			obs.Water++
-		case core.Wall:
+		case core.CellWall:
 			obs.Wall++
 		}
 	}
@@ -249,7 +237,7 @@ func (g *Game) ApplyMasterEvent(event MasterEvent) MasterEvent {
 			if !g.canMasterReplaceSoftCell(pos) {
 				return false
 			}
-			g.Board.Set(pos, core.Resource{Pos: pos, Amount: 1 + rand.Intn(3)})
+			g.Board.Set(pos, core.Resource{Pos: pos, Amount: 1 + util.RandIntn(3)})
 			g.emitEventPheromone(pos, core.PheromoneOre)
 			return true
 		})
@@ -441,6 +429,9 @@ func (g *Game) seedSettlementController(event MasterEvent) int {
 		g.Colonies = append(g.Colonies, colony)
 	}
 	colony.AddFamily(g.liveLineageRoot(founder))
+	if newColony {
+		g.emitColonyEvent(EventColonyFounded, colony, founder, "game_master")
+	}
 	colony.Deposit(4, 1)
 	g.recruitFriendlyBots(colony, ctrlPos, controllerRecruitRadius)
 	g.claimNearbyColonyFarms(colony, ctrlPos, controllerRecruitRadius)
@@ -527,17 +518,10 @@ func (g *Game) liveBotPositions() []util.Position {
 
 func (g *Game) controllerSupportTargets() []controllerSupportTarget {
 	targets := []controllerSupportTarget{}
-	for idx, cell := range *g.Board.GetGrid() {
-		pos := util.PosOf(idx)
-		switch ctrl := cell.(type) {
-		case core.Controller:
-			if ctrl.Colony != nil && g.controllerOwnerAlive(&ctrl) {
-				targets = append(targets, controllerSupportTarget{pos: pos, ctrl: ctrl})
-			}
-		case *core.Controller:
-			if ctrl != nil && ctrl.Colony != nil && g.controllerOwnerAlive(ctrl) {
-				targets = append(targets, controllerSupportTarget{pos: pos, ctrl: *ctrl})
-			}
+	for idx := range g.Board.CellsOfKind(core.CellController) {
+		ctrl := g.Board.AtIdx(idx).(core.Controller)
+		if ctrl.Colony != nil && g.controllerOwnerAlive(&ctrl) {
+			targets = append(targets, controllerSupportTarget{pos: util.PosOf(idx), ctrl: ctrl})
 		}
 	}
 	return targets
@@ -576,8 +560,8 @@ func (g *Game) canMasterReplaceSoftCell(pos util.Position) bool {
 
 func randomMasterPosition() MasterPosition {
 	return MasterPosition{
-		Row: 1 + rand.Intn(core.Rows-2),
-		Col: rand.Intn(core.Cols),
+		Row: 1 + util.RandIntn(core.Rows-2),
+		Col: util.RandIntn(core.Cols),
 	}
 }
 
@@ -585,8 +569,8 @@ func randomPositionNear(center util.Position, radius int) util.Position {
 	if radius <= 0 {
 		return center
 	}
-	dr := rand.Intn(radius*2+1) - radius
-	dc := rand.Intn(radius*2+1) - radius
+	dr := util.RandIntn(radius*2+1) - radius
+	dc := util.RandIntn(radius*2+1) - radius
 	return util.NewPos(center.R+dr, center.C+dc)
 }
 
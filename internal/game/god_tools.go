@@ -81,6 +81,11 @@ func (g *Game) SelectedColonyLabel() string {
 	return fmt.Sprintf("R%d C%d M%d", g.selectedColony.Center.R, g.selectedColony.Center.C, g.liveSelectedColonyMembers())
 }
 
+// SelectedColony returns the colony picked with the colony tool, if any.
+func (g *Game) SelectedColony() *core.Colony {
+	return g.selectedColony
+}
+
 func (g *Game) applyGodBrush(center util.Position, radius int, apply func(util.Position) bool) int {
 	applied := 0
 	for r := center.R - radius; r <= center.R+radius; r++ {
@@ -147,6 +152,7 @@ func (g *Game) spawnGodColony(pos util.Position) ui.GodReport {
 	g.initializeColonySpawnerGenome(&colony, &bot)
 	g.buildInitialColonyInfrastructure(controllerPos, &bot, &colony)
 	g.Colonies = append(g.Colonies, &colony)
+	g.emitColonyEvent(EventColonyFounded, &colony, &bot, "god")
 	g.selectColony(&colony)
 	g.config.LiveBots = g.liveBotCount()
 
@@ -223,20 +229,6 @@ func (g *Game) inspectGodCell(pos util.Position) ui.GodReport {
 				pheromoneInspectLine(g.Board, pos),
 			},
 		}
-	case *core.Controller:
-		if v.Colony != nil {
-			g.selectColony(v.Colony)
-		}
-		return ui.GodReport{
-			Message: fmt.Sprintf("Inspected controller R%d C%d", pos.R, pos.C),
-			Lines: []string{
-				fmt.Sprintf("Controller amount %d", v.Amount),
-				fmt.Sprintf("Water %d", v.WaterAmount),
-				colonyBankLine(v.Colony),
-				fmt.Sprintf("Colony %s", g.SelectedColonyLabel()),
-				pheromoneInspectLine(g.Board, pos),
-			},
-		}
 	case core.Depot:
 		if v.Colony != nil {
 			g.selectColony(v.Colony)
@@ -250,29 +242,6 @@ func (g *Game) inspectGodCell(pos util.Position) ui.GodReport {
 				pheromoneInspectLine(g.Board, pos),
 			},
 		}
-	case *core.Depot:
-		if v == nil {
-			return ui.GodReport{
-				Message: fmt.Sprintf("Inspected cell R%d C%d", pos.R, pos.C),
-				Lines: []string{
-					fmt.Sprintf("Cell %T", g.Board.At(pos)),
-					fmt.Sprintf("Frozen %v", g.Board.IsFrozen(pos)),
-					pheromoneInspectLine(g.Board, pos),
-				},
-			}
-		}
-		if v.Colony != nil {
-			g.selectColony(v.Colony)
-		}
-		return ui.GodReport{
-			Message: fmt.Sprintf("Inspected depot R%d C%d", pos.R, pos.C),
-			Lines: []string{
-				fmt.Sprintf("Depot F %d O %d", v.Food, v.Ore),
-				fmt.Sprintf("Owner %v", v.Owner != nil),
-				fmt.Sprintf("Colony %s", g.SelectedColonyLabel()),
-				pheromoneInspectLine(g.Board, pos),
-			},
-		}
 	default:
 		return ui.GodReport{
 			Message: fmt.Sprintf("Inspected cell R%d C%d", pos.R, pos.C),
@@ -300,46 +269,34 @@ func (g *Game) colonyAt(pos util.Position) *core.Colony {
 	switch v := g.Board.At(pos).(type) {
 	case core.Controller:
 		return v.Colony
-	case *core.Controller:
-		return v.Colony
 	case core.Depot:
 		return v.Colony
-	case *core.Depot:
-		if v == nil {
-			return nil
-		}
-		return v.Colony
 	}
-	for r := pos.R - 4; r <= pos.R+4; r++ {
-		if r < 0 || r >= core.Rows {
+	var colony *core.Colony
+	best, bestDist := 0, 0
+	for _, kind := range []core.CellKind{core.CellBot, core.CellController, core.CellDepot} {
+		i, ok := g.Board.NearestOfKind(kind, pos, 4, func(i int) bool {
+			return g.colonyOfCell(i) != nil
+		})
+		if !ok {
 			continue
 		}
-		for dc := -4; dc <= 4; dc++ {
-			near := util.NewPos(r, pos.C+dc)
-			if bot := g.Board.GetBot(near); bot != nil && bot.Colony != nil {
-				return bot.Colony
-			}
-			switch v := g.Board.At(near).(type) {
-			case core.Controller:
-				if v.Colony != nil {
-					return v.Colony
-				}
-			case *core.Controller:
-				if v.Colony != nil {
-					return v.Colony
-				}
-			case core.Depot:
-				if v.Colony != nil {
-					return v.Colony
-				}
-			case *core.Depot:
-				if v != nil && v.Colony != nil {
-					return v.Colony
-				}
-			}
+		dist := boardDistance(pos, util.PosOf(i))
+		if colony == nil || dist < bestDist || (dist == bestDist && i < best) {
+			colony, best, bestDist = g.colonyOfCell(i), i, dist
+		}
+	}
+	return colony
+}
+
+func (g *Game) colonyOfCell(i int) *core.Colony {
+	if g.Board.KindAtIdx(i) == core.CellBot {
+		if bot := g.Board.GetBot(util.PosOf(i)); bot != nil {
+			return bot.Colony
 		}
+		return nil
 	}
-	return nil
+	return g.Board.CellAtIdx(i).Colony
 }
 
 func (g *Game) selectColony(colony *core.Colony) {
@@ -378,10 +335,6 @@ func (g *Game) blessColony(colony *core.Colony, radius int) int {
 			ctrl.Amount += 250
 			g.Board.Set(pos, ctrl)
 			applied++
-		case *core.Controller:
-			ctrl.Amount += 250
-			g.Board.MarkDirty(util.Idx(pos))
-			applied++
 		}
 	}
 	applied += g.applyGodBrush(colony.Center, min(radius+2, 8), func(p util.Position) bool {
@@ -403,6 +356,7 @@ func (g *Game) curseColony(colony *core.Colony, radius int) int {
 			continue
 		}
 		bot.Hp = max(1, bot.Hp-120)
+		bot.Harm(core.DeathCurse)
 		bot.Inventory.Clear()
 		bot.Color = blendColor(bot.Color, util.RedColor(), 0.45)
 		g.Board.MarkDirty(util.Idx(bot.Pos))
@@ -414,10 +368,6 @@ func (g *Game) curseColony(colony *core.Colony, radius int) int {
 			ctrl.Amount = max(0, ctrl.Amount-250)
 			g.Board.Set(pos, ctrl)
 			applied++
-		case *core.Controller:
-			ctrl.Amount = max(0, ctrl.Amount-250)
-			g.Board.MarkDirty(util.Idx(pos))
-			applied++
 		}
 	}
 	applied += g.applyGodBrush(colony.Center, min(radius+2, 8), func(p util.Position) bool {
@@ -434,17 +384,9 @@ func (g *Game) curseColony(colony *core.Colony, radius int) int {
 
 func (g *Game) controllerPositions(colony *core.Colony) []util.Position {
 	positions := []util.Position{}
-	for i, cell := range *g.Board.GetGrid() {
-		pos := util.PosOf(i)
-		switch ctrl := cell.(type) {
-		case core.Controller:
-			if ctrl.Colony == colony {
-				positions = append(positions, pos)
-			}
-		case *core.Controller:
-			if ctrl.Colony == colony {
-				positions = append(positions, pos)
-			}
+	for i := range g.Board.CellsOfKind(core.CellController) {
+		if g.Board.CellAtIdx(i).Colony == colony {
+			positions = append(positions, util.PosOf(i))
 		}
 	}
 	return positions
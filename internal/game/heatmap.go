@@ -0,0 +1,35 @@
+package game
+
+import (
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+// EnableHeatmaps collects the board's cumulative heatmaps from now on: event
+// layers through the event bus and occupancy and pheromone exposure once per
+// logic tick. It survives ResetSimulation.
+func (g *Game) EnableHeatmaps() {
+	if g.heatmaps {
+		return
+	}
+	g.heatmaps = true
+	g.Board.EnableHeat()
+	g.events.Subscribe(g.recordHeat)
+}
+
+func (g *Game) recordHeat(event Event) {
+	var layer core.HeatLayer
+	switch event.Kind {
+	case EventBirth:
+		layer = core.HeatBirths
+	case EventDeath:
+		layer = core.HeatDeaths
+	case EventKill:
+		layer = core.HeatKills
+	case EventControllerRaid, EventDepotRaid:
+		layer = core.HeatRaids
+	default:
+		return
+	}
+	g.Board.AddHeat(layer, util.Idx(core.Position{R: event.Row, C: event.Col}), 1)
+}
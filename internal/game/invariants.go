@@ -0,0 +1,174 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+// InvariantError reports every invariant a check found broken after a tick.
+type InvariantError struct {
+	Tick     int
+	Problems []string
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("invariants broken after tick %d:\n  %s", e.Tick, strings.Join(e.Problems, "\n  "))
+}
+
+// invariantChecker carries what a check needs from the previous one: which
+// bots were on the board, and which have left it since, so a bot that leaves
+// without dying or a dead bot's memory coming back can be caught.
+type invariantChecker struct {
+	every int
+	fail  func(*InvariantError)
+	board *core.Board
+	live  map[*core.Bot]struct{}
+	// retired maps each bot that left the board to the tick it was last
+	// seen. It only grows, which is fine for debugging runs.
+	retired map[*core.Bot]int
+}
+
+// EnableInvariantChecks runs CheckInvariants after every n logic ticks and
+// passes a failure to fail, or panics with it when fail is nil. n <= 0 turns
+// the checks off.
+func (g *Game) EnableInvariantChecks(n int, fail func(*InvariantError)) {
+	if n <= 0 {
+		g.invariants = nil
+		return
+	}
+	g.invariants = &invariantChecker{every: n, fail: fail}
+}
+
+func (g *Game) checkInvariantsForTick() {
+	c := g.invariants
+	if c == nil || g.logicTick%c.every != 0 {
+		return
+	}
+	if err := g.CheckInvariants(); err != nil {
+		if c.fail == nil {
+			panic(err)
+		}
+		c.fail(err)
+	}
+}
+
+// CheckInvariants verifies the board's bookkeeping, colony membership and
+// the bots that owned structures and colonies point at. With invariant
+// checks enabled it also compares against the previous check, reporting bots
+// that left the board without dying and dead bots that came back. It returns
+// nil when everything is consistent.
+func (g *Game) CheckInvariants() *InvariantError {
+	problems := g.Board.CheckInvariants()
+	problems = append(problems, g.checkColonyMembership()...)
+	problems = append(problems, g.checkOwnedStructures()...)
+	if c := g.invariants; c != nil {
+		problems = append(problems, c.checkBotLifetimes(g)...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &InvariantError{Tick: g.logicTick, Problems: problems}
+}
+
+func (g *Game) checkColonyMembership() []string {
+	var problems []string
+	listed := make(map[*core.Colony]bool, len(g.Colonies))
+	memberOf := map[*core.Bot]*core.Colony{}
+	for _, colony := range g.Colonies {
+		if colony == nil || listed[colony] {
+			problems = append(problems, fmt.Sprintf("colony list holds %p twice or nil", colony))
+			continue
+		}
+		listed[colony] = true
+		for _, m := range colony.Members {
+			switch other, ok := memberOf[m]; {
+			case m == nil:
+				problems = append(problems, fmt.Sprintf("colony at %v has a nil member", colony.Center))
+				continue
+			case ok:
+				problems = append(problems, fmt.Sprintf("bot at %v is a member of colonies at %v and %v", m.Pos, other.Center, colony.Center))
+				continue
+			}
+			memberOf[m] = colony
+			if g.Board.BotIDOf(m) == core.NoBotID {
+				problems = append(problems, fmt.Sprintf("colony at %v keeps member %p (hp %d, age %d) that is not on the board", colony.Center, m, m.Hp, m.Age))
+			}
+			if m.Colony != colony {
+				problems = append(problems, fmt.Sprintf("member at %v of colony at %v points at colony %p", m.Pos, colony.Center, m.Colony))
+			}
+		}
+	}
+	for _, id := range g.Board.ActiveBotIDs() {
+		b := g.Board.BotByID(id)
+		if b.Colony != nil && memberOf[b] != b.Colony {
+			problems = append(problems, fmt.Sprintf("bot at %v points at colony at %v but is not in its members (listed %v)", b.Pos, b.Colony.Center, listed[b.Colony]))
+		}
+		if b.CurrTask != nil && b.CurrTask.Owner != b {
+			problems = append(problems, fmt.Sprintf("bot at %v holds a %v task owned by %p", b.Pos, b.CurrTask.Type, b.CurrTask.Owner))
+		}
+	}
+	return problems
+}
+
+// checkOwnedStructures allows structures to outlive their owner: killBot
+// zeroes a dead bot, so its pointer reads as no one. An owner that is off the
+// board but not zeroed left without dying.
+func (g *Game) checkOwnedStructures() []string {
+	var problems []string
+	for i, kind := range g.Board.Kinds() {
+		if kind == core.CellEmpty || kind == core.CellBot {
+			continue
+		}
+		owner := g.Board.CellAtIdx(i).Owner
+		if owner == nil || g.Board.BotIDOf(owner) != core.NoBotID || !botAlive(owner) {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%v at %v is owned by bot %p (hp %d, age %d) that left the board without dying", kind, util.PosOf(i), owner, owner.Hp, owner.Age))
+	}
+	return problems
+}
+
+// botAlive reports whether b still holds the state killBot clears.
+func botAlive(b *core.Bot) bool {
+	return b.Hp != 0 || b.Age != 0 || b.Colony != nil
+}
+
+func (c *invariantChecker) checkBotLifetimes(g *Game) []string {
+	if c.board != g.Board {
+		// A new generation or a rewind swaps the board; bots that did not
+		// carry over did not die on it.
+		c.board, c.live, c.retired = g.Board, nil, map[*core.Bot]int{}
+	}
+	var problems []string
+	live := make(map[*core.Bot]struct{}, g.Board.ActiveBotCount())
+	for _, id := range g.Board.ActiveBotIDs() {
+		b := g.Board.BotByID(id)
+		live[b] = struct{}{}
+		if tick, ok := c.retired[b]; ok {
+			problems = append(problems, fmt.Sprintf("bot %p left the board by tick %d and is back at %v: its memory was reused", b, tick, b.Pos))
+			delete(c.retired, b)
+		}
+	}
+	var vanished []*core.Bot
+	for b := range c.live {
+		if _, ok := live[b]; ok {
+			continue
+		}
+		c.retired[b] = g.logicTick
+		if botAlive(b) {
+			vanished = append(vanished, b)
+		}
+	}
+	slices.SortFunc(vanished, func(a, b *core.Bot) int {
+		return util.Idx(a.Pos) - util.Idx(b.Pos)
+	})
+	for _, b := range vanished {
+		problems = append(problems, fmt.Sprintf("bot %p (hp %d, age %d) left the board at %v without dying; the cell now holds %v", b, b.Hp, b.Age, b.Pos, g.Board.KindAt(b.Pos)))
+	}
+	c.live = live
+	return problems
+}
@@ -0,0 +1,248 @@
+package game
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"golab/internal/core"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type MetricsFormat string
+
+const (
+	MetricsCSV    MetricsFormat = "csv"
+	MetricsNDJSON MetricsFormat = "ndjson"
+)
+
+// MetricsSample is one row of the per-tick metrics time series. Births, deaths
+// and kills count only what happened since the previous exported row.
+type MetricsSample struct {
+	Seed                 int64   `json:"seed"`
+	Tick                 int     `json:"tick"`
+	LiveBots             int     `json:"live_bots"`
+	Births               int     `json:"births"`
+	Immigrants           int     `json:"immigrants"`
+	Deaths               int     `json:"deaths"`
+	AgeDeaths            int     `json:"age_deaths"`
+	StarvationDeaths     int     `json:"starvation_deaths"`
+	PoisonDeaths         int     `json:"poison_deaths"`
+	CombatDeaths         int     `json:"combat_deaths"`
+	CurseDeaths          int     `json:"curse_deaths"`
+	CrowdingDeaths       int     `json:"crowding_deaths"`
+	CombatKills          int     `json:"combat_kills"`
+	ActiveColonies       int     `json:"active_colonies"`
+	Controllers          int     `json:"controllers"`
+	PheromoneActiveCells int     `json:"pheromone_active_cells"`
+	FoodPheromone        int     `json:"food_pheromone"`
+	OrePheromone         int     `json:"ore_pheromone"`
+	HomePheromone        int     `json:"home_pheromone"`
+	DangerPheromone      int     `json:"danger_pheromone"`
+	Food                 int     `json:"food"`
+	Resources            int     `json:"resources"`
+	Poison               int     `json:"poison"`
+	Organics             int     `json:"organics"`
+	TicksPerSecond       float64 `json:"tps"`
+}
+
+type metricsCounters struct {
+	births      int
+	immigrants  int
+	deaths      int
+	causes      map[core.DeathCause]int
+	combatKills int
+}
+
+// MetricsExporter streams MetricsSample rows as CSV or NDJSON.
+type MetricsExporter struct {
+	out     io.Writer
+	format  MetricsFormat
+	every   int
+	csv     *csv.Writer
+	json    *json.Encoder
+	columns []string
+	header  bool
+	err     error
+}
+
+func NewMetricsExporter(out io.Writer, format MetricsFormat, every int) (*MetricsExporter, error) {
+	if out == nil {
+		return nil, fmt.Errorf("metrics output is required")
+	}
+	if every <= 0 {
+		every = 1
+	}
+	exporter := &MetricsExporter{out: out, format: format, every: every}
+	switch format {
+	case MetricsCSV:
+		exporter.csv = csv.NewWriter(out)
+		exporter.columns = metricsColumns()
+	case MetricsNDJSON:
+		exporter.json = json.NewEncoder(out)
+	default:
+		return nil, fmt.Errorf("unknown metrics format %q: use csv or ndjson", format)
+	}
+	return exporter, nil
+}
+
+// ParseMetricsFormat resolves an explicit format name, falling back to the
+// output path extension and then to NDJSON.
+func ParseMetricsFormat(name, path string) (MetricsFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "csv":
+		return MetricsCSV, nil
+	case "ndjson", "jsonl", "json":
+		return MetricsNDJSON, nil
+	case "":
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			return MetricsCSV, nil
+		}
+		return MetricsNDJSON, nil
+	default:
+		return "", fmt.Errorf("unknown metrics format %q: use csv or ndjson", name)
+	}
+}
+
+func (e *MetricsExporter) Every() int {
+	return e.every
+}
+
+func (e *MetricsExporter) Write(sample MetricsSample) error {
+	if e.err != nil {
+		return e.err
+	}
+	switch e.format {
+	case MetricsCSV:
+		if !e.header {
+			e.header = true
+			if e.err = e.csv.Write(e.columns); e.err != nil {
+				return e.err
+			}
+		}
+		if e.err = e.csv.Write(metricsRecord(sample)); e.err != nil {
+			return e.err
+		}
+		e.csv.Flush()
+		e.err = e.csv.Error()
+	case MetricsNDJSON:
+		e.err = e.json.Encode(sample)
+	}
+	return e.err
+}
+
+func (e *MetricsExporter) Close() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if e.err == nil {
+			e.err = e.csv.Error()
+		}
+	}
+	if closer, ok := e.out.(io.Closer); ok {
+		if err := closer.Close(); err != nil && e.err == nil {
+			e.err = err
+		}
+	}
+	return e.err
+}
+
+func metricsColumns() []string {
+	typ := reflect.TypeOf(MetricsSample{})
+	columns := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		columns = append(columns, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+	}
+	return columns
+}
+
+func metricsRecord(sample MetricsSample) []string {
+	value := reflect.ValueOf(sample)
+	record := make([]string, 0, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.Float64:
+			record = append(record, strconv.FormatFloat(field.Float(), 'f', 2, 64))
+		default:
+			record = append(record, strconv.FormatInt(field.Int(), 10))
+		}
+	}
+	return record
+}
+
+// EnableMetrics streams a metrics row after every exporter interval of logic
+// ticks. The seed only labels rows so several runs can share one output.
+func (g *Game) EnableMetrics(exporter *MetricsExporter, seed int64) {
+	g.metrics = exporter
+	g.metricsSeed = seed
+	g.metricsLast = g.metricsCounters()
+}
+
+func (g *Game) MetricsSample() MetricsSample {
+	counters := g.metricsCounters()
+	sample := MetricsSample{
+		Seed:        g.metricsSeed,
+		Tick:        g.logicTick,
+		LiveBots:    g.Board.ActiveBotCount(),
+		Births:      counters.births - g.metricsLast.births,
+		Immigrants:  counters.immigrants - g.metricsLast.immigrants,
+		Deaths:      counters.deaths - g.metricsLast.deaths,
+		CombatKills: counters.combatKills - g.metricsLast.combatKills,
+	}
+	causeDelta := func(cause core.DeathCause) int {
+		return counters.causes[cause] - g.metricsLast.causes[cause]
+	}
+	sample.AgeDeaths = causeDelta(core.DeathAge)
+	sample.StarvationDeaths = causeDelta(core.DeathStarvation)
+	sample.PoisonDeaths = causeDelta(core.DeathPoison)
+	sample.CombatDeaths = causeDelta(core.DeathCombat)
+	sample.CurseDeaths = causeDelta(core.DeathCurse)
+	sample.CrowdingDeaths = causeDelta(core.DeathCrowding)
+	sample.ActiveColonies = g.activeColonyCount()
+	pheromones := g.Board.PheromoneTotals()
+	sample.PheromoneActiveCells = pheromones.ActiveCells
+	sample.FoodPheromone = pheromones.Food
+	sample.OrePheromone = pheromones.Ore
+	sample.HomePheromone = pheromones.Home
+	sample.DangerPheromone = pheromones.Danger
+	for _, kind := range g.Board.Kinds() {
+		switch kind {
+		case core.CellController:
+			sample.Controllers++
+		case core.CellFood:
+			sample.Food++
+		case core.CellResource:
+			sample.Resources++
+		case core.CellPoison:
+			sample.Poison++
+		case core.CellOrganics:
+			sample.Organics++
+		}
+	}
+	if g.State != nil {
+		sample.TicksPerSecond = g.State.LogicTicksPerSecond
+	}
+	return sample
+}
+
+func (g *Game) recordMetrics() {
+	if g.metrics == nil || g.logicTick%g.metrics.Every() != 0 {
+		return
+	}
+	sample := g.MetricsSample()
+	g.metricsLast = g.metricsCounters()
+	g.metrics.Write(sample)
+}
+
+func (g *Game) metricsCounters() metricsCounters {
+	return metricsCounters{
+		births:      g.successfulDivisions,
+		immigrants:  g.totalImmigrants,
+		deaths:      g.totalDeaths,
+		causes:      g.DeathsByCause(),
+		combatKills: g.totalCombatKills,
+	}
+}
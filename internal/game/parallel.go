@@ -0,0 +1,200 @@
+package game
+
+import (
+	"sync"
+
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+// botStripeRows is the height of one stripe of the parallel bot scheduler.
+// A stripe-local step reads and writes only the bot's cell and its eight
+// neighbours, and stripes of the same parity are a whole stripe apart, so
+// they never touch the same cell or dirty-bitmap word.
+const botStripeRows = 8
+
+// botStripe is one band of rows and the bots that started the tick in it.
+type botStripe struct {
+	ids      []core.BotID
+	deferred []core.BotID
+	dirty    []int
+	saved    stripeBotState
+	bailed   bool
+}
+
+// stripeBotState is every bot field a stripe-local step can change, so a
+// bailed step can be undone without copying the whole bot.
+type stripeBotState struct {
+	dir      util.Direction
+	pos      core.Position
+	age, hp  int
+	pointer  int
+	nextArg  int
+	signal   int
+	register [4]int
+}
+
+func saveStripeBot(b *core.Bot) stripeBotState {
+	return stripeBotState{
+		dir:      b.Dir,
+		pos:      b.Pos,
+		age:      b.Age,
+		hp:       b.Hp,
+		pointer:  b.Genome.Pointer,
+		nextArg:  b.Genome.NextArg,
+		signal:   b.Genome.Signal,
+		register: b.Genome.Registers,
+	}
+}
+
+func (st stripeBotState) restore(b *core.Bot) {
+	b.Dir, b.Pos, b.Age, b.Hp = st.dir, st.pos, st.age, st.hp
+	b.Genome.Pointer, b.Genome.NextArg, b.Genome.Signal = st.pointer, st.nextArg, st.signal
+	b.Genome.Registers = st.register
+}
+
+// SetBotWorkers opts into the parallel bot scheduler with n workers; n <= 1
+// keeps the serial scheduler. The parallel scheduler steps every bot whose
+// tick only touches its own state and cells in checkerboard stripes, then
+// runs the rest serially, stripe by stripe. Its results depend on the seed
+// alone, not on n, but differ from the serial scheduler's. Pheromone decay
+// and diffusion also use n workers; their results are the same for any n.
+func (g *Game) SetBotWorkers(n int) {
+	g.botWorkers = max(n, 1)
+	if g.botWorkers == 1 || g.botStripes != nil {
+		return
+	}
+	g.botStripes = make([]botStripe, (util.Rows+botStripeRows-1)/botStripeRows)
+}
+
+func (g *Game) BotWorkers() int {
+	return max(g.botWorkers, 1)
+}
+
+func (g *Game) botsActionsParallel() {
+	g.collectStripeBots()
+	for parity := range 2 {
+		g.forEachStripe(parity, 2, (*Game).stepStripe)
+		for k := parity; k < len(g.botStripes); k += 2 {
+			s := &g.botStripes[k]
+			g.Board.FlushLocalMoves(s.dirty)
+			s.dirty = s.dirty[:0]
+		}
+	}
+	for k := range g.botStripes {
+		for _, id := range g.botStripes[k].deferred {
+			g.stepBot(id)
+		}
+	}
+}
+
+// collectStripeBots buckets the active bots by the stripe of their cell,
+// keeping registry order within a stripe so workers walk bot memory in order.
+func (g *Game) collectStripeBots() {
+	for k := range g.botStripes {
+		g.botStripes[k].ids = g.botStripes[k].ids[:0]
+		g.botStripes[k].deferred = g.botStripes[k].deferred[:0]
+	}
+	stripeCells := botStripeRows * util.Cols
+	for _, id := range g.Board.ActiveBotIDs() {
+		if cell := g.Board.BotCell(id); cell >= 0 {
+			s := &g.botStripes[cell/stripeCells]
+			s.ids = append(s.ids, id)
+		}
+	}
+}
+
+// forEachStripe runs fn on stripes first, first+step, ... spread over the
+// workers, and returns once all of them are done.
+func (g *Game) forEachStripe(first, step int, fn func(*Game, *botStripe)) {
+	var wg sync.WaitGroup
+	stride := g.botWorkers * step
+	for w := range g.botWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := first + w*step; k < len(g.botStripes); k += stride {
+				fn(g, &g.botStripes[k])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (g *Game) stepStripe(s *botStripe) {
+	for _, id := range s.ids {
+		if !g.stepBotLocal(s, id) {
+			s.deferred = append(s.deferred, id)
+		}
+	}
+}
+
+// stepBotLocal is stepBot for a stripe worker. It reports false, leaving the
+// bot as it was, when the step would do anything beyond moving the bot or
+// changing its own fields: colony members, bots with tasks, bots about to die
+// and genomes reaching a non-local opcode all go to the serial pass.
+func (g *Game) stepBotLocal(s *botStripe, id core.BotID) bool {
+	b := g.Board.BotByID(id)
+	i := g.Board.BotCell(id)
+	if b == nil || i < 0 {
+		return true
+	}
+	pos := util.PosOf(i)
+	if g.Board.IsFrozen(pos) {
+		return true
+	}
+	if b.Colony != nil || b.ConnnectedToColony || b.CurrTask != nil {
+		return false
+	}
+	hp := min(b.Hp-g.calcHpChange(), 500)
+	if hp <= 0 || (g.config.MaxBotAge > 0 && b.Age+1 > g.config.MaxBotAge) {
+		return false
+	}
+
+	s.saved = saveStripeBot(b)
+	dirty := len(s.dirty)
+	b.Age++
+	b.Hp = hp
+	s.bailed = false
+	g.botActionIn(s, pos, b)
+	if s.bailed {
+		s.saved.restore(b)
+		s.dirty = s.dirty[:dirty]
+		return false
+	}
+	return true
+}
+
+// stripeMove is tryMove for a bot without a task on a stripe worker. Moving
+// onto food or ore bails out, since the pickup updates shared counters.
+func (g *Game) stripeMove(s *botStripe, oldPos core.Position, b *core.Bot) {
+	s.dirty = append(s.dirty, idx(oldPos))
+	newPos := oldPos.AddDir(b.Dir)
+	if g.Board.IsFrozen(newPos) {
+		return
+	}
+	switch g.Board.KindAt(newPos) {
+	case core.CellFood, core.CellResource:
+		s.bailed = true
+		return
+	}
+	if !g.Board.IsEmpty(newPos) {
+		return
+	}
+	b.Pos = newPos
+	s.dirty, _ = g.Board.MoveBotLocal(oldPos, newPos, b, s.dirty)
+}
+
+// stripeLocalOpcode reports whether op only reads and writes the bot fields
+// in stripeBotState, apart from the plain moves stripeMove handles.
+func stripeLocalOpcode(op core.Opcode) bool {
+	switch op {
+	case core.OpMove, core.OpMoveAbs, core.OpTurn, core.OpCheckHp,
+		core.OpCheckConnection, core.OpCheckSignal, core.OpExecuteInstr,
+		core.OpHpToResource, core.OpEatOther, core.OpSetReg, core.OpIncReg,
+		core.OpDecReg, core.OpCmpReg, core.OpJumpIfZero:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,173 @@
+package game
+
+import (
+	"runtime/metrics"
+	"time"
+
+	conf "golab/internal/config"
+)
+
+// TickPhase is one stage of runLogicTick, timed when phase timers are on.
+type TickPhase int
+
+const (
+	// PhaseChampionScan counts live bots and picks the generation champion.
+	PhaseChampionScan TickPhase = iota
+	PhaseBots
+	PhaseEnvironment
+	PhaseImmigration
+	// PhaseGeneration reseeds the board after every bot has died.
+	PhaseGeneration
+	PhasePheromones
+	PhaseGameMaster
+	// PhaseOther is rewind capture, heat sampling, metrics and invariant
+	// checks.
+	PhaseOther
+	NumTickPhases
+)
+
+var tickPhaseNames = [NumTickPhases]string{
+	PhaseChampionScan: "champion_scan",
+	PhaseBots:         "bots",
+	PhaseEnvironment:  "environment",
+	PhaseImmigration:  "immigration",
+	PhaseGeneration:   "generation",
+	PhasePheromones:   "pheromones",
+	PhaseGameMaster:   "game_master",
+	PhaseOther:        "other",
+}
+
+func (p TickPhase) String() string {
+	if p >= 0 && p < NumTickPhases {
+		return tickPhaseNames[p]
+	}
+	return "unknown"
+}
+
+// PhaseTimes accumulates wall time, and optionally heap allocations, per
+// tick phase over Ticks ticks.
+type PhaseTimes struct {
+	Ticks  int
+	Nanos  [NumTickPhases]int64
+	Allocs [NumTickPhases]uint64
+	Bytes  [NumTickPhases]uint64
+}
+
+// Total returns the sum over all phases.
+func (p PhaseTimes) Total() (nanos int64, allocs, bytes uint64) {
+	for phase := range NumTickPhases {
+		nanos += p.Nanos[phase]
+		allocs += p.Allocs[phase]
+		bytes += p.Bytes[phase]
+	}
+	return nanos, allocs, bytes
+}
+
+// phaseClock times runLogicTick. New games start with one that reads only
+// the wall clock. Its methods are no-ops on a nil clock, so the tick loop
+// pays one nil check per phase when timers are off.
+type phaseClock struct {
+	times PhaseTimes
+	// published is times as of the last publishTickPhases.
+	published PhaseTimes
+	// allocs also reads the runtime's cumulative heap allocation counters at
+	// every lap. They are cheap but not free, so only benchmarks ask.
+	allocs  bool
+	last    time.Time
+	samples [2]metrics.Sample
+}
+
+// EnablePhaseTimers restarts the phase timers with fresh totals. With allocs
+// set they also count heap allocations per phase.
+func (g *Game) EnablePhaseTimers(allocs bool) {
+	g.phases = newPhaseClock(allocs)
+}
+
+func newPhaseClock(allocs bool) *phaseClock {
+	c := &phaseClock{allocs: allocs}
+	c.samples[0].Name = "/gc/heap/allocs:objects"
+	c.samples[1].Name = "/gc/heap/allocs:bytes"
+	return c
+}
+
+// DisablePhaseTimers stops timing ticks; State.TickPhases keeps its last
+// values.
+func (g *Game) DisablePhaseTimers() {
+	g.phases = nil
+}
+
+// PhaseTimes returns the totals since phase timers were enabled or last
+// reset, and whether they are enabled.
+func (g *Game) PhaseTimes() (PhaseTimes, bool) {
+	if g.phases == nil {
+		return PhaseTimes{}, false
+	}
+	return g.phases.times, true
+}
+
+// ResetPhaseTimes clears the totals, for example after warmup ticks.
+func (g *Game) ResetPhaseTimes() {
+	if g.phases != nil {
+		g.phases.times, g.phases.published = PhaseTimes{}, PhaseTimes{}
+	}
+}
+
+// publishTickPhases stores each phase's average time per tick since the last
+// call in State.TickPhases for the HUD.
+func (g *Game) publishTickPhases() {
+	c := g.phases
+	if c == nil || g.State == nil {
+		return
+	}
+	ticks := c.times.Ticks - c.published.Ticks
+	if ticks <= 0 {
+		return
+	}
+	// A fresh slice each time: rewind snapshots keep copies of State.
+	phases := make([]conf.TickPhaseTime, NumTickPhases)
+	for phase := range NumTickPhases {
+		nanos := c.times.Nanos[phase] - c.published.Nanos[phase]
+		phases[phase] = conf.TickPhaseTime{
+			Name:   phase.String(),
+			Millis: float64(nanos) / float64(ticks) / float64(time.Millisecond),
+		}
+	}
+	g.State.TickPhases = phases
+	c.published = c.times
+}
+
+func (c *phaseClock) begin() {
+	if c == nil {
+		return
+	}
+	if c.allocs {
+		metrics.Read(c.samples[:])
+	}
+	c.last = time.Now()
+}
+
+// lap charges the time, and allocations, since the previous lap to phase.
+func (c *phaseClock) lap(phase TickPhase) {
+	if c == nil {
+		return
+	}
+	now := time.Now()
+	c.times.Nanos[phase] += int64(now.Sub(c.last))
+	if c.allocs {
+		objects, bytes := c.samples[0].Value.Uint64(), c.samples[1].Value.Uint64()
+		metrics.Read(c.samples[:])
+		c.times.Allocs[phase] += c.samples[0].Value.Uint64() - objects
+		c.times.Bytes[phase] += c.samples[1].Value.Uint64() - bytes
+		// Reading the counters takes time too; start the next phase after it.
+		now = time.Now()
+	}
+	c.last = now
+}
+
+func (c *phaseClock) end() {
+	if c == nil {
+		return
+	}
+	c.lap(PhaseOther)
+	c.times.Ticks++
+}
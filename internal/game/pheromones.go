@@ -12,12 +12,12 @@ func (g *Game) updatePheromones() {
 	if g.config.PheromoneDecayPeriod > 0 &&
 		g.config.PheromoneDecay > 0 &&
 		g.logicTick%g.config.PheromoneDecayPeriod == 0 {
-		g.Board.DecayPheromones(g.config.PheromoneDecay)
+		g.Board.DecayPheromonesParallel(g.config.PheromoneDecay, g.BotWorkers())
 	}
 	if g.config.PheromoneDiffusePeriod > 0 &&
 		g.config.PheromoneDiffuseAmount > 0 &&
 		g.logicTick%g.config.PheromoneDiffusePeriod == 0 {
-		g.Board.DiffusePheromones(g.config.PheromoneDiffuseAmount)
+		g.Board.DiffusePheromonesParallel(g.config.PheromoneDiffuseAmount, g.BotWorkers())
 	}
 }
 
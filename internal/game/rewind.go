@@ -0,0 +1,255 @@
+package game
+
+import (
+	conf "golab/internal/config"
+	"golab/internal/core"
+	"golab/internal/util"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Snapshot is a compact copy of the simulation at the start of a logic tick.
+// Config, metrics, events and heatmaps are not part of it: they keep running
+// across a rewind.
+type Snapshot struct {
+	Tick int
+
+	seed  int64
+	taken time.Time
+	board *core.BoardSnapshot
+
+	colonies                []*core.Colony
+	initialGenome           *core.Genome
+	state                   conf.GameState
+	maxHp                   int
+	currGen                 int
+	latestImprovement       int
+	generationSeedGenome    core.Genome
+	generationSeedRank      generationChampionRank
+	hasGenerationSeedGenome bool
+	eliteGenomes            []eliteGenome
+	eliteImmigrantCursor    int
+	successfulDivisions     int
+	totalFoodGathered       int
+	totalOreGathered        int
+	totalStolenFood         int
+	totalStolenOre          int
+	totalCombatKills        int
+	totalControllerRaids    int
+	totalDepotRaids         int
+	totalSpawnerBirths      int
+	totalDeaths             int
+	totalImmigrants         int
+	colonyIDs               map[*core.Colony]int
+	deathsByCause           map[core.DeathCause]int
+	colonyDeaths            map[*core.Colony]map[core.DeathCause]int
+	selectedColony          *core.Colony
+}
+
+// rewindBuffer keeps the latest snapshots in a ring, oldest overwritten first.
+type rewindBuffer struct {
+	every int
+	seed  int64
+	ring  []*Snapshot
+	start int
+	count int
+}
+
+// EnableRewind keeps a snapshot every `every` logic ticks, up to keep of
+// them. Both PRNGs are reseeded from seed and the tick at every snapshot, so
+// a run resumed from a snapshot replays the ticks that followed it.
+func (g *Game) EnableRewind(every, keep int, seed int64) {
+	if every <= 0 || keep <= 0 {
+		g.rewind = nil
+		return
+	}
+	g.rewind = &rewindBuffer{every: every, seed: seed, ring: make([]*Snapshot, keep)}
+}
+
+// RewindTicks lists the ticks that can be rewound to, oldest first.
+func (g *Game) RewindTicks() []int {
+	if g.rewind == nil {
+		return nil
+	}
+	ticks := make([]int, g.rewind.count)
+	for i := range ticks {
+		ticks[i] = g.rewind.at(i).Tick
+	}
+	return ticks
+}
+
+// RewindTo restores the newest snapshot taken at or before tick and drops the
+// snapshots after it. It returns the restored tick.
+func (g *Game) RewindTo(tick int) (int, bool) {
+	r := g.rewind
+	if r == nil {
+		return 0, false
+	}
+	for i := r.count - 1; i >= 0; i-- {
+		snap := r.at(i)
+		if snap.Tick > tick {
+			continue
+		}
+		r.count = i + 1
+		g.restoreSnapshot(snap)
+		return snap.Tick, true
+	}
+	return 0, false
+}
+
+func (r *rewindBuffer) at(i int) *Snapshot {
+	return r.ring[(r.start+i)%len(r.ring)]
+}
+
+func (r *rewindBuffer) push(snap *Snapshot) {
+	if r.count > 0 && r.at(r.count-1).Tick == snap.Tick {
+		r.ring[(r.start+r.count-1)%len(r.ring)] = snap
+		return
+	}
+	if r.count == len(r.ring) {
+		r.ring[r.start] = snap
+		r.start = (r.start + 1) % len(r.ring)
+		return
+	}
+	r.ring[(r.start+r.count)%len(r.ring)] = snap
+	r.count++
+}
+
+func (r *rewindBuffer) clear() {
+	clear(r.ring)
+	r.start, r.count = 0, 0
+}
+
+// rewindSeed mixes the run seed with a tick, so each snapshot point gets its
+// own PRNG stream.
+func rewindSeed(seed int64, tick int) int64 {
+	x := uint64(seed) ^ uint64(tick)*0x9e3779b97f4a7c15
+	x ^= x >> 31
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 29
+	return int64(x)
+}
+
+// reseedRandom restarts g's own PRNG state, or the current one if it has
+// none.
+func (g *Game) reseedRandom(seed int64) {
+	if g.random != nil {
+		g.random.Seed(seed)
+		return
+	}
+	util.SeedRandom(seed)
+}
+
+// captureRewind runs at the start of each logic tick and snapshots the game
+// when the tick is due.
+func (g *Game) captureRewind() {
+	r := g.rewind
+	if r == nil || g.logicTick%r.every != 0 {
+		return
+	}
+	snap := g.snapshot()
+	snap.seed = rewindSeed(r.seed, g.logicTick)
+	r.push(snap)
+	g.reseedRandom(snap.seed)
+}
+
+func (g *Game) snapshot() *Snapshot {
+	c := core.NewCloner(0)
+	snap := &Snapshot{
+		Tick:                    g.logicTick,
+		taken:                   time.Now(),
+		board:                   g.Board.Snapshot(c),
+		colonies:                c.Colonies(g.Colonies),
+		state:                   *g.State,
+		maxHp:                   g.maxHp,
+		currGen:                 g.currGen,
+		latestImprovement:       g.latestImprovement,
+		generationSeedGenome:    g.generationSeedGenome,
+		generationSeedRank:      g.generationSeedRank,
+		hasGenerationSeedGenome: g.hasGenerationSeedGenome,
+		eliteGenomes:            slices.Clone(g.eliteGenomes),
+		eliteImmigrantCursor:    g.eliteImmigrantCursor,
+		successfulDivisions:     g.successfulDivisions,
+		totalFoodGathered:       g.totalFoodGathered,
+		totalOreGathered:        g.totalOreGathered,
+		totalStolenFood:         g.totalStolenFood,
+		totalStolenOre:          g.totalStolenOre,
+		totalCombatKills:        g.totalCombatKills,
+		totalControllerRaids:    g.totalControllerRaids,
+		totalDepotRaids:         g.totalDepotRaids,
+		totalSpawnerBirths:      g.totalSpawnerBirths,
+		totalDeaths:             g.totalDeaths,
+		totalImmigrants:         g.totalImmigrants,
+		colonyIDs:               core.ColonyKeys(c, g.colonyIDs),
+		deathsByCause:           maps.Clone(g.deathsByCause),
+		colonyDeaths:            cloneColonyDeaths(c, g.colonyDeaths),
+		selectedColony:          c.Colony(g.selectedColony),
+	}
+	if g.InitialGenome != nil {
+		genome := *g.InitialGenome
+		snap.initialGenome = &genome
+	}
+	return snap
+}
+
+// restoreSnapshot installs a fresh copy of snap, so it can be restored again.
+// Heatmaps carry over from the current board.
+func (g *Game) restoreSnapshot(snap *Snapshot) {
+	c := core.NewCloner(time.Since(snap.taken))
+	previous := g.Board
+	g.Board = snap.board.Restore(c)
+	if g.heatmaps {
+		g.Board.CopyHeatFrom(previous)
+	}
+	g.Colonies = c.Colonies(snap.colonies)
+	g.InitialGenome = nil
+	if snap.initialGenome != nil {
+		genome := *snap.initialGenome
+		g.InitialGenome = &genome
+	}
+	g.logicTick = snap.Tick
+	g.maxHp = snap.maxHp
+	g.currGen = snap.currGen
+	g.latestImprovement = snap.latestImprovement
+	g.generationSeedGenome = snap.generationSeedGenome
+	g.generationSeedRank = snap.generationSeedRank
+	g.hasGenerationSeedGenome = snap.hasGenerationSeedGenome
+	g.eliteGenomes = slices.Clone(snap.eliteGenomes)
+	g.eliteImmigrantCursor = snap.eliteImmigrantCursor
+	g.successfulDivisions = snap.successfulDivisions
+	g.totalFoodGathered = snap.totalFoodGathered
+	g.totalOreGathered = snap.totalOreGathered
+	g.totalStolenFood = snap.totalStolenFood
+	g.totalStolenOre = snap.totalStolenOre
+	g.totalCombatKills = snap.totalCombatKills
+	g.totalControllerRaids = snap.totalControllerRaids
+	g.totalDepotRaids = snap.totalDepotRaids
+	g.totalSpawnerBirths = snap.totalSpawnerBirths
+	g.totalDeaths = snap.totalDeaths
+	g.totalImmigrants = snap.totalImmigrants
+	g.colonyIDs = core.ColonyKeys(c, snap.colonyIDs)
+	g.deathsByCause = maps.Clone(snap.deathsByCause)
+	g.colonyDeaths = cloneColonyDeaths(c, snap.colonyDeaths)
+	g.selectedColony = c.Colony(snap.selectedColony)
+	g.metricsLast = g.metricsCounters()
+	g.tpsWindowStart = time.Time{}
+	g.tpsWindowTick = 0
+
+	tps, phases := g.State.LogicTicksPerSecond, g.State.TickPhases
+	*g.State = snap.state
+	g.State.LogicTick = snap.Tick
+	g.State.LogicTicksPerSecond = tps
+	g.State.TickPhases = phases
+	g.State.LastLogic = time.Now()
+	g.config.LiveBots = g.liveBotCount()
+	g.reseedRandom(snap.seed)
+}
+
+func cloneColonyDeaths(c *core.Cloner, deaths map[*core.Colony]map[core.DeathCause]int) map[*core.Colony]map[core.DeathCause]int {
+	clone := core.ColonyKeys(c, deaths)
+	for colony, causes := range clone {
+		clone[colony] = maps.Clone(causes)
+	}
+	return clone
+}
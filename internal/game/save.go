@@ -228,7 +228,7 @@ func (g *Game) saveMapToDir(dir string) (string, int, int, int, error) {
 		},
 	}
 
-	for idx, cell := range *g.Board.GetGrid() {
+	for idx, cell := range g.Board.Occupants() {
 		pos := util.PosOf(idx)
 		biome := g.Board.BiomeAtIdx(idx)
 		save.BiomeCounts[biome.String()]++
@@ -303,31 +303,14 @@ func mapCellFromOccupant(pos core.Position, cell core.Occupant) (mapCellSave, bo
 		out.Ore = v.Ore
 		out.HasOwner = v.Owner != nil
 		out.HasColony = v.Colony != nil
-	case *core.Depot:
-		if v == nil {
-			return mapCellSave{}, false
-		}
-		out.Kind = "depot"
-		out.Food = v.Food
-		out.Ore = v.Ore
-		out.HasOwner = v.Owner != nil
-		out.HasColony = v.Colony != nil
 	case core.Controller:
 		out.Kind = "controller"
 		out.Amount = v.Amount
 		out.WaterAmount = v.WaterAmount
 		out.HasOwner = v.Owner != nil
 		out.HasColony = v.Colony != nil
-	case *core.Controller:
-		out.Kind = "controller"
-		out.Amount = v.Amount
-		out.WaterAmount = v.WaterAmount
-		out.HasOwner = v.Owner != nil
-		out.HasColony = v.Colony != nil
 	case core.ColonyFlag:
 		out.Kind = "colony_flag"
-	case *core.ColonyFlag:
-		out.Kind = "colony_flag"
 	default:
 		out.Kind = fmt.Sprintf("%T", cell)
 	}
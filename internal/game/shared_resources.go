@@ -172,23 +172,21 @@ func (g *Game) nearbySameColonyDepots(colony *core.Colony, center core.Position,
 		return nil
 	}
 	refs := make([]sharedDepotRef, 0, 8)
-	for r := center.R - radius; r <= center.R+radius; r++ {
-		if r < 0 || r >= core.Rows {
+	for i := range g.Board.InRadius(core.CellDepot, center, radius) {
+		if g.Board.CellAtIdx(i).Colony != colony {
 			continue
 		}
-		for dc := -radius; dc <= radius; dc++ {
-			pos := util.NewPos(r, center.C+dc)
-			depot, ok := depotAt(g.Board.At(pos))
-			if !ok || depot.Colony != colony {
-				continue
-			}
-			refs = append(refs, sharedDepotRef{
-				pos:      pos,
-				depot:    depot,
-				distance: boardDistance(center, pos),
-				index:    util.Idx(pos),
-			})
+		pos := util.PosOf(i)
+		depot, ok := depotAt(g.Board.At(pos))
+		if !ok {
+			continue
 		}
+		refs = append(refs, sharedDepotRef{
+			pos:      pos,
+			depot:    depot,
+			distance: boardDistance(center, pos),
+			index:    i,
+		})
 	}
 	sort.Slice(refs, func(i, j int) bool {
 		if refs[i].distance != refs[j].distance {
@@ -203,36 +201,16 @@ func (g *Game) hasNearbySameColonyController(colony *core.Colony, center core.Po
 	if g == nil || g.Board == nil || colony == nil || radius < 0 {
 		return false
 	}
-	for r := center.R - radius; r <= center.R+radius; r++ {
-		if r < 0 || r >= core.Rows {
-			continue
-		}
-		for dc := -radius; dc <= radius; dc++ {
-			pos := util.NewPos(r, center.C+dc)
-			switch ctrl := g.Board.At(pos).(type) {
-			case core.Controller:
-				if ctrl.Colony == colony {
-					return true
-				}
-			case *core.Controller:
-				if ctrl != nil && ctrl.Colony == colony {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	_, ok := g.Board.NearestOfKind(core.CellController, center, radius, func(i int) bool {
+		return g.Board.CellAtIdx(i).Colony == colony
+	})
+	return ok
 }
 
 func depotAt(cell core.Occupant) (core.Depot, bool) {
 	switch depot := cell.(type) {
 	case core.Depot:
 		return depot, true
-	case *core.Depot:
-		if depot == nil {
-			return core.Depot{}, false
-		}
-		return *depot, true
 	default:
 		return core.Depot{}, false
 	}
@@ -0,0 +1,55 @@
+package game
+
+import "golab/internal/core"
+
+// StateHash fingerprints the simulation by component, so two runs of a seed
+// can be compared tick by tick and a mismatch traced to what diverged first.
+type StateHash struct {
+	Grid       uint64
+	Bots       uint64
+	Pheromones uint64
+	Colonies   uint64
+}
+
+// StateHashComponents names the StateHash fields in the order Diverged
+// reports them.
+var StateHashComponents = []string{"bots", "grid", "pheromones", "colonies"}
+
+// StateHash hashes the board and the colony list. Equal seeds and configs
+// must give equal hashes after every tick.
+func (g *Game) StateHash() StateHash {
+	board := g.Board.HashParts()
+	return StateHash{
+		Grid:       board.Grid,
+		Bots:       board.Bots,
+		Pheromones: board.Pheromones,
+		Colonies:   core.HashColonies(g.Colonies),
+	}
+}
+
+// Component returns the hash StateHashComponents calls name.
+func (h StateHash) Component(name string) uint64 {
+	switch name {
+	case "grid":
+		return h.Grid
+	case "bots":
+		return h.Bots
+	case "pheromones":
+		return h.Pheromones
+	case "colonies":
+		return h.Colonies
+	default:
+		return 0
+	}
+}
+
+// Diverged lists the components whose hashes differ from other's.
+func (h StateHash) Diverged(other StateHash) []string {
+	var names []string
+	for _, name := range StateHashComponents {
+		if h.Component(name) != other.Component(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -8,6 +8,7 @@ import (
 	"image/png"
 	"os"
 
+	"golab/internal/assets"
 	"golab/internal/core"
 	"golab/internal/util"
 )
@@ -36,6 +37,7 @@ var (
 )
 
 type Options struct {
+	// AtlasPath overrides the assets atlas when set.
 	AtlasPath string
 	Output    string
 
@@ -46,6 +48,11 @@ type Options struct {
 	Legend bool
 
 	Style string
+	// HeatLayer picks the cumulative heatmap drawn by the heatmap style.
+	HeatLayer core.HeatLayer
+
+	// territory is computed once per render for the territory style.
+	territory *core.Territory
 
 	RenderPaths        bool
 	RenderTaskTargets  bool
@@ -59,9 +66,45 @@ type Result struct {
 }
 
 func SaveBoardPNG(brd *core.Board, opts Options) (Result, error) {
-	if opts.AtlasPath == "" {
-		opts.AtlasPath = "assests/sprites/atlas.png"
+	opts, err := normalizeOptions(opts)
+	if err != nil {
+		return Result{}, err
+	}
+	atlas, err := loadAtlas(opts.AtlasPath)
+	if err != nil {
+		return Result{}, err
+	}
+	img, err := renderBoard(brd, opts, atlas)
+	if err != nil {
+		return Result{}, err
+	}
+
+	file, err := os.Create(opts.Output)
+	if err != nil {
+		return Result{}, err
 	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		return Result{}, err
+	}
+
+	return Result{Output: opts.Output, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}, nil
+}
+
+// RenderBoard draws brd into a new image without writing it anywhere.
+func RenderBoard(brd *core.Board, opts Options) (*image.RGBA, error) {
+	opts, err := normalizeOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	atlas, err := loadAtlas(opts.AtlasPath)
+	if err != nil {
+		return nil, err
+	}
+	return renderBoard(brd, opts, atlas)
+}
+
+func normalizeOptions(opts Options) (Options, error) {
 	if opts.Output == "" {
 		opts.Output = "golab-render.png"
 	}
@@ -74,20 +117,15 @@ func SaveBoardPNG(brd *core.Board, opts Options) (Result, error) {
 	if opts.Style == "" {
 		opts.Style = "game"
 	}
-	if opts.Style != "flat" && opts.Style != "atlas" && opts.Style != "game" && opts.Style != "pheromone" && opts.Style != "biome" && opts.Style != "density" && opts.Style != "colony" {
-		return Result{}, fmt.Errorf("unknown render style %q: use flat, atlas, game, pheromone, biome, density, or colony", opts.Style)
-	}
-
-	atlas, err := loadAtlas(opts.AtlasPath)
-	if err != nil {
-		return Result{}, err
+	if opts.Style != "flat" && opts.Style != "atlas" && opts.Style != "game" && opts.Style != "pheromone" && opts.Style != "biome" && opts.Style != "density" && opts.Style != "colony" && opts.Style != "deaths" && opts.Style != "heatmap" && opts.Style != "territory" {
+		return opts, fmt.Errorf("unknown render style %q: use flat, atlas, game, pheromone, biome, density, colony, deaths, heatmap, or territory", opts.Style)
 	}
+	return opts, nil
+}
 
+func renderBoard(brd *core.Board, opts Options, atlas *image.RGBA) (*image.RGBA, error) {
 	tileSize := atlas.Bounds().Dy()
-	if tileSize <= 0 || atlas.Bounds().Dx()%tileSize != 0 {
-		return Result{}, fmt.Errorf("atlas must contain square tiles in a single row: %s", opts.AtlasPath)
-	}
-
+	opts = withTerritory(brd, opts)
 	boardW := core.Cols * opts.CellSize
 	boardH := core.Rows * opts.CellSize
 	legendH := 0
@@ -105,37 +143,21 @@ func SaveBoardPNG(brd *core.Board, opts Options) (Result, error) {
 		drawBorder(img, boardRect)
 	}
 	if opts.Legend {
-		drawLegend(img, opts.Padding, boardRect.Max.Y+opts.Padding, opts.CellSize, atlas, tileSize, opts.Style)
-	}
-
-	file, err := os.Create(opts.Output)
-	if err != nil {
-		return Result{}, err
+		drawLegend(img, opts.Padding, boardRect.Max.Y+opts.Padding, atlas, tileSize, opts)
 	}
-	defer file.Close()
-	if err := png.Encode(file, img); err != nil {
-		return Result{}, err
-	}
-
-	return Result{Output: opts.Output, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}, nil
+	return img, nil
 }
 
+// loadAtlas loads the atlas at path, or the assets atlas when path is empty.
 func loadAtlas(path string) (*image.RGBA, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	src, _, err := image.Decode(file)
-	if err != nil {
-		return nil, err
-	}
-
-	bounds := src.Bounds()
-	atlas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
-	draw.Draw(atlas, atlas.Bounds(), src, bounds.Min, draw.Src)
-	return atlas, nil
+	var atlas *image.RGBA
+	var err error
+	if path == "" {
+		atlas, _, err = assets.LoadAtlas()
+	} else {
+		atlas, _, err = assets.LoadAtlasFile(path)
+	}
+	return atlas, err
 }
 
 func drawBoard(dst *image.RGBA, rect image.Rectangle, brd *core.Board, atlas *image.RGBA, tileSize int, opts Options) {
@@ -148,24 +170,11 @@ func drawBoard(dst *image.RGBA, rect image.Rectangle, brd *core.Board, atlas *im
 					occupant = nil
 				}
 			}
-			tile, tint := visualFor(occupant)
-			if opts.Style == "pheromone" {
-				tile, tint = pheromoneVisual(brd.PheromoneAt(pos))
-			}
-			if opts.Style == "biome" {
-				tile, tint = biomeVisual(brd.At(pos), brd.BiomeAt(pos), tile, tint)
-			}
-			if opts.Style == "colony" {
-				tile, tint = colonyVisual(brd, pos, occupant, tile, tint)
-			}
-			if opts.Style != "pheromone" && opts.RenderPaths && brd.IsPathToRender(pos) {
-				tile, tint = tileLight, util.CyanColor()
-			}
-			if opts.Style != "pheromone" && opts.RenderTaskTargets && brd.IsTaskTargetToRender(pos) {
-				tile, tint = tileLight, util.PinkColor()
-			}
-			if opts.Style != "pheromone" && opts.RenderUnreachables && brd.IsUnreachableToRender(pos) {
-				tile, tint = tileLight, util.RedColor()
+			tile, tint := styledVisual(brd, pos, occupant, opts)
+			if opts.Style != "pheromone" {
+				if overlay, ok := taskOverlay(brd, pos, opts); ok {
+					tile, tint = tileLight, overlay
+				}
 			}
 			if brd.IsFrozen(pos) {
 				tile, tint = tileLight, frozenTint(tint)
@@ -181,6 +190,74 @@ func drawBoard(dst *image.RGBA, rect image.Rectangle, brd *core.Board, atlas *im
 	}
 }
 
+// styledVisual picks the tile and tint for occupant at pos under opts.Style,
+// before task overlays and the frozen tint.
+func styledVisual(brd *core.Board, pos core.Position, occupant core.Occupant, opts Options) (int, [3]float32) {
+	tile, tint := visualFor(occupant)
+	switch opts.Style {
+	case "pheromone":
+		tile, tint = pheromoneVisual(brd.PheromoneAt(pos))
+	case "biome":
+		tile, tint = biomeVisual(occupant, brd.BiomeAt(pos), tile, tint)
+	case "colony":
+		tile, tint = colonyVisual(brd, pos, occupant, tile, tint)
+	case "deaths":
+		tile, tint = deathVisual(brd.DeathAt(pos), tile, tint)
+	case "heatmap":
+		tile, tint = heatVisual(brd.HeatIntensity(opts.HeatLayer, pos))
+	case "territory":
+		tile, tint = territoryVisual(brd, pos, occupant, opts.territory, tile, tint)
+	}
+	return tile, tint
+}
+
+func withTerritory(brd *core.Board, opts Options) Options {
+	if opts.Style == "territory" && opts.territory == nil {
+		territory := brd.Territory()
+		opts.territory = &territory
+	}
+	return opts
+}
+
+// territoryVisual washes each owned cell in its colony's color, with white
+// lines where two colonies meet and a bright edge where a colony's reach ends.
+// Structures and bots keep their colony-mode colors on top.
+func territoryVisual(brd *core.Board, pos core.Position, o core.Occupant, territory *core.Territory, tile int, tint [3]float32) (int, [3]float32) {
+	switch o.(type) {
+	case nil, core.Water, core.Food, core.Resource, core.Poison:
+	default:
+		return colonyVisual(brd, pos, o, tile, tint)
+	}
+	owner := territory.OwnerAt(pos)
+	if owner == nil {
+		if o != nil {
+			return tile, lerpColor(clrGrey, tint, 0.18)
+		}
+		return tileDark, clrGrey
+	}
+	switch border, contested := territory.Border(pos); {
+	case contested:
+		return tileLight, clrWhite
+	case border:
+		return tileLight, lerpColor(owner.Color, clrWhite, 0.2)
+	}
+	return tileLight, lerpColor(clrGrey, owner.Color, 0.38)
+}
+
+// taskOverlay returns the highlight for debug task cells enabled in opts;
+// unreachable cells win over task targets, which win over paths.
+func taskOverlay(brd *core.Board, pos core.Position, opts Options) ([3]float32, bool) {
+	switch {
+	case opts.RenderUnreachables && brd.IsUnreachableToRender(pos):
+		return util.RedColor(), true
+	case opts.RenderTaskTargets && brd.IsTaskTargetToRender(pos):
+		return util.PinkColor(), true
+	case opts.RenderPaths && brd.IsPathToRender(pos):
+		return util.CyanColor(), true
+	}
+	return [3]float32{}, false
+}
+
 func frozenTint(tint [3]float32) [3]float32 {
 	return [3]float32{
 		(tint[0] + 0.35) / 2,
@@ -208,12 +285,8 @@ func visualFor(o core.Occupant) (int, [3]float32) {
 		return tileWall, clrWhite
 	case core.Controller:
 		return tileChest, clrWhite
-	case *core.Controller:
-		return tileChest, clrWhite
 	case core.Depot:
 		return tileChest, [3]float32{0.20, 0.95, 0.85}
-	case *core.Depot:
-		return tileChest, [3]float32{0.20, 0.95, 0.85}
 	case core.Mine:
 		return tileSpawner, clrWhite
 	case core.Resource:
@@ -239,16 +312,8 @@ func colonyVisual(brd *core.Board, pos core.Position, o core.Occupant, tile int,
 		return tileLight, [3]float32{0.02, 0.04, 0.10}
 	case core.Controller:
 		return tileChest, colonyStructureColor(v.Colony, tint)
-	case *core.Controller:
-		if v != nil {
-			return tileChest, colonyStructureColor(v.Colony, tint)
-		}
 	case core.Depot:
 		return tileChest, colonyStructureColor(v.Colony, tint)
-	case *core.Depot:
-		if v != nil {
-			return tileChest, colonyStructureColor(v.Colony, tint)
-		}
 	case core.Farm:
 		return tileFarm, colonyStructureColor(colonyForOwnedCell(v.Colony, v.Owner), tint)
 	case core.Spawner:
@@ -311,7 +376,7 @@ func colonyForOwner(owner *core.Bot) *core.Colony {
 }
 
 func drawCell(dst *image.RGBA, rect image.Rectangle, atlas *image.RGBA, tileSize, tile int, tint [3]float32, style string) {
-	if style == "pheromone" || style == "density" {
+	if style == "pheromone" || style == "density" || style == "deaths" || style == "heatmap" || style == "territory" {
 		draw.Draw(dst, rect, &image.Uniform{flatColor(tile, tint)}, image.Point{}, draw.Src)
 		return
 	}
@@ -420,6 +485,55 @@ func pheromoneColor(values core.PheromoneValues) [3]float32 {
 	}
 }
 
+// deathVisual paints the cause of the latest death in a cell over a dimmed
+// board, so clusters of each cause stand out.
+func deathVisual(cause core.DeathCause, tile int, tint [3]float32) (int, [3]float32) {
+	if cause == core.DeathNone {
+		return tile, lerpColor(tint, clrGrey, 0.8)
+	}
+	return tileLight, deathColor(cause)
+}
+
+func deathColor(cause core.DeathCause) [3]float32 {
+	switch cause {
+	case core.DeathAge:
+		return [3]float32{0.85, 0.85, 0.85}
+	case core.DeathStarvation:
+		return [3]float32{0.95, 0.65, 0.10}
+	case core.DeathPoison:
+		return [3]float32{0.62, 0.20, 0.85}
+	case core.DeathCombat:
+		return [3]float32{0.95, 0.12, 0.12}
+	case core.DeathCurse:
+		return [3]float32{0.20, 0.85, 0.95}
+	case core.DeathCrowding:
+		return [3]float32{0.30, 0.90, 0.25}
+	default:
+		return clrGrey
+	}
+}
+
+func heatVisual(intensity float32) (int, [3]float32) {
+	if intensity <= 0 {
+		return tileDark, clrGrey
+	}
+	return tileLight, heatColor(intensity)
+}
+
+// heatColor runs from deep purple through red and orange to pale yellow.
+func heatColor(intensity float32) [3]float32 {
+	stops := [...][3]float32{
+		{0.16, 0.04, 0.30},
+		{0.58, 0.10, 0.45},
+		{0.93, 0.30, 0.14},
+		{1.00, 0.72, 0.16},
+		{1.00, 1.00, 0.80},
+	}
+	scaled := clamp01(intensity) * float32(len(stops)-1)
+	i := min(int(scaled), len(stops)-2)
+	return lerpColor(stops[i], stops[i+1], scaled-float32(i))
+}
+
 func biomeVisual(o core.Occupant, biome core.Biome, tile int, tint [3]float32) (int, [3]float32) {
 	biomeTint := biomeColor(biome)
 	switch o.(type) {
@@ -552,8 +666,9 @@ func drawBorder(dst *image.RGBA, rect image.Rectangle) {
 	}
 }
 
-func drawLegend(dst *image.RGBA, x, y, cellSize int, atlas *image.RGBA, tileSize int, style string) {
-	size := max(14, cellSize*7)
+func drawLegend(dst *image.RGBA, x, y int, atlas *image.RGBA, tileSize int, opts Options) {
+	style := opts.Style
+	size := max(14, opts.CellSize*7)
 	gap := size + 18
 	if style == "pheromone" {
 		items := []core.PheromoneValues{
@@ -569,6 +684,24 @@ func drawLegend(dst *image.RGBA, x, y, cellSize int, atlas *image.RGBA, tileSize
 		}
 		return
 	}
+	if style == "heatmap" {
+		for i := range 5 {
+			x0 := x + i*gap
+			drawCell(dst, image.Rect(x0, y, x0+size, y+size), atlas, tileSize, tileLight, heatColor(float32(i+1)/5), "heatmap")
+		}
+		return
+	}
+	if style == "territory" {
+		drawTerritoryLegend(dst, x, y, core.Cols*opts.CellSize, size, opts.territory)
+		return
+	}
+	if style == "deaths" {
+		for i, cause := range core.DeathCauses() {
+			x0 := x + i*gap
+			drawCell(dst, image.Rect(x0, y, x0+size, y+size), atlas, tileSize, tileLight, deathColor(cause), "deaths")
+		}
+		return
+	}
 	if style == "biome" {
 		items := []struct {
 			tile int
@@ -608,6 +741,18 @@ func drawLegend(dst *image.RGBA, x, y, cellSize int, atlas *image.RGBA, tileSize
 	}
 }
 
+// drawTerritoryLegend draws one bar across the board width split between
+// colonies in proportion to their area, with unclaimed ground left dark.
+func drawTerritoryLegend(dst *image.RGBA, x, y, width, height int, territory *core.Territory) {
+	draw.Draw(dst, image.Rect(x, y, x+width, y+height), &image.Uniform{flatColor(tileDark, clrGrey)}, image.Point{}, draw.Src)
+	x0 := x
+	for i, colony := range territory.Colonies {
+		w := territory.Areas[i] * width / util.Cells
+		draw.Draw(dst, image.Rect(x0, y, x0+w, y+height), &image.Uniform{flatColor(tileLight, colony.Color)}, image.Point{}, draw.Src)
+		x0 += w
+	}
+}
+
 func setSafe(dst *image.RGBA, x, y int, c color.RGBA) {
 	if image.Pt(x, y).In(dst.Bounds()) {
 		dst.SetRGBA(x, y, c)
@@ -172,3 +172,122 @@ func sampleRenderedCell(img image.Image, pos util.Position, cellSize, padding in
 	r, g, b, a := img.At(x, y).RGBA()
 	return color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
 }
+
+func TestSaveBoardPNGDeathsStyleColorsCause(t *testing.T) {
+	brd := core.NewBoard()
+	pos := util.NewPos(12, 12)
+	brd.RecordDeath(util.Idx(pos), core.DeathCombat)
+	out := filepath.Join(t.TempDir(), "deaths.png")
+
+	if _, err := SaveBoardPNG(brd, Options{
+		AtlasPath: filepath.Join("..", "..", "assests", "sprites", "atlas.png"),
+		Output:    out,
+		Style:     "deaths",
+		CellSize:  3,
+		Legend:    true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := pos.C*3 + 1
+	y := (core.Rows-1-pos.R)*3 + 1
+	r, g, b, _ := img.At(x, y).RGBA()
+	if r <= g*2 || r <= b*2 {
+		t.Fatalf("combat death pixel rgb16 = %d/%d/%d, want red-dominant", r, g, b)
+	}
+}
+
+func TestSaveBoardPNGHeatmapStyleDrawsSelectedLayer(t *testing.T) {
+	brd := core.NewBoard()
+	brd.EnableHeat()
+	hot := util.NewPos(14, 14)
+	brd.AddHeat(core.HeatKills, util.Idx(hot), 3)
+	brd.AddHeat(core.HeatBirths, util.Idx(util.NewPos(20, 20)), 3)
+	out := filepath.Join(t.TempDir(), "heat.png")
+
+	if _, err := SaveBoardPNG(brd, Options{
+		AtlasPath: filepath.Join("..", "..", "assests", "sprites", "atlas.png"),
+		Output:    out,
+		Style:     "heatmap",
+		HeatLayer: core.HeatKills,
+		CellSize:  3,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(pos core.Position) color.RGBA {
+		return color.RGBAModel.Convert(img.At(pos.C*3+1, (core.Rows-1-pos.R)*3+1)).(color.RGBA)
+	}
+	if got, want := at(hot), flatColor(tileLight, heatColor(1)); got != want {
+		t.Fatalf("hottest kill cell = %v, want %v", got, want)
+	}
+	if got, want := at(util.NewPos(20, 20)), flatColor(tileDark, clrGrey); got != want {
+		t.Fatalf("birth cell on kill layer = %v, want empty %v", got, want)
+	}
+}
+
+func TestRenderBoardTerritoryStyleDrawsBordersAndAreaLegend(t *testing.T) {
+	brd := core.NewBoard()
+	a, b := core.NewColony(util.NewPos(100, 100)), core.NewColony(util.NewPos(100, 140))
+	brd.Set(a.Center, core.Controller{Pos: a.Center, Colony: &a})
+	brd.Set(b.Center, core.Controller{Pos: b.Center, Colony: &b})
+	territory := brd.Territory()
+
+	img, err := RenderBoard(brd, Options{
+		AtlasPath: filepath.Join("..", "..", "assests", "sprites", "atlas.png"),
+		Style:     "territory",
+		CellSize:  2,
+		Legend:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	}
+	cell := func(pos core.Position) color.RGBA {
+		return at(pos.C*2, (core.Rows-1-pos.R)*2)
+	}
+	if got, want := cell(util.NewPos(100, 110)), flatColor(tileLight, lerpColor(clrGrey, a.Color, 0.38)); got != want {
+		t.Fatalf("owned cell = %v, want %v", got, want)
+	}
+	if got, want := cell(util.NewPos(100, 120)), flatColor(tileLight, clrWhite); got != want {
+		t.Fatalf("contested border = %v, want %v", got, want)
+	}
+	if got, want := cell(util.NewPos(100, 68)), flatColor(tileLight, lerpColor(a.Color, clrWhite, 0.2)); got != want {
+		t.Fatalf("frontier = %v, want %v", got, want)
+	}
+	if got, want := cell(util.NewPos(300, 300)), flatColor(tileDark, clrGrey); got != want {
+		t.Fatalf("unclaimed cell = %v, want %v", got, want)
+	}
+
+	legendY := core.Rows*2 + 1
+	widthA := territory.Areas[0] * core.Cols * 2 / util.Cells
+	if got, want := at(0, legendY), flatColor(tileLight, a.Color); got != want {
+		t.Fatalf("legend start = %v, want first colony %v", got, want)
+	}
+	if got, want := at(widthA, legendY), flatColor(tileLight, b.Color); got != want {
+		t.Fatalf("legend after first colony's share = %v, want second colony %v", got, want)
+	}
+	if got, want := at(core.Cols*2-1, legendY), flatColor(tileDark, clrGrey); got != want {
+		t.Fatalf("legend end = %v, want unclaimed %v", got, want)
+	}
+}
@@ -0,0 +1,196 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+var (
+	diffOnlyA   = [3]float32{0.95, 0.32, 0.24}
+	diffOnlyB   = [3]float32{0.30, 0.85, 0.42}
+	diffChanged = [3]float32{1.00, 0.84, 0.20}
+)
+
+// CompareResult describes a comparison image. DiffCells counts cells whose
+// occupant kind differs between the two boards.
+type CompareResult struct {
+	Result
+	DiffCells int `json:"diff_cells"`
+}
+
+// SaveComparisonPNG renders a and b side by side, followed by a diff panel,
+// and writes the image to opts.Output.
+func SaveComparisonPNG(a, b *core.Board, opts Options) (CompareResult, error) {
+	opts, err := normalizeOptions(opts)
+	if err != nil {
+		return CompareResult{}, err
+	}
+	img, diff, err := renderComparison(a, b, opts)
+	if err != nil {
+		return CompareResult{}, err
+	}
+	file, err := os.Create(opts.Output)
+	if err != nil {
+		return CompareResult{}, err
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		return CompareResult{}, err
+	}
+	return CompareResult{
+		Result:    Result{Output: opts.Output, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()},
+		DiffCells: diff,
+	}, file.Close()
+}
+
+// RenderComparison draws three panels in one row: a, b, and a diff layer
+// that marks cells only a occupies, cells only b occupies, and cells both
+// occupy with different kinds. The legend row is shared: the style legend
+// sits under a (territory bars go under each board) and the diff key under
+// the diff panel.
+func RenderComparison(a, b *core.Board, opts Options) (*image.RGBA, int, error) {
+	opts, err := normalizeOptions(opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return renderComparison(a, b, opts)
+}
+
+func renderComparison(a, b *core.Board, opts Options) (*image.RGBA, int, error) {
+	atlas, err := loadAtlas(opts.AtlasPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	tileSize := atlas.Bounds().Dy()
+
+	boardW := core.Cols * opts.CellSize
+	boardH := core.Rows * opts.CellSize
+	gap := compareGap(opts)
+	legendH := 0
+	if opts.Legend {
+		legendH = opts.Padding + 24
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 3*boardW+2*gap+opts.Padding*2, boardH+opts.Padding*2+legendH))
+	draw.Draw(img, img.Bounds(), &image.Uniform{pageBackground}, image.Point{}, draw.Src)
+
+	panels := make([]image.Rectangle, 3)
+	for i := range panels {
+		x0 := opts.Padding + i*(boardW+gap)
+		panels[i] = image.Rect(x0, opts.Padding, x0+boardW, opts.Padding+boardH)
+	}
+	optsA, optsB := withTerritory(a, opts), withTerritory(b, opts)
+	drawBoard(img, panels[0], a, atlas, tileSize, optsA)
+	drawBoard(img, panels[1], b, atlas, tileSize, optsB)
+	diff := drawDiff(img, panels[2], a, b, opts.CellSize)
+	if opts.Border {
+		for _, panel := range panels {
+			drawBorder(img, panel)
+		}
+	}
+
+	if opts.Legend {
+		y := panels[0].Max.Y + opts.Padding
+		if opts.Style == "territory" {
+			size := max(14, opts.CellSize*7)
+			drawTerritoryLegend(img, panels[0].Min.X, y, boardW, size, optsA.territory)
+			drawTerritoryLegend(img, panels[1].Min.X, y, boardW, size, optsB.territory)
+		} else {
+			drawLegend(img, panels[0].Min.X, y, atlas, tileSize, optsA)
+		}
+		drawDiffLegend(img, panels[2].Min.X, y, opts.CellSize)
+	}
+	return img, diff, nil
+}
+
+// compareGap keeps panels visibly apart even without padding.
+func compareGap(opts Options) int {
+	return max(opts.Padding, 4*opts.CellSize)
+}
+
+func drawDiff(dst *image.RGBA, rect image.Rectangle, a, b *core.Board, cellSize int) int {
+	diff := 0
+	for row := 0; row < core.Rows; row++ {
+		for col := 0; col < core.Cols; col++ {
+			pos := util.Position{R: row, C: col}
+			tint, changed := diffTint(occupantKind(a.At(pos)), occupantKind(b.At(pos)))
+			tile := tileLight
+			if changed {
+				diff++
+			} else {
+				tile = tileDark
+			}
+			x0 := rect.Min.X + col*cellSize
+			y0 := rect.Min.Y + (core.Rows-1-row)*cellSize
+			draw.Draw(dst, image.Rect(x0, y0, x0+cellSize, y0+cellSize), &image.Uniform{flatColor(tile, tint)}, image.Point{}, draw.Src)
+		}
+	}
+	return diff
+}
+
+func diffTint(kindA, kindB string) ([3]float32, bool) {
+	switch {
+	case kindA == kindB:
+		return clrGrey, false
+	case kindB == "":
+		return diffOnlyA, true
+	case kindA == "":
+		return diffOnlyB, true
+	}
+	return diffChanged, true
+}
+
+func drawDiffLegend(dst *image.RGBA, x, y, cellSize int) {
+	size := max(14, cellSize*7)
+	gap := size + 18
+	for i, tint := range [][3]float32{diffOnlyA, diffOnlyB, diffChanged} {
+		x0 := x + i*gap
+		draw.Draw(dst, image.Rect(x0, y, x0+size, y+size), &image.Uniform{flatColor(tileLight, tint)}, image.Point{}, draw.Src)
+	}
+}
+
+// occupantKind names what occupies a cell, treating value and pointer forms
+// of the same structure alike; empty cells are "".
+func occupantKind(o core.Occupant) string {
+	switch v := o.(type) {
+	case *core.Bot:
+		if v == nil {
+			return ""
+		}
+		return "bot"
+	case core.Controller:
+		return "controller"
+	case core.Depot:
+		return "depot"
+	case core.Farm:
+		return "farm"
+	case core.Spawner:
+		return "spawner"
+	case core.Mine:
+		return "mine"
+	case core.ColonyFlag:
+		return "flag"
+	case core.Building:
+		return "building"
+	case core.Wall:
+		return "wall"
+	case core.Water:
+		return "water"
+	case core.Food:
+		return "food"
+	case core.Resource:
+		return "resource"
+	case core.Poison:
+		return "poison"
+	case core.Organics:
+		return "organics"
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%T", o)
+}
@@ -0,0 +1,62 @@
+package render
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+func TestRenderComparisonDrawsBothBoardsAndKindDiff(t *testing.T) {
+	a, b := core.NewBoard(), core.NewBoard()
+	same, onlyA, onlyB, changed := util.NewPos(10, 10), util.NewPos(20, 20), util.NewPos(30, 30), util.NewPos(40, 40)
+	a.Set(same, core.Poison{Pos: same})
+	b.Set(same, core.Poison{Pos: same})
+	a.Set(onlyA, core.Food{Pos: onlyA})
+	b.Set(onlyB, core.Food{Pos: onlyB})
+	a.Set(changed, core.Food{Pos: changed})
+	b.Set(changed, core.Poison{Pos: changed})
+
+	img, diff, err := RenderComparison(a, b, Options{
+		AtlasPath: filepath.Join("..", "..", "assests", "sprites", "atlas.png"),
+		Style:     "flat",
+		CellSize:  2,
+		Legend:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != 3 {
+		t.Fatalf("diff cells = %d, want 3", diff)
+	}
+	gap := compareGap(Options{CellSize: 2})
+	if got, want := img.Bounds().Dx(), 3*core.Cols*2+2*gap; got != want {
+		t.Fatalf("width = %d, want three panels %d", got, want)
+	}
+	at := func(panel int, pos core.Position) color.RGBA {
+		return color.RGBAModel.Convert(img.At(panel*(core.Cols*2+gap)+pos.C*2, (core.Rows-1-pos.R)*2)).(color.RGBA)
+	}
+
+	foodA, foodB := at(0, onlyA), at(1, onlyB)
+	if foodA != foodB || foodA == at(1, onlyA) {
+		t.Fatalf("food cells A=%v B=%v, want each board drawn in its own panel", foodA, foodB)
+	}
+	for _, tc := range []struct {
+		pos  core.Position
+		want color.RGBA
+	}{
+		{same, flatColor(tileDark, clrGrey)},
+		{onlyA, flatColor(tileLight, diffOnlyA)},
+		{onlyB, flatColor(tileLight, diffOnlyB)},
+		{changed, flatColor(tileLight, diffChanged)},
+	} {
+		if got := at(2, tc.pos); got != tc.want {
+			t.Fatalf("diff at %v = %v, want %v", tc.pos, got, tc.want)
+		}
+	}
+	if got, want := color.RGBAModel.Convert(img.At(2*(core.Cols*2+gap), core.Rows*2+1)).(color.RGBA), flatColor(tileLight, diffOnlyA); got != want {
+		t.Fatalf("diff legend = %v, want %v", got, want)
+	}
+}
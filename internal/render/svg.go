@@ -0,0 +1,234 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+type SVGOptions struct {
+	Options
+
+	// ColonyID labels colonies in data-colony attributes. When nil, colonies
+	// are numbered from 1 in board scan order.
+	ColonyID func(*core.Colony) int
+}
+
+// SaveBoardSVG writes brd as an SVG with one board cell per user unit, grouped
+// into terrain, structures, bots, pheromone and tasks layers. Structures and
+// bots carry data-* attributes; the pheromone layer is hidden unless the style
+// is pheromone. Legends are PNG-only.
+func SaveBoardSVG(brd *core.Board, opts SVGOptions) (Result, error) {
+	base, err := normalizeOptions(opts.Options)
+	if err != nil {
+		return Result{}, err
+	}
+	opts.Options = base
+	if opts.ColonyID == nil {
+		opts.ColonyID = scanOrderColonyIDs()
+	}
+
+	file, err := os.Create(opts.Output)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+	out := bufio.NewWriter(file)
+	result := writeBoardSVG(out, brd, opts)
+	if err := out.Flush(); err != nil {
+		return Result{}, err
+	}
+	return result, file.Close()
+}
+
+func scanOrderColonyIDs() func(*core.Colony) int {
+	ids := map[*core.Colony]int{}
+	return func(colony *core.Colony) int {
+		if colony == nil {
+			return 0
+		}
+		if id, ok := ids[colony]; ok {
+			return id
+		}
+		ids[colony] = len(ids) + 1
+		return ids[colony]
+	}
+}
+
+func writeBoardSVG(out io.Writer, brd *core.Board, opts SVGOptions) Result {
+	pad := float64(opts.Padding) / float64(opts.CellSize)
+	width := core.Cols*opts.CellSize + 2*opts.Padding
+	height := core.Rows*opts.CellSize + 2*opts.Padding
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="%s %s %s %s" shape-rendering="crispEdges" data-style="%s">`+"\n",
+		width, height, svgNum(-pad), svgNum(-pad), svgNum(core.Cols+2*pad), svgNum(core.Rows+2*pad), opts.Style)
+	fmt.Fprintf(out, `<rect x="%s" y="%s" width="100%%" height="100%%" fill="%s"/>`+"\n", svgNum(-pad), svgNum(-pad), svgColor(pageBackground))
+
+	// Pheromone style keeps the game colors underneath its overlay.
+	opts.Options = withTerritory(brd, opts.Options)
+	baseStyle := opts.Options
+	if baseStyle.Style == "pheromone" || baseStyle.Style == "density" {
+		baseStyle.Style = "flat"
+	}
+
+	fmt.Fprintf(out, `<g id="terrain" fill="%s">`+"\n", svgColor(flatColor(tileDark, clrGrey)))
+	fmt.Fprintf(out, `<rect width="%d" height="%d"/>`+"\n", core.Cols, core.Rows)
+	svgRuns(out, func(pos core.Position) (color.RGBA, bool) {
+		occupant := brd.At(pos)
+		if svgLayer(occupant) != "terrain" {
+			occupant = nil
+		}
+		tile, tint := styledVisual(brd, pos, occupant, baseStyle)
+		if brd.IsFrozen(pos) {
+			tile, tint = tileLight, frozenTint(tint)
+		}
+		c := svgCellColor(tile, tint)
+		return c, c != flatColor(tileDark, clrGrey)
+	})
+	fmt.Fprintln(out, "</g>")
+
+	layers := map[string][]string{}
+	for row := 0; row < core.Rows; row++ {
+		for col := 0; col < core.Cols; col++ {
+			pos := util.Position{R: row, C: col}
+			occupant := brd.At(pos)
+			layer := svgLayer(occupant)
+			if layer == "terrain" {
+				continue
+			}
+			tile, tint := styledVisual(brd, pos, occupant, baseStyle)
+			if brd.IsFrozen(pos) {
+				tile, tint = tileLight, frozenTint(tint)
+			}
+			layers[layer] = append(layers[layer], fmt.Sprintf(`<rect x="%d" y="%d" width="1" height="1" fill="%s"%s/>`,
+				col, core.Rows-1-row, svgColor(svgCellColor(tile, tint)), svgData(brd, pos, occupant, opts.ColonyID)))
+		}
+	}
+	for _, layer := range []string{"structures", "bots"} {
+		fmt.Fprintf(out, `<g id="%s">`+"\n", layer)
+		for _, rect := range layers[layer] {
+			fmt.Fprintln(out, rect)
+		}
+		fmt.Fprintln(out, "</g>")
+	}
+
+	display := ` display="none"`
+	if opts.Style == "pheromone" {
+		display = ""
+	}
+	fmt.Fprintf(out, `<g id="pheromone"%s>`+"\n", display)
+	svgRuns(out, func(pos core.Position) (color.RGBA, bool) {
+		values := brd.PheromoneAt(pos)
+		if values.IsZero() {
+			return color.RGBA{}, false
+		}
+		return svgCellColor(tileLight, pheromoneColor(values)), true
+	})
+	fmt.Fprintln(out, "</g>")
+
+	fmt.Fprintln(out, `<g id="tasks">`)
+	svgRuns(out, func(pos core.Position) (color.RGBA, bool) {
+		overlay, ok := taskOverlay(brd, pos, opts.Options)
+		return flatColor(tileLight, overlay), ok
+	})
+	fmt.Fprintln(out, "</g>")
+	if opts.Border {
+		fmt.Fprintf(out, `<rect x="0" y="0" width="%d" height="%d" fill="none" stroke="%s" stroke-width="%s"/>`+"\n",
+			core.Cols, core.Rows, svgColor(boardBorder), svgNum(2/float64(opts.CellSize)))
+	}
+	fmt.Fprintln(out, "</svg>")
+	return Result{Output: opts.Output, Width: width, Height: height}
+}
+
+// svgLayer sorts occupants into the terrain, structures and bots groups.
+func svgLayer(occupant core.Occupant) string {
+	switch occupant.(type) {
+	case *core.Bot:
+		return "bots"
+	case core.Building, core.Controller, core.Depot, core.Mine, core.Farm, core.Spawner, core.ColonyFlag:
+		return "structures"
+	}
+	return "terrain"
+}
+
+// svgRuns writes one rect per horizontal run of equally colored cells for
+// which cell reports ok, since terrain and overlays carry no per-cell data.
+func svgRuns(out io.Writer, cell func(core.Position) (color.RGBA, bool)) {
+	for row := 0; row < core.Rows; row++ {
+		start := -1
+		var run color.RGBA
+		flush := func(end int) {
+			if start >= 0 {
+				fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="1" fill="%s"/>`+"\n", start, core.Rows-1-row, end-start, svgColor(run))
+			}
+			start = -1
+		}
+		for col := 0; col < core.Cols; col++ {
+			c, ok := cell(util.Position{R: row, C: col})
+			if !ok || c != run {
+				flush(col)
+			}
+			if ok && start < 0 {
+				start, run = col, c
+			}
+		}
+		flush(core.Cols)
+	}
+}
+
+func svgData(brd *core.Board, pos core.Position, occupant core.Occupant, colonyID func(*core.Colony) int) string {
+	var colony *core.Colony
+	data := ""
+	switch v := occupant.(type) {
+	case *core.Bot:
+		colony = v.Colony
+		data = fmt.Sprintf(` data-kind="bot" data-bot="%d" data-hp="%d"`, brd.BotIDOf(v), v.Hp)
+	case core.Controller:
+		colony = v.Colony
+		data = ` data-kind="controller"`
+	case core.Depot:
+		colony = v.Colony
+		data = ` data-kind="depot"`
+	case core.Farm:
+		colony = colonyForOwnedCell(v.Colony, v.Owner)
+		data = ` data-kind="farm"`
+	case core.Spawner:
+		colony = colonyForOwner(v.Owner)
+		data = ` data-kind="spawner"`
+	case core.Mine:
+		data = ` data-kind="mine"`
+	case core.ColonyFlag:
+		colony = brd.PheromoneHomeOwnerAt(pos)
+		data = ` data-kind="flag"`
+	case core.Building:
+		data = ` data-kind="building"`
+	}
+	if id := colonyID(colony); id > 0 {
+		data += fmt.Sprintf(` data-colony="%d"`, id)
+	}
+	return data
+}
+
+// svgCellColor matches the flat PNG style, except that structures keep a
+// style tint such as their colony color instead of the generic stone grey.
+func svgCellColor(tile int, tint [3]float32) color.RGBA {
+	switch tile {
+	case tileWall, tileChest, tileSpawner, tileFarm, tileFlag:
+		if tint != clrWhite {
+			return color.RGBA{R: toByte(tint[0]), G: toByte(tint[1]), B: toByte(tint[2]), A: 255}
+		}
+	}
+	return flatColor(tile, tint)
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgNum[T int | float64](v T) string {
+	return fmt.Sprintf("%g", float64(v))
+}
@@ -0,0 +1,103 @@
+package render
+
+import (
+	"encoding/xml"
+	"golab/internal/core"
+	"golab/internal/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+type svgNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []svgNode  `xml:",any"`
+}
+
+func (n svgNode) attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func TestSaveBoardSVGGroupsLayersWithCellMetadata(t *testing.T) {
+	brd := core.NewBoard()
+	colony := &core.Colony{Color: [3]float32{0.9, 0.2, 0.1}}
+	botPos := util.NewPos(5, 7)
+	bot := core.NewBot(botPos)
+	bot.Hp = 321
+	bot.Colony = colony
+	brd.AddBot(botPos, &bot)
+	depotPos := util.NewPos(6, 7)
+	brd.Set(depotPos, core.Depot{Pos: depotPos, Colony: colony})
+	for col := 20; col < 24; col++ {
+		brd.Set(util.NewPos(8, col), core.Water{})
+	}
+	brd.DepositPheromone(util.NewPos(9, 9), core.PheromoneHome, 200, nil)
+	out := filepath.Join(t.TempDir(), "board.svg")
+
+	result, err := SaveBoardSVG(brd, SVGOptions{
+		Options:  Options{Output: out, CellSize: 3, Padding: 6, Style: "flat", Border: true},
+		ColonyID: func(c *core.Colony) int { return map[*core.Colony]int{colony: 7}[c] },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Width != core.Cols*3+12 || result.Height != core.Rows*3+12 {
+		t.Fatalf("svg size = %dx%d", result.Width, result.Height)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root svgNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	layers := map[string]svgNode{}
+	var order []string
+	for _, node := range root.Nodes {
+		if node.XMLName.Local == "g" {
+			layers[node.attr("id")] = node
+			order = append(order, node.attr("id"))
+		}
+	}
+	if want := []string{"terrain", "structures", "bots", "pheromone", "tasks"}; !slices.Equal(order, want) {
+		t.Fatalf("layers = %v, want %v", order, want)
+	}
+
+	bots := layers["bots"].Nodes
+	if len(bots) != 1 {
+		t.Fatalf("bots layer has %d rects, want 1", len(bots))
+	}
+	rect := bots[0]
+	if rect.attr("data-hp") != "321" || rect.attr("data-colony") != "7" || rect.attr("data-bot") != strconv.Itoa(int(brd.BotIDOf(&bot))) {
+		t.Fatalf("bot rect attrs = %+v", rect.Attrs)
+	}
+	if rect.attr("x") != "7" || rect.attr("y") != strconv.Itoa(core.Rows-1-5) {
+		t.Fatalf("bot rect at %s,%s, want flipped board coordinates", rect.attr("x"), rect.attr("y"))
+	}
+
+	structures := layers["structures"].Nodes
+	if len(structures) != 1 || structures[0].attr("data-kind") != "depot" || structures[0].attr("data-colony") != "7" {
+		t.Fatalf("structures layer = %+v", structures)
+	}
+
+	waterRun := false
+	for _, node := range layers["terrain"].Nodes {
+		waterRun = waterRun || (node.attr("x") == "20" && node.attr("width") == "4" && node.attr("y") == strconv.Itoa(core.Rows-1-8))
+	}
+	if !waterRun {
+		t.Fatal("terrain layer did not merge the water row into one run")
+	}
+	if pheromone := layers["pheromone"]; pheromone.attr("display") != "none" || len(pheromone.Nodes) == 0 {
+		t.Fatalf("pheromone layer display=%q rects=%d, want hidden overlay with scent", pheromone.attr("display"), len(pheromone.Nodes))
+	}
+}
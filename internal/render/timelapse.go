@@ -0,0 +1,382 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"golab/internal/core"
+)
+
+type TimelapseFormat string
+
+const (
+	TimelapseGIF    TimelapseFormat = "gif"
+	TimelapseAPNG   TimelapseFormat = "apng"
+	TimelapsePNGSeq TimelapseFormat = "png-seq"
+
+	defaultFrameDelay = 80 * time.Millisecond
+)
+
+type TimelapseOptions struct {
+	Options
+
+	Format TimelapseFormat
+	// FrameDelay is how long each frame stays on screen.
+	FrameDelay time.Duration
+	// MaxFrameSize caps the longer frame edge in pixels; 0 keeps full size.
+	MaxFrameSize int
+	// PaletteSize quantizes frames to at most this many colors. GIF always
+	// quantizes (256 when unset); APNG and PNG sequences stay truecolor at 0.
+	PaletteSize int
+}
+
+type TimelapseResult struct {
+	Output string          `json:"output"`
+	Format TimelapseFormat `json:"format"`
+	Frames int             `json:"frames"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+}
+
+// Timelapse renders board snapshots as they are captured and encodes them as
+// one animation, or writes them out as numbered PNGs for png-seq.
+type Timelapse struct {
+	opts    TimelapseOptions
+	atlas   *image.RGBA
+	palette color.Palette
+	gif     gif.GIF
+	apng    [][]byte
+	frames  int
+	width   int
+	height  int
+}
+
+func ParseTimelapseFormat(name string) (TimelapseFormat, error) {
+	switch TimelapseFormat(name) {
+	case TimelapseGIF, TimelapseAPNG, TimelapsePNGSeq:
+		return TimelapseFormat(name), nil
+	case "":
+		return TimelapseGIF, nil
+	}
+	return "", fmt.Errorf("unknown timelapse format %q: use gif, apng, or png-seq", name)
+}
+
+func NewTimelapse(opts TimelapseOptions) (*Timelapse, error) {
+	base, err := normalizeOptions(opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	opts.Options = base
+	if _, err := ParseTimelapseFormat(string(opts.Format)); err != nil {
+		return nil, err
+	}
+	if opts.Format == "" {
+		opts.Format = TimelapseGIF
+	}
+	if opts.FrameDelay <= 0 {
+		opts.FrameDelay = defaultFrameDelay
+	}
+	if opts.PaletteSize < 0 || opts.PaletteSize > 256 {
+		return nil, fmt.Errorf("palette size %d out of range: use 0-256", opts.PaletteSize)
+	}
+	if opts.Format == TimelapseGIF && opts.PaletteSize == 0 {
+		opts.PaletteSize = 256
+	}
+	if opts.Format == TimelapsePNGSeq {
+		if err := os.MkdirAll(opts.Output, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	atlas, err := loadAtlas(opts.AtlasPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Timelapse{opts: opts, atlas: atlas}, nil
+}
+
+func (t *Timelapse) Frames() int {
+	return t.frames
+}
+
+// Capture renders brd as the next frame.
+func (t *Timelapse) Capture(brd *core.Board) error {
+	rgba, err := renderBoard(brd, t.opts.Options, t.atlas)
+	if err != nil {
+		return err
+	}
+	var frame image.Image = capFrameSize(rgba, t.opts.MaxFrameSize)
+	t.width, t.height = frame.Bounds().Dx(), frame.Bounds().Dy()
+	defer func() { t.frames++ }()
+
+	switch t.opts.Format {
+	case TimelapseGIF:
+		paletted := quantize(frame, adaptivePalette(frame, t.opts.PaletteSize))
+		t.gif.Image = append(t.gif.Image, paletted)
+		t.gif.Delay = append(t.gif.Delay, max(1, int(t.opts.FrameDelay/(10*time.Millisecond))))
+		return nil
+	case TimelapseAPNG:
+		if t.opts.PaletteSize > 0 {
+			// APNG shares one PLTE across frames, so the first frame fixes it.
+			if t.palette == nil {
+				t.palette = adaptivePalette(frame, t.opts.PaletteSize)
+			}
+			frame = quantize(frame, t.palette)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, frame); err != nil {
+			return err
+		}
+		t.apng = append(t.apng, buf.Bytes())
+		return nil
+	default:
+		if t.opts.PaletteSize > 0 {
+			frame = quantize(frame, adaptivePalette(frame, t.opts.PaletteSize))
+		}
+		path := filepath.Join(t.opts.Output, fmt.Sprintf("frame-%05d.png", t.frames))
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return png.Encode(file, frame)
+	}
+}
+
+// Save encodes the captured frames. png-seq frames are already on disk.
+func (t *Timelapse) Save() (TimelapseResult, error) {
+	result := TimelapseResult{
+		Output: t.opts.Output,
+		Format: t.opts.Format,
+		Frames: t.frames,
+		Width:  t.width,
+		Height: t.height,
+	}
+	if t.frames == 0 {
+		return result, errors.New("timelapse has no frames")
+	}
+	if t.opts.Format == TimelapsePNGSeq {
+		return result, nil
+	}
+
+	file, err := os.Create(t.opts.Output)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+	if t.opts.Format == TimelapseGIF {
+		return result, gif.EncodeAll(file, &t.gif)
+	}
+	return result, writeAPNG(file, t.apng, t.opts.FrameDelay)
+}
+
+func capFrameSize(img *image.RGBA, maxSize int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if maxSize <= 0 || (w <= maxSize && h <= maxSize) {
+		return img
+	}
+	scale := float64(maxSize) / float64(max(w, h))
+	dw, dh := max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+	out := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy := y * h / dh
+		for x := 0; x < dw; x++ {
+			sx := x * w / dw
+			out.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return out
+}
+
+// adaptivePalette picks the most common colors after folding each channel to
+// five bits, averaging the exact colors that fell into each bucket.
+func adaptivePalette(img image.Image, size int) color.Palette {
+	type bucket struct {
+		key     uint16
+		count   int
+		r, g, b int
+	}
+	buckets := map[uint16]*bucket{}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			key := colorKey(c)
+			bk := buckets[key]
+			if bk == nil {
+				bk = &bucket{key: key}
+				buckets[key] = bk
+			}
+			bk.count++
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+		}
+	}
+	sorted := make([]*bucket, 0, len(buckets))
+	for _, bk := range buckets {
+		sorted = append(sorted, bk)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].key < sorted[j].key
+	})
+	if len(sorted) > size {
+		sorted = sorted[:size]
+	}
+	palette := make(color.Palette, 0, len(sorted))
+	for _, bk := range sorted {
+		palette = append(palette, color.RGBA{
+			R: uint8(bk.r / bk.count),
+			G: uint8(bk.g / bk.count),
+			B: uint8(bk.b / bk.count),
+			A: 255,
+		})
+	}
+	return palette
+}
+
+func quantize(img image.Image, palette color.Palette) *image.Paletted {
+	bounds := img.Bounds()
+	out := image.NewPaletted(bounds, palette)
+	nearest := map[uint16]uint8{}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			key := colorKey(c)
+			idx, ok := nearest[key]
+			if !ok {
+				idx = uint8(palette.Index(c))
+				nearest[key] = idx
+			}
+			out.SetColorIndex(x, y, idx)
+		}
+	}
+	return out
+}
+
+func colorKey(c color.RGBA) uint16 {
+	return uint16(c.R>>3)<<10 | uint16(c.G>>3)<<5 | uint16(c.B>>3)
+}
+
+// writeAPNG muxes independently encoded PNG frames into one animated PNG: the
+// first frame's IDAT stays the default image and later frames become fdAT.
+func writeAPNG(out io.Writer, frames [][]byte, delay time.Duration) error {
+	var header []pngChunk
+	var seq uint32
+	body := []pngChunk{}
+	for i, data := range frames {
+		chunks, err := readPNGChunks(data)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", i, err)
+		}
+		var ihdr []byte
+		var idat [][]byte
+		for _, chunk := range chunks {
+			switch chunk.kind {
+			case "IHDR":
+				ihdr = chunk.data
+			case "IDAT":
+				idat = append(idat, chunk.data)
+			case "IEND":
+			default:
+				if i == 0 {
+					header = append(header, chunk)
+				}
+			}
+		}
+		if i == 0 {
+			header = append([]pngChunk{{kind: "IHDR", data: ihdr}, {kind: "acTL", data: be32(uint32(len(frames)), 0)}}, header...)
+		} else if !bytes.Equal(ihdr, header[0].data) {
+			return fmt.Errorf("frame %d header differs from first frame", i)
+		}
+
+		width := binary.BigEndian.Uint32(ihdr[0:4])
+		height := binary.BigEndian.Uint32(ihdr[4:8])
+		fctl := append(be32(seq, width, height, 0, 0), be16(uint16(delay.Milliseconds()), 1000)...)
+		fctl = append(fctl, 0, 0)
+		body = append(body, pngChunk{kind: "fcTL", data: fctl})
+		seq++
+		for _, data := range idat {
+			if i == 0 {
+				body = append(body, pngChunk{kind: "IDAT", data: data})
+				continue
+			}
+			body = append(body, pngChunk{kind: "fdAT", data: append(be32(seq), data...)})
+			seq++
+		}
+	}
+
+	if _, err := out.Write([]byte("\x89PNG\r\n\x1a\n")); err != nil {
+		return err
+	}
+	for _, chunk := range append(append(header, body...), pngChunk{kind: "IEND"}) {
+		if err := chunk.write(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+func readPNGChunks(data []byte) ([]pngChunk, error) {
+	if len(data) < 8 || string(data[:8]) != "\x89PNG\r\n\x1a\n" {
+		return nil, errors.New("not a PNG stream")
+	}
+	chunks := []pngChunk{}
+	for rest := data[8:]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		n := int(binary.BigEndian.Uint32(rest[:4]))
+		if len(rest) < 12+n {
+			return nil, errors.New("truncated PNG chunk")
+		}
+		chunks = append(chunks, pngChunk{kind: string(rest[4:8]), data: rest[8 : 8+n]})
+		rest = rest[12+n:]
+	}
+	return chunks, nil
+}
+
+func (c pngChunk) write(out io.Writer) error {
+	buf := make([]byte, 0, 12+len(c.data))
+	buf = append(buf, be32(uint32(len(c.data)))...)
+	buf = append(buf, c.kind...)
+	buf = append(buf, c.data...)
+	buf = append(buf, be32(crc32.ChecksumIEEE(buf[4:]))...)
+	_, err := out.Write(buf)
+	return err
+}
+
+func be32(values ...uint32) []byte {
+	out := make([]byte, 0, 4*len(values))
+	for _, v := range values {
+		out = binary.BigEndian.AppendUint32(out, v)
+	}
+	return out
+}
+
+func be16(values ...uint16) []byte {
+	out := make([]byte, 0, 2*len(values))
+	for _, v := range values {
+		out = binary.BigEndian.AppendUint16(out, v)
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golab/internal/core"
+	"golab/internal/util"
+)
+
+func testTimelapseOptions(t *testing.T, format TimelapseFormat, output string) TimelapseOptions {
+	t.Helper()
+	return TimelapseOptions{
+		Options: Options{
+			AtlasPath: filepath.Join("..", "..", "assests", "sprites", "atlas.png"),
+			Output:    output,
+			Style:     "flat",
+			CellSize:  1,
+		},
+		Format:       format,
+		FrameDelay:   50 * time.Millisecond,
+		MaxFrameSize: 120,
+	}
+}
+
+func captureTestFrames(t *testing.T, timelapse *Timelapse, frames int) {
+	t.Helper()
+	brd := core.NewBoard()
+	for i := 0; i < frames; i++ {
+		pos := util.NewPos(10+i*5, 10+i*5)
+		brd.Set(pos, core.Wall{Pos: pos})
+		if err := timelapse.Capture(brd); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTimelapseGIFCapsFrameSizeAndPalette(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "run.gif")
+	opts := testTimelapseOptions(t, TimelapseGIF, out)
+	opts.PaletteSize = 8
+	timelapse, err := NewTimelapse(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	captureTestFrames(t, timelapse, 3)
+	result, err := timelapse.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Frames != 3 || max(result.Width, result.Height) != 120 {
+		t.Fatalf("timelapse result = %+v, want 3 frames capped to 120px", result)
+	}
+
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	anim, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 3 || anim.Delay[0] != 5 {
+		t.Fatalf("gif frames/delay = %d/%d, want 3/5", len(anim.Image), anim.Delay[0])
+	}
+	for i, frame := range anim.Image {
+		if len(frame.Palette) > 8 {
+			t.Fatalf("frame %d palette = %d colors, want at most 8", i, len(frame.Palette))
+		}
+	}
+}
+
+func TestTimelapseAPNGKeepsDefaultImageAndSequence(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "run.apng")
+	timelapse, err := NewTimelapse(testTimelapseOptions(t, TimelapseAPNG, out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	captureTestFrames(t, timelapse, 3)
+	if _, err := timelapse.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("default image does not decode as PNG: %v", err)
+	}
+	chunks, err := readPNGChunks(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	seq := uint32(0)
+	for _, chunk := range chunks {
+		kinds = append(kinds, chunk.kind)
+		switch chunk.kind {
+		case "acTL":
+			if frames := binary.BigEndian.Uint32(chunk.data); frames != 3 {
+				t.Fatalf("acTL frames = %d, want 3", frames)
+			}
+		case "fcTL", "fdAT":
+			if got := binary.BigEndian.Uint32(chunk.data); got != seq {
+				t.Fatalf("%s sequence = %d, want %d", chunk.kind, got, seq)
+			}
+			seq++
+		}
+	}
+	if kinds[0] != "IHDR" || kinds[1] != "acTL" || kinds[len(kinds)-1] != "IEND" {
+		t.Fatalf("chunk order = %v, want IHDR, acTL, ..., IEND", kinds)
+	}
+}
+
+func TestTimelapsePNGSequenceWritesNumberedFrames(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "frames")
+	timelapse, err := NewTimelapse(testTimelapseOptions(t, TimelapsePNGSeq, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	captureTestFrames(t, timelapse, 2)
+	if _, err := timelapse.Save(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"frame-00000.png", "frame-00001.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("missing %s: %v", name, err)
+		}
+	}
+}
@@ -46,17 +46,20 @@ func CalcPath(
 
 func CalcFlowField(sources []util.Position, brd *core.Board) []int16 {
 	dist := make([]int16, util.Cells)
+	fillFlowField(dist, make([]int32, 0, util.Cells), sourceCells(nil, sources), brd)
+	return dist
+}
+
+// fillFlowField writes into dist the walking distance from the nearest of
+// the source cells, math.MaxInt16 where none reaches, using q as its queue.
+func fillFlowField(dist []int16, q []int32, sources []int32, brd *core.Board) []int32 {
 	for i := range dist {
 		dist[i] = math.MaxInt16
 	}
-	q := make([]int32, 0, util.Cells)
-	for _, p := range sources {
-		if util.OutOfBounds(p) {
-			continue
-		}
-		sourceIdx := util.Idx(p)
+	q = q[:0]
+	for _, sourceIdx := range sources {
 		dist[sourceIdx] = 0
-		q = append(q, int32(sourceIdx))
+		q = append(q, sourceIdx)
 	}
 	head := 0
 	for head < len(q) {
@@ -73,7 +76,97 @@ func CalcFlowField(sources []util.Position, brd *core.Board) []int16 {
 			}
 		}
 	}
-	return dist
+	return q
+}
+
+// sourceCells appends the cells of the in-bounds sources to dst, sorted and
+// without repeats. A flow field does not depend on the order of its sources.
+func sourceCells(dst []int32, sources []util.Position) []int32 {
+	for _, p := range sources {
+		if !util.OutOfBounds(p) {
+			dst = append(dst, int32(util.Idx(p)))
+		}
+	}
+	slices.Sort(dst)
+	return slices.Compact(dst)
+}
+
+// flowFieldCacheSize bounds how many fields FlowFieldCache keeps for one
+// board version.
+const flowFieldCacheSize = 16
+
+// FlowFieldCache shares flow fields between callers asking for the same
+// sources while the board's walkable cells stay the same. Fields it returns
+// are shared and must not be written to.
+type FlowFieldCache struct {
+	brd     *core.Board
+	version uint64
+	entries []flowFieldEntry
+	key     []int32
+	queue   []int32
+}
+
+type flowFieldEntry struct {
+	sources []int32
+	field   []int16
+}
+
+func NewFlowFieldCache(brd *core.Board) *FlowFieldCache {
+	return &FlowFieldCache{brd: brd}
+}
+
+// Field returns CalcFlowField(sources, brd), computing it only when no field
+// for the same source cells was built since the last change to the board's
+// walkable cells.
+func (c *FlowFieldCache) Field(sources []util.Position) []int16 {
+	if version := c.brd.PassableVersion(); version != c.version {
+		clear(c.entries)
+		c.entries = c.entries[:0]
+		c.version = version
+	}
+	c.key = sourceCells(c.key[:0], sources)
+	for n, entry := range c.entries {
+		if slices.Equal(entry.sources, c.key) {
+			copy(c.entries[n:], c.entries[n+1:])
+			c.entries[len(c.entries)-1] = entry
+			return entry.field
+		}
+	}
+	field := make([]int16, util.Cells)
+	c.queue = fillFlowField(field, c.queue, c.key, c.brd)
+	if len(c.entries) == flowFieldCacheSize {
+		copy(c.entries, c.entries[1:])
+		c.entries = c.entries[:len(c.entries)-1]
+	}
+	c.entries = append(c.entries, flowFieldEntry{sources: slices.Clone(c.key), field: field})
+	return field
+}
+
+// Planner is the path graph and flow-field cache of one board, shared by
+// every colony routing on it.
+type Planner struct {
+	Paths *PathGraph
+	Flows *FlowFieldCache
+}
+
+func NewPlanner(brd *core.Board) *Planner {
+	return &Planner{Paths: NewPathGraph(brd), Flows: NewFlowFieldCache(brd)}
+}
+
+// Board returns the board the planner routes on.
+func (p *Planner) Board() *core.Board {
+	return p.Paths.brd
+}
+
+// CalcPath is the package CalcPath routed through the path graph, which
+// only considers empty and bot cells walkable.
+func (p *Planner) CalcPath(botPos, targetPos util.Position) []util.Position {
+	path := p.Paths.FindPath(botPos, targetPos)
+	if len(path) != 0 {
+		assert.Assert(path[0] != botPos, "Current pos in path")
+		assert.Assert(path[len(path)-1] == targetPos, "No target in path")
+	}
+	return path
 }
 
 func crossNeighborIndices(i int) [4]int {
@@ -2,6 +2,8 @@ package tasking
 
 import (
 	"math"
+	"math/rand"
+	"slices"
 	"testing"
 
 	"golab/internal/core"
@@ -51,6 +53,115 @@ func TestCalcFlowFieldTreatsBotsAsPassable(t *testing.T) {
 	}
 }
 
+func TestPathGraphFindsValidPathsWhereAStarDoes(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	brd := core.NewBoard()
+	for range util.Cells / 4 {
+		pos := util.NewPos(1+rng.Intn(util.Rows-2), rng.Intn(util.Cols))
+		brd.Set(pos, core.Wall{Pos: pos})
+	}
+	graph := NewPathGraph(brd)
+
+	for n := range 200 {
+		start := util.NewPos(1+rng.Intn(util.Rows-2), rng.Intn(util.Cols))
+		end := start.AddRowCol(rng.Intn(121)-60, rng.Intn(121)-60)
+		if util.OutOfBounds(end) || start == end {
+			continue
+		}
+		want := findPath(start, end, brd.IsEmptyOrBot)
+		got := graph.FindPath(start, end)
+		if (len(got) == 0) != (len(want) == 0) {
+			t.Fatalf("pair %d %v -> %v: graph path length %d, A* length %d", n, start, end, len(got), len(want))
+		}
+		if len(got) == 0 {
+			continue
+		}
+		if len(got) < len(want) {
+			t.Fatalf("pair %d: graph path length %d is shorter than A* %d", n, len(got), len(want))
+		}
+		assertWalkablePath(t, brd, start, end, got)
+	}
+}
+
+func TestPathGraphRebuildsChunksWhoseCellsChange(t *testing.T) {
+	brd := core.NewBoard()
+	graph := NewPathGraph(brd)
+	start := util.NewPos(50, 10)
+	end := util.NewPos(50, 90)
+	if path := graph.FindPath(start, end); len(path) != 80 {
+		t.Fatalf("open board path length = %d, want 80", len(path))
+	}
+
+	for r := 1; r < util.Rows-1; r++ {
+		for _, c := range []int{50, util.Cols - 50} {
+			pos := util.NewPos(r, c)
+			brd.Set(pos, core.Wall{Pos: pos})
+		}
+	}
+	if path := graph.FindPath(start, end); path != nil {
+		t.Fatalf("path through walled column = %v, want nil", path)
+	}
+
+	gap := util.NewPos(120, 50)
+	brd.Set(gap, nil)
+	path := graph.FindPath(start, end)
+	if len(path) == 0 {
+		t.Fatal("no path through the reopened gap")
+	}
+	assertWalkablePath(t, brd, start, end, path)
+	if !slices.Contains(path, gap) {
+		t.Fatalf("path does not pass the only gap %v", gap)
+	}
+}
+
+func assertWalkablePath(t *testing.T, brd *core.Board, start, end util.Position, path []util.Position) {
+	t.Helper()
+	if path[len(path)-1] != end {
+		t.Fatalf("path ends at %v, want %v", path[len(path)-1], end)
+	}
+	prev := start
+	for i, pos := range path {
+		if manhattanWrapped(util.Idx(prev), util.Idx(pos)) != 1 {
+			t.Fatalf("step %d from %v to %v is not a cross step", i, prev, pos)
+		}
+		if pos != end && !brd.IsEmptyOrBot(pos) {
+			t.Fatalf("step %d enters blocked cell %v", i, pos)
+		}
+		prev = pos
+	}
+}
+
+func TestFlowFieldCacheSharesFieldsUntilWalkableCellsChange(t *testing.T) {
+	brd := core.NewBoard()
+	cache := NewFlowFieldCache(brd)
+	sources := []util.Position{util.NewPos(10, 10), util.NewPos(30, 30)}
+
+	first := cache.Field(sources)
+	if !slices.Equal(first, CalcFlowField(sources, brd)) {
+		t.Fatal("cached field differs from CalcFlowField")
+	}
+	if again := cache.Field([]util.Position{sources[1], sources[0], sources[1]}); &again[0] != &first[0] {
+		t.Fatal("same source set in another order was recomputed")
+	}
+
+	botPos := util.NewPos(11, 10)
+	bot := core.NewBot(botPos)
+	brd.AddBot(botPos, &bot)
+	if again := cache.Field(sources); &again[0] != &first[0] {
+		t.Fatal("adding a bot invalidated the field")
+	}
+
+	wallPos := util.NewPos(10, 11)
+	brd.Set(wallPos, core.Wall{Pos: wallPos})
+	fresh := cache.Field(sources)
+	if &fresh[0] == &first[0] {
+		t.Fatal("field was not recomputed after a wall was placed")
+	}
+	if first[util.Idx(wallPos)] != 1 || fresh[util.Idx(wallPos)] != math.MaxInt16 {
+		t.Fatalf("wall cell distance before/after = %d/%d, want 1/%d", first[util.Idx(wallPos)], fresh[util.Idx(wallPos)], math.MaxInt16)
+	}
+}
+
 func BenchmarkCalcPath(b *testing.B) {
 	start := util.NewPos(10, 10)
 	end := util.NewPos(util.Rows-10, util.Cols-10)
@@ -83,3 +194,19 @@ func BenchmarkCalcFlowField(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkPathGraphFindPath(b *testing.B) {
+	brd := core.NewBoard()
+	graph := NewPathGraph(brd)
+	start := util.NewPos(10, 10)
+	end := util.NewPos(util.Rows-10, util.Cols-10)
+	graph.FindPath(start, end)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if path := graph.FindPath(start, end); len(path) == 0 {
+			b.Fatal("expected path")
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package tasking
+
+import (
+	"golab/internal/core"
+	"golab/internal/util"
+	"slices"
+)
+
+// PathGraph is an HPA*-style abstraction of the board's walkable cells. The
+// board is cut into core.ChunkSize chunks; every maximal run of walkable cell
+// pairs across a chunk border becomes one portal pair in the middle of the
+// run, and the portals of a chunk are linked by their walking distance inside
+// it. A chunk is rebuilt, with the neighbours it shares portals with, only
+// when its passable version moves, so bots walking around cost nothing.
+type PathGraph struct {
+	brd    *core.Board
+	chunks []chunkGraph
+	stale  []bool
+
+	crossings  []portalCrossing
+	local      []int32
+	prev       []int32
+	queue      []int32
+	startEdges []pathEdge
+	endEdges   []pathEdge
+	open       pathHeap
+	g          map[int32]int32
+	from       map[int32]int32
+}
+
+type chunkGraph struct {
+	built   bool
+	version uint32
+	// nodes are the chunk's portal cells in ascending order; edges[n] leave
+	// nodes[n], first across the border, then inside the chunk.
+	nodes []int32
+	edges [][]pathEdge
+}
+
+type pathEdge struct {
+	to   int32
+	cost int32
+}
+
+type portalCrossing struct {
+	cell, partner int32
+}
+
+func NewPathGraph(brd *core.Board) *PathGraph {
+	return &PathGraph{
+		brd:    brd,
+		chunks: make([]chunkGraph, core.Chunks),
+		stale:  make([]bool, core.Chunks),
+		local:  make([]int32, core.ChunkSize*core.ChunkSize),
+		prev:   make([]int32, core.ChunkSize*core.ChunkSize),
+		g:      map[int32]int32{},
+		from:   map[int32]int32{},
+	}
+}
+
+// FindPath returns a walkable path from start to end the way CalcPath does:
+// without start, ending on end, stepping in the four cross directions, and
+// entering only empty or bot cells apart from end itself. The path is
+// shortest between portals rather than overall. It returns nil when end
+// cannot be reached.
+func (pg *PathGraph) FindPath(start, end Position) []Position {
+	if start == end || !core.Inside(start) || !core.Inside(end) {
+		return nil
+	}
+	pg.refresh()
+	s, e := util.Idx(start), util.Idx(end)
+	sc, ec := core.ChunkOf(s), core.ChunkOf(e)
+
+	pg.searchChunk(sc, s, e)
+	pg.startEdges = pg.appendReachedPortals(pg.startEdges[:0], sc)
+	if sc == ec {
+		if d := pg.local[pg.localIdx(sc, e)]; d >= 0 {
+			pg.startEdges = append(pg.startEdges, pathEdge{to: int32(e), cost: d})
+		}
+	}
+	pg.searchChunk(ec, e, -1)
+	pg.endEdges = pg.appendReachedPortals(pg.endEdges[:0], ec)
+
+	hops := pg.searchAbstract(int32(s), int32(e))
+	if len(hops) == 0 {
+		return nil
+	}
+	path := make([]Position, 0, pg.g[int32(e)])
+	for k := 1; k < len(hops); k++ {
+		u, v := int(hops[k-1]), int(hops[k])
+		if core.ChunkOf(u) != core.ChunkOf(v) {
+			path = append(path, util.PosOf(v))
+			continue
+		}
+		path = pg.appendChunkPath(path, u, v)
+	}
+	return path
+}
+
+// searchAbstract runs A* over the portals from s to e and returns the cells
+// it hops through, s first.
+func (pg *PathGraph) searchAbstract(s, e int32) []int32 {
+	clear(pg.g)
+	clear(pg.from)
+	pg.open = pg.open[:0]
+	pg.g[s] = 0
+	pg.open.push(pathNode{f: manhattanWrapped(int(s), int(e)), cell: s})
+	for len(pg.open) > 0 {
+		cur := pg.open.pop()
+		if cur.g != pg.g[cur.cell] {
+			continue
+		}
+		if cur.cell == e {
+			hops := []int32{e}
+			for cell := e; cell != s; {
+				cell = pg.from[cell]
+				hops = append(hops, cell)
+			}
+			slices.Reverse(hops)
+			return hops
+		}
+		relax := func(edge pathEdge) {
+			g := cur.g + edge.cost
+			if old, ok := pg.g[edge.to]; ok && old <= g {
+				return
+			}
+			pg.g[edge.to] = g
+			pg.from[edge.to] = cur.cell
+			pg.open.push(pathNode{f: g + manhattanWrapped(int(edge.to), int(e)), g: g, cell: edge.to})
+		}
+		if cur.cell == s {
+			for _, edge := range pg.startEdges {
+				relax(edge)
+			}
+		}
+		chunk := &pg.chunks[core.ChunkOf(int(cur.cell))]
+		if n, ok := slices.BinarySearch(chunk.nodes, cur.cell); ok {
+			for _, edge := range chunk.edges[n] {
+				relax(edge)
+			}
+		}
+		for _, edge := range pg.endEdges {
+			if edge.to == cur.cell {
+				relax(pathEdge{to: e, cost: edge.cost})
+			}
+		}
+	}
+	return nil
+}
+
+func (pg *PathGraph) refresh() {
+	for ch := range pg.chunks {
+		chunk := &pg.chunks[ch]
+		if chunk.built && chunk.version == pg.brd.ChunkPassableVersion(ch) {
+			continue
+		}
+		pg.stale[ch] = true
+		for _, n := range chunkNeighbours(ch) {
+			if n >= 0 {
+				pg.stale[n] = true
+			}
+		}
+	}
+	for ch, stale := range pg.stale {
+		if stale {
+			pg.rebuild(ch)
+			pg.stale[ch] = false
+		}
+	}
+}
+
+func (pg *PathGraph) rebuild(ch int) {
+	r0, c0, h, w := chunkBounds(ch)
+	pg.crossings = pg.crossings[:0]
+	if r0 > 0 {
+		pg.addCrossings(w, func(k int) (int, int) {
+			return r0*util.Cols + c0 + k, (r0-1)*util.Cols + c0 + k
+		})
+	}
+	if r0+h < util.Rows {
+		pg.addCrossings(w, func(k int) (int, int) {
+			return (r0+h-1)*util.Cols + c0 + k, (r0+h)*util.Cols + c0 + k
+		})
+	}
+	pg.addCrossings(h, func(k int) (int, int) {
+		row := (r0 + k) * util.Cols
+		return row + c0, row + (c0-1+util.Cols)%util.Cols
+	})
+	pg.addCrossings(h, func(k int) (int, int) {
+		row := (r0 + k) * util.Cols
+		return row + c0 + w - 1, row + (c0+w)%util.Cols
+	})
+	slices.SortFunc(pg.crossings, func(a, b portalCrossing) int {
+		if a.cell != b.cell {
+			return int(a.cell - b.cell)
+		}
+		return int(a.partner - b.partner)
+	})
+
+	chunk := &pg.chunks[ch]
+	chunk.nodes = chunk.nodes[:0]
+	for _, x := range pg.crossings {
+		if n := len(chunk.nodes); n == 0 || chunk.nodes[n-1] != x.cell {
+			chunk.nodes = append(chunk.nodes, x.cell)
+		}
+	}
+	chunk.edges = slices.Grow(chunk.edges[:0], len(chunk.nodes))[:len(chunk.nodes)]
+	for n := range chunk.edges {
+		chunk.edges[n] = chunk.edges[n][:0]
+	}
+	for _, x := range pg.crossings {
+		n, _ := slices.BinarySearch(chunk.nodes, x.cell)
+		chunk.edges[n] = append(chunk.edges[n], pathEdge{to: x.partner, cost: 1})
+	}
+	for n, cell := range chunk.nodes {
+		pg.searchChunk(ch, int(cell), -1)
+		for m, other := range chunk.nodes {
+			if d := pg.local[pg.localIdx(ch, int(other))]; m != n && d >= 0 {
+				chunk.edges[n] = append(chunk.edges[n], pathEdge{to: other, cost: d})
+			}
+		}
+	}
+	chunk.built = true
+	chunk.version = pg.brd.ChunkPassableVersion(ch)
+}
+
+// addCrossings walks one side of a chunk, n cells long, where cells(k)
+// returns the k-th cell inside and its neighbour across the border, and adds
+// a crossing in the middle of every run where both are walkable. Both chunks
+// of a border walk it in the same order, so they agree on the crossings.
+func (pg *PathGraph) addCrossings(n int, cells func(k int) (int, int)) {
+	run := 0
+	for k := 0; k <= n; k++ {
+		if k < n {
+			inside, outside := cells(k)
+			if pg.brd.IsEmptyOrBotIdx(inside) && pg.brd.IsEmptyOrBotIdx(outside) {
+				run++
+				continue
+			}
+		}
+		if run > 0 {
+			inside, outside := cells(k - run + run/2)
+			pg.crossings = append(pg.crossings, portalCrossing{cell: int32(inside), partner: int32(outside)})
+			run = 0
+		}
+	}
+}
+
+// appendReachedPortals appends an edge to every portal of chunk ch that the
+// last searchChunk reached.
+func (pg *PathGraph) appendReachedPortals(dst []pathEdge, ch int) []pathEdge {
+	for _, cell := range pg.chunks[ch].nodes {
+		if d := pg.local[pg.localIdx(ch, int(cell))]; d >= 0 {
+			dst = append(dst, pathEdge{to: cell, cost: d})
+		}
+	}
+	return dst
+}
+
+// appendChunkPath appends the cells after from up to and including to,
+// walking inside their shared chunk.
+func (pg *PathGraph) appendChunkPath(path []Position, from, to int) []Position {
+	ch := core.ChunkOf(from)
+	pg.searchChunk(ch, from, to)
+	r0, c0, _, w := chunkBounds(ch)
+	first := len(path)
+	for k, start := pg.localIdx(ch, to), pg.localIdx(ch, from); k != start; k = int(pg.prev[k]) {
+		path = append(path, util.NewPos(r0+k/w, c0+k%w))
+	}
+	slices.Reverse(path[first:])
+	return path
+}
+
+// searchChunk fills local with the walking distance from cell from to every
+// cell of chunk ch, -1 where unreachable inside the chunk, and prev with the
+// step each came from. from is always a source and target, when not -1, can
+// be entered even if it is not walkable.
+func (pg *PathGraph) searchChunk(ch, from, target int) {
+	r0, c0, h, w := chunkBounds(ch)
+	for k := range pg.local {
+		pg.local[k] = -1
+	}
+	start := pg.localIdx(ch, from)
+	pg.local[start] = 0
+	pg.queue = append(pg.queue[:0], int32(start))
+	for head := 0; head < len(pg.queue); head++ {
+		k := int(pg.queue[head])
+		r, c := k/w, k%w
+		if k != start && (r0+r)*util.Cols+c0+c == target {
+			continue
+		}
+		for _, d := range util.PosCross {
+			nr, nc := r+d[0], c+d[1]
+			if nr < 0 || nr >= h || nc < 0 || nc >= w {
+				continue
+			}
+			next := nr*w + nc
+			if pg.local[next] >= 0 {
+				continue
+			}
+			if cell := (r0+nr)*util.Cols + c0 + nc; cell != target && !pg.brd.IsEmptyOrBotIdx(cell) {
+				continue
+			}
+			pg.local[next] = pg.local[k] + 1
+			pg.prev[next] = int32(k)
+			pg.queue = append(pg.queue, int32(next))
+		}
+	}
+}
+
+func (pg *PathGraph) localIdx(ch, cell int) int {
+	r0, c0, _, w := chunkBounds(ch)
+	return (cell/util.Cols-r0)*w + cell%util.Cols - c0
+}
+
+// chunkBounds returns the first row and column of chunk ch and its height
+// and width.
+func chunkBounds(ch int) (r0, c0, h, w int) {
+	r0 = ch / core.ChunkCols * core.ChunkSize
+	c0 = ch % core.ChunkCols * core.ChunkSize
+	return r0, c0, min(core.ChunkSize, util.Rows-r0), min(core.ChunkSize, util.Cols-c0)
+}
+
+// chunkNeighbours returns the chunks above, below, left and right of ch,
+// wrapping columns, with -1 past the top and bottom rows.
+func chunkNeighbours(ch int) [4]int {
+	cr, cc := ch/core.ChunkCols, ch%core.ChunkCols
+	up, down := -1, -1
+	if cr > 0 {
+		up = ch - core.ChunkCols
+	}
+	if cr < core.ChunkRows-1 {
+		down = ch + core.ChunkCols
+	}
+	left := cr*core.ChunkCols + (cc+core.ChunkCols-1)%core.ChunkCols
+	right := cr*core.ChunkCols + (cc+1)%core.ChunkCols
+	return [4]int{up, down, left, right}
+}
+
+func manhattanWrapped(a, b int) int32 {
+	dc := util.Abs(a%util.Cols - b%util.Cols)
+	if wrapped := util.Cols - dc; wrapped < dc {
+		dc = wrapped
+	}
+	return int32(util.Abs(a/util.Cols-b/util.Cols) + dc)
+}
+
+type pathNode struct {
+	f, g, cell int32
+}
+
+// pathHeap is a binary min-heap of A* nodes ordered by f, then by cell so
+// equal estimates pop in a fixed order.
+type pathHeap []pathNode
+
+func (h pathHeap) less(i, j int) bool {
+	if h[i].f != h[j].f {
+		return h[i].f < h[j].f
+	}
+	return h[i].cell < h[j].cell
+}
+
+func (h *pathHeap) push(n pathNode) {
+	*h = append(*h, n)
+	q := *h
+	for i := len(q) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !q.less(i, parent) {
+			break
+		}
+		q[i], q[parent] = q[parent], q[i]
+		i = parent
+	}
+}
+
+func (h *pathHeap) pop() pathNode {
+	q := *h
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q = q[:last]
+	for i := 0; ; {
+		child := 2*i + 1
+		if child >= len(q) {
+			break
+		}
+		if child+1 < len(q) && q.less(child+1, child) {
+			child++
+		}
+		if !q.less(child, i) {
+			break
+		}
+		q[i], q[child] = q[child], q[i]
+		i = child
+	}
+	*h = q
+	return top
+}
@@ -10,7 +10,9 @@ import (
 	"time"
 )
 
-func ProcessColonyTasks(ctrl *core.Controller, brd *core.Board) {
+// ProcessColonyTasks routes the colony's water connection through planner,
+// which must belong to brd, and hands out its maintenance tasks.
+func ProcessColonyTasks(ctrl *core.Controller, brd *core.Board, planner *Planner) {
 	c := ctrl.Colony
 	now := time.Now()
 
@@ -27,7 +29,7 @@ func ProcessColonyTasks(ctrl *core.Controller, brd *core.Board) {
 			return
 		}
 
-		path := CalcPath(ctrl.Pos, task.Pos, brd.IsEmptyOrBot, nil)
+		path := planner.CalcPath(ctrl.Pos, task.Pos)
 		pathLen := len(path)
 		if pathLen == 0 {
 			return
@@ -38,7 +40,7 @@ func ProcessColonyTasks(ctrl *core.Controller, brd *core.Board) {
 		if len(c.PathToWater) == 0 {
 			continue
 		}
-		c.WaterPathFlowField = CalcFlowField(c.PathToWater, brd)
+		c.WaterPathFlowField = planner.Flows.Field(c.PathToWater)
 		for _, pos := range c.PathToWater {
 			c.Tasks = append(c.Tasks, c.NewMaintainConnectionTask(pos, &c.WaterPathFlowField))
 		}
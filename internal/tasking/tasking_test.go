@@ -15,8 +15,9 @@ func TestProcessColonyTasksSkipsFlowFieldWithoutWaterPath(t *testing.T) {
 		Colony: &colony,
 	}
 
+	planner := NewPlanner(brd)
 	for range 10 {
-		ProcessColonyTasks(&ctrl, brd)
+		ProcessColonyTasks(&ctrl, brd, planner)
 	}
 
 	if colony.WaterPathFlowField != nil {
@@ -35,8 +36,9 @@ func TestProcessColonyTasksDoesNotRecomputeStableWaterFlowField(t *testing.T) {
 		Colony: &colony,
 	}
 
+	planner := NewPlanner(brd)
 	for range 10 {
-		ProcessColonyTasks(&ctrl, brd)
+		ProcessColonyTasks(&ctrl, brd, planner)
 	}
 
 	if len(colony.WaterPathFlowField) != 1 {
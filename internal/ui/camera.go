@@ -0,0 +1,260 @@
+package ui
+
+import (
+	"fmt"
+	"golab/internal/core"
+	"sort"
+)
+
+const (
+	cameraBookmarkCount = 9
+	minimapCellPx       = 0.5
+	minimapMargin       = 12
+	minimapHeader       = 30
+)
+
+type followKind int
+
+const (
+	followNone followKind = iota
+	followBot
+	followColony
+)
+
+// cameraFollow is what the camera tracks between frames. Bots are tracked by
+// ID so the camera keeps up with moves and lets go when the bot dies.
+type cameraFollow struct {
+	Kind   followKind
+	Bot    core.BotID
+	Colony *core.Colony
+}
+
+type cameraBookmark struct {
+	Set      bool
+	X, Y     float32
+	Scale    float32
+	Centered core.Position
+}
+
+// minimapRect is the minimap area in HUD coordinates, y growing downward.
+type minimapRect struct {
+	x, y, w, h float32
+}
+
+// cameraCenter returns the board cell at the middle of the view.
+func cameraCenter() core.Position {
+	return core.Position{
+		R: int(camY + float32(rows)/(2*camScale)),
+		C: int(camX + float32(cols)/(2*camScale)),
+	}
+}
+
+// centerCameraOn moves the view so pos sits in its middle, keeping the zoom.
+func centerCameraOn(pos core.Position) {
+	camX = float32(pos.C) + 0.5 - float32(cols)/(2*camScale)
+	camY = float32(pos.R) + 0.5 - float32(rows)/(2*camScale)
+}
+
+func stopFollow(message string) {
+	if ctrlState.Follow.Kind == followNone {
+		return
+	}
+	ctrlState.Follow = cameraFollow{}
+	if message != "" {
+		ctrlState.LastGodMessage = message
+	}
+}
+
+// toggleFollow follows the selected bot, or else the selected colony, or
+// stops following.
+func toggleFollow() {
+	if ctrlState.Follow.Kind != followNone {
+		stopFollow("Follow off")
+		return
+	}
+	if brd != nil {
+		for _, id := range brd.ActiveBotIDs() {
+			if bot := brd.BotByID(id); bot != nil && bot.IsSelected {
+				ctrlState.Follow = cameraFollow{Kind: followBot, Bot: id}
+				ctrlState.LastGodMessage = "Following " + followLabel()
+				updateFollow()
+				return
+			}
+		}
+	}
+	if godActions != nil {
+		if colony := godActions.SelectedColony(); colony != nil {
+			followColonyCamera(colony)
+			return
+		}
+	}
+	ctrlState.LastGodMessage = "Select a bot or colony to follow"
+}
+
+func followColonyCamera(colony *core.Colony) {
+	ctrlState.Follow = cameraFollow{Kind: followColony, Colony: colony}
+	ctrlState.LastGodMessage = "Following " + followLabel()
+	updateFollow()
+}
+
+// updateFollow recenters the camera on the followed bot or colony, and stops
+// following once the target is gone.
+func updateFollow() {
+	follow := ctrlState.Follow
+	switch follow.Kind {
+	case followBot:
+		if brd == nil || brd.BotByID(follow.Bot) == nil || brd.BotCell(follow.Bot) < 0 {
+			stopFollow("Followed bot is gone")
+			return
+		}
+		cell := brd.BotCell(follow.Bot)
+		centerCameraOn(core.Position{R: cell / cols, C: cell % cols})
+	case followColony:
+		if len(follow.Colony.Members) == 0 {
+			stopFollow("Followed colony dissolved")
+			return
+		}
+		centerCameraOn(follow.Colony.Center)
+	}
+}
+
+func followLabel() string {
+	switch ctrlState.Follow.Kind {
+	case followBot:
+		return fmt.Sprintf("bot #%d", ctrlState.Follow.Bot)
+	case followColony:
+		colony := ctrlState.Follow.Colony
+		return fmt.Sprintf("colony R%d C%d", colony.Center.R, colony.Center.C)
+	}
+	return "off"
+}
+
+func saveBookmark(slot int) {
+	center := cameraCenter()
+	ctrlState.Bookmarks[slot] = cameraBookmark{
+		Set:      true,
+		X:        camX,
+		Y:        camY,
+		Scale:    camScale,
+		Centered: center,
+	}
+	ctrlState.LastGodMessage = fmt.Sprintf("Bookmark %d at R%d C%d", slot+1, center.R, center.C)
+}
+
+func recallBookmark(slot int) {
+	mark := ctrlState.Bookmarks[slot]
+	if !mark.Set {
+		ctrlState.LastGodMessage = fmt.Sprintf("Bookmark %d is empty (Ctrl+F%d saves)", slot+1, slot+1)
+		return
+	}
+	stopFollow("")
+	camX, camY, camScale = mark.X, mark.Y, mark.Scale
+	ctrlState.LastGodMessage = fmt.Sprintf("Bookmark %d: R%d C%d", slot+1, mark.Centered.R, mark.Centered.C)
+}
+
+// coloniesBySize lists colonies with live bots on the board, largest first;
+// ties go to the lower, then leftmost, center so the order is stable.
+func coloniesBySize(board *core.Board) []*core.Colony {
+	if board == nil {
+		return nil
+	}
+	counts := map[*core.Colony]int{}
+	var colonies []*core.Colony
+	for _, id := range board.ActiveBotIDs() {
+		bot := board.BotByID(id)
+		if bot == nil || bot.Colony == nil {
+			continue
+		}
+		if counts[bot.Colony] == 0 {
+			colonies = append(colonies, bot.Colony)
+		}
+		counts[bot.Colony]++
+	}
+	sort.Slice(colonies, func(i, j int) bool {
+		a, b := colonies[i], colonies[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		if a.Center.R != b.Center.R {
+			return a.Center.R < b.Center.R
+		}
+		return a.Center.C < b.Center.C
+	})
+	return colonies
+}
+
+// cycleColony follows the next colony by size, wrapping to the largest.
+func cycleColony() {
+	colonies := coloniesBySize(brd)
+	if len(colonies) == 0 {
+		ctrlState.LastGodMessage = "No active colonies"
+		return
+	}
+	i := ctrlState.ColonyCycle % len(colonies)
+	ctrlState.ColonyCycle = i + 1
+	followColonyCamera(colonies[i])
+	ctrlState.LastGodMessage = fmt.Sprintf("Colony %d/%d: %s", i+1, len(colonies), followLabel())
+}
+
+// minimapLayout puts the minimap in the bottom-right corner at half a pixel
+// per cell.
+func minimapLayout(winW, winH float32) minimapRect {
+	w, h := float32(cols)*minimapCellPx, float32(rows)*minimapCellPx
+	return minimapRect{x: winW - w - minimapMargin, y: winH - h - minimapMargin, w: w, h: h}
+}
+
+func (m minimapRect) contains(x, y float64) bool {
+	return float32(x) >= m.x && float32(x) < m.x+m.w && float32(y) >= m.y && float32(y) < m.y+m.h
+}
+
+// boardPos maps a HUD point inside the minimap to the cell under it. Board
+// row 0 is at the bottom, as on the main view.
+func (m minimapRect) boardPos(x, y float64) (core.Position, bool) {
+	if !m.contains(x, y) {
+		return core.Position{}, false
+	}
+	col := int((float32(x) - m.x) / m.w * float32(cols))
+	row := int((m.y + m.h - float32(y)) / m.h * float32(rows))
+	return core.Position{R: min(row, rows-1), C: min(col, cols-1)}, true
+}
+
+// viewport returns the camera view outline in HUD coordinates, clipped to
+// the minimap.
+func (m minimapRect) viewport() (x, y, w, h float32) {
+	left := m.x + camX/float32(cols)*m.w
+	right := left + m.w/camScale
+	bottom := m.y + m.h - camY/float32(rows)*m.h
+	top := bottom - m.h/camScale
+	left, right = max(left, m.x), min(right, m.x+m.w)
+	top, bottom = max(top, m.y), min(bottom, m.y+m.h)
+	return left, top, max(0, right-left), max(0, bottom-top)
+}
+
+// jumpToMinimap centers the view on the cell under a minimap point.
+func jumpToMinimap(m minimapRect, x, y float64) bool {
+	pos, ok := m.boardPos(x, y)
+	if !ok {
+		return false
+	}
+	stopFollow("")
+	centerCameraOn(pos)
+	return true
+}
+
+var minimapChunks []DensityChunk
+
+func drawMinimap(winH float32, m minimapRect) {
+	drawPanel(winH, m.x-8, m.y-minimapHeader, m.w+16, m.h+minimapHeader+8, "MAP", hudBlue)
+	drawText(SmallFont, m.x+40, m.y-minimapHeader+8, hudMuted, "F %s  C next colony", trimOverlayText(followLabel(), 18))
+	drawRect(winH, m.x, m.y, m.w, m.h, hudRGBA{clrDefault[0], clrDefault[1], clrDefault[2], 0.95})
+
+	minimapChunks = buildDensityChunksInto(minimapChunks[:0], brd, DensityChunkSize, ctrlState.RenderMode)
+	chunk := float32(DensityChunkSize) * minimapCellPx
+	for _, c := range minimapChunks {
+		x := m.x + float32(c.Col)*minimapCellPx
+		y := m.y + m.h - float32(c.Row)*minimapCellPx - chunk
+		drawRect(winH, x, y, chunk, chunk, hudRGBA{c.Color[0], c.Color[1], c.Color[2], 1})
+	}
+	x, y, w, h := m.viewport()
+	drawRectBorder(winH, x, y, w, h, hudText)
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"golab/internal/core"
+	"testing"
+)
+
+func resetCameraForTest(t *testing.T) {
+	t.Helper()
+	prevX, prevY, prevScale := camX, camY, camScale
+	t.Cleanup(func() {
+		camX, camY, camScale = prevX, prevY, prevScale
+		brd = nil
+		ctrlState = ControlState{}
+	})
+	camX, camY, camScale = 0, 0, 2
+}
+
+func TestMinimapMapsClicksToCellsAndOutlinesTheView(t *testing.T) {
+	resetCameraForTest(t)
+	m := minimapLayout(1600, 900)
+	if m.x+m.w != 1600-minimapMargin || m.y+m.h != 900-minimapMargin || m.w != cols*minimapCellPx || m.h != rows*minimapCellPx {
+		t.Fatalf("minimap = %+v, want bottom-right corner at %v px per cell", m, minimapCellPx)
+	}
+
+	// Board row 0 is the bottom edge of the minimap.
+	pos, ok := m.boardPos(float64(m.x)+100.2, float64(m.y+m.h)-20.2)
+	if !ok || pos != (core.Position{R: 40, C: 200}) {
+		t.Fatalf("boardPos = %v %v, want R40 C200", pos, ok)
+	}
+	if _, ok := m.boardPos(float64(m.x)-1, float64(m.y)); ok {
+		t.Fatal("point left of the minimap mapped to a cell")
+	}
+
+	if !jumpToMinimap(m, float64(m.x)+150.2, float64(m.y+m.h)-100.2) {
+		t.Fatal("jumpToMinimap ignored a click inside the minimap")
+	}
+	if got := cameraCenter(); got != (core.Position{R: 200, C: 300}) {
+		t.Fatalf("camera center after jump = %v, want R200 C300", got)
+	}
+	x, y, w, h := m.viewport()
+	if w != m.w/2 || h != m.h/2 || x != m.x+150.25-m.w/4 || y != m.y+m.h-100.25-m.h/4 {
+		t.Fatalf("viewport = %v,%v %vx%v, want a half-size box centered on the jump", x, y, w, h)
+	}
+
+	centerCameraOn(core.Position{R: 0, C: 0})
+	x, y, w, h = m.viewport()
+	if x != m.x || y+h != m.y+m.h || w != m.w/4+0.25 || h != m.h/4+0.25 {
+		t.Fatalf("corner viewport = %v,%v %vx%v, want it clipped to the minimap", x, y, w, h)
+	}
+}
+
+func TestCameraFollowsBotUntilItDies(t *testing.T) {
+	resetCameraForTest(t)
+	brd = core.NewBoard()
+	start, next := core.Position{R: 50, C: 60}, core.Position{R: 51, C: 60}
+	bot := core.NewBot(start)
+	brd.AddBot(start, &bot)
+	bot.IsSelected = true
+
+	toggleFollow()
+	if ctrlState.Follow.Kind != followBot || cameraCenter() != start {
+		t.Fatalf("follow = %+v center = %v, want selected bot at %v", ctrlState.Follow, cameraCenter(), start)
+	}
+	if !brd.MoveBot(start, next, &bot) {
+		t.Fatal("MoveBot returned false")
+	}
+	updateFollow()
+	if got := cameraCenter(); got != next {
+		t.Fatalf("camera center after move = %v, want %v", got, next)
+	}
+
+	brd.RemoveBotAt(next)
+	updateFollow()
+	if ctrlState.Follow.Kind != followNone || ctrlState.LastGodMessage != "Followed bot is gone" {
+		t.Fatalf("follow after death = %+v message %q", ctrlState.Follow, ctrlState.LastGodMessage)
+	}
+	if got := cameraCenter(); got != next {
+		t.Fatalf("camera moved after the bot died: %v", got)
+	}
+}
+
+func TestBookmarksRestoreViewAndColonyCycleGoesBySize(t *testing.T) {
+	resetCameraForTest(t)
+	brd = core.NewBoard()
+	small, large := core.NewColony(core.Position{R: 20, C: 20}), core.NewColony(core.Position{R: 200, C: 300})
+	for i, colony := range []*core.Colony{&small, &large, &large} {
+		pos := core.Position{R: colony.Center.R + 1, C: colony.Center.C + i}
+		bot := core.NewBot(pos)
+		bot.Colony = colony
+		colony.Members = append(colony.Members, &bot)
+		brd.AddBot(pos, &bot)
+	}
+
+	cycleColony()
+	if ctrlState.Follow.Colony != &large || cameraCenter() != large.Center {
+		t.Fatalf("first cycle follows %+v at %v, want the larger colony", ctrlState.Follow, cameraCenter())
+	}
+	saveBookmark(2)
+	cycleColony()
+	if ctrlState.Follow.Colony != &small || cameraCenter() != small.Center {
+		t.Fatalf("second cycle follows %+v at %v, want the smaller colony", ctrlState.Follow, cameraCenter())
+	}
+	cycleColony()
+	if ctrlState.Follow.Colony != &large {
+		t.Fatalf("third cycle follows %+v, want to wrap to the larger colony", ctrlState.Follow)
+	}
+
+	camScale = 3
+	centerCameraOn(small.Center)
+	recallBookmark(2)
+	if ctrlState.Follow.Kind != followNone || camScale != 2 || cameraCenter() != large.Center {
+		t.Fatalf("bookmark recall = follow %+v scale %v center %v", ctrlState.Follow, camScale, cameraCenter())
+	}
+	recallBookmark(4)
+	if ctrlState.LastGodMessage != "Bookmark 5 is empty (Ctrl+F5 saves)" {
+		t.Fatalf("empty bookmark message = %q", ctrlState.LastGodMessage)
+	}
+}
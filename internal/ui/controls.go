@@ -22,6 +22,14 @@ type ControlState struct {
 	InspectLines     []string
 	ResetRequested   bool
 	RenderMode       RenderMode
+	Follow           cameraFollow
+	Bookmarks        [cameraBookmarkCount]cameraBookmark
+	ColonyCycle      int
+	MinimapDragging  bool
+	RewindRequested  bool
+	RewindTarget     int
+	ScrubDragging    bool
+	ScrubTick        int
 }
 
 type RenderMode int
@@ -35,6 +43,12 @@ const (
 	RenderModeTask
 	RenderModeBiome
 	RenderModePheromone
+	RenderModeHeatBirths
+	RenderModeHeatDeaths
+	RenderModeHeatKills
+	RenderModeHeatRaids
+	RenderModeHeatPheromone
+	RenderModeHeatOccupancy
 	renderModeCount
 )
 
@@ -63,6 +77,8 @@ type GodActions interface {
 	SaveGenome(pos core.Position) GodReport
 	SaveMap() GodReport
 	SelectedColonyLabel() string
+	SelectedColony() *core.Colony
+	RewindTicks() []int
 }
 
 var godActions GodActions
@@ -89,6 +105,9 @@ func (m RenderMode) Label() string {
 		return "Biome"
 	case RenderModePheromone:
 		return "Pheromone"
+	case RenderModeHeatBirths, RenderModeHeatDeaths, RenderModeHeatKills, RenderModeHeatRaids, RenderModeHeatPheromone, RenderModeHeatOccupancy:
+		layer, _ := m.HeatLayer()
+		return "Heat: " + layer.String()
 	default:
 		return "Unknown"
 	}
@@ -107,7 +126,16 @@ func cycleRenderMode() {
 }
 
 func (m RenderMode) fullBoardMode() bool {
-	return m == RenderModeColony || m == RenderModeBiome || m == RenderModePheromone
+	_, heat := m.HeatLayer()
+	return m == RenderModeColony || m == RenderModeBiome || m == RenderModePheromone || heat
+}
+
+// HeatLayer reports which cumulative heatmap a heat mode shows.
+func (m RenderMode) HeatLayer() (core.HeatLayer, bool) {
+	if m < RenderModeHeatBirths || m > RenderModeHeatOccupancy {
+		return 0, false
+	}
+	return core.HeatLayer(m - RenderModeHeatBirths), true
 }
 
 func markBotCellsDirty() {
@@ -134,6 +162,8 @@ func MarkSimulationResetComplete() {
 	ctrlState.LastClickIdx = -1
 	ctrlState.MousePainting = false
 	ctrlState.InspectLines = nil
+	ctrlState.Follow = cameraFollow{}
+	ctrlState.ColonyCycle = 0
 	ctrlState.LastGodMessage = "Simulation reset"
 	dragging = false
 }
@@ -199,6 +229,21 @@ func highlightRect(brd *core.Board, fromIdx, toIdx int) {
 }
 
 func cursorPosCallback(w *glfw.Window, xpos, ypos float64) {
+	if ctrlState.ScrubDragging {
+		winW, winH := w.GetSize()
+		scrubTo(scrubLayout(float32(winW), float32(winH)), xpos, ypos, true)
+		return
+	}
+	if ctrlState.MinimapDragging {
+		winW, winH := w.GetSize()
+		jumpToMinimap(minimapLayout(float32(winW), float32(winH)), xpos, ypos)
+		return
+	}
+	if split.on && !dragging && !ctrlState.MousePainting {
+		winW, _ := w.GetSize()
+		pane, _ := splitPaneAt(winW, xpos)
+		focusSplitPane(pane)
+	}
 	if idx, ok := cursorBoardIdx(w, xpos, ypos); ok && idx != ctrlState.HoveredIdx {
 		if ctrlState.HoveredIdx >= 0 {
 			brd.MarkDirty(ctrlState.HoveredIdx)
@@ -214,17 +259,25 @@ func cursorPosCallback(w *glfw.Window, xpos, ypos float64) {
 	}
 
 	if dragging {
+		stopFollow("")
 		winW, winH := w.GetSize()
 		dx := xpos - dragStartX
 		dy := ypos - dragStartY
-		camX = camStartX - float32(dx)*float32(cols)/float32(winW)/camScale
+		camX = camStartX - float32(dx)*float32(cols)/float32(boardViewWidth(winW))/camScale
 		camY = camStartY + float32(dy)*float32(rows)/float32(winH)/camScale
 	}
 }
 
 func cursorBoardIdx(w *glfw.Window, xpos, ypos float64) (int, bool) {
 	winW, winH := w.GetSize()
-	cellPxX := float32(winW) / float32(cols) * camScale
+	if split.on {
+		pane, x := splitPaneAt(winW, xpos)
+		if pane != split.active {
+			return -1, false
+		}
+		xpos = x
+	}
+	cellPxX := float32(boardViewWidth(winW)) / float32(cols) * camScale
 	cellPxY := float32(winH) / float32(rows) * camScale
 	wx := camX + float32(xpos)/cellPxX
 	wy := camY + float32(float32(winH)-float32(ypos))/cellPxY
@@ -242,6 +295,14 @@ func mouseButtonCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Ac
 	}
 	switch action {
 	case glfw.Press:
+		winW, winH := w.GetSize()
+		if x, y := w.GetCursorPos(); jumpToMinimap(minimapLayout(float32(winW), float32(winH)), x, y) {
+			ctrlState.MinimapDragging = true
+			return
+		}
+		if x, y := w.GetCursorPos(); scrubTo(scrubLayout(float32(winW), float32(winH)), x, y, false) {
+			return
+		}
 		if ctrlState.LeftShiftPressed {
 			dragging = true
 		}
@@ -260,6 +321,15 @@ func mouseButtonCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Ac
 			applyGodToolAtHover()
 		}
 	case glfw.Release:
+		if ctrlState.MinimapDragging {
+			ctrlState.MinimapDragging = false
+			return
+		}
+		if ctrlState.ScrubDragging {
+			ctrlState.ScrubDragging = false
+			requestRewind(ctrlState.ScrubTick)
+			return
+		}
 		if ctrlState.HoveredIdx != -1 {
 			hoveredPos := util.PosOf(ctrlState.HoveredIdx)
 
@@ -449,6 +519,18 @@ func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action,
 			saveMap()
 		case glfw.KeyV:
 			cycleRenderMode()
+		case glfw.KeyF:
+			toggleFollow()
+		case glfw.KeyC:
+			cycleColony()
+		case glfw.KeyComma:
+			stepRewindBack()
+		case glfw.KeyF1, glfw.KeyF2, glfw.KeyF3, glfw.KeyF4, glfw.KeyF5, glfw.KeyF6, glfw.KeyF7, glfw.KeyF8, glfw.KeyF9:
+			if mods&glfw.ModControl != 0 {
+				saveBookmark(int(key - glfw.KeyF1))
+			} else {
+				recallBookmark(int(key - glfw.KeyF1))
+			}
 		case glfw.KeyEscape:
 			w.SetShouldClose(true)
 		}
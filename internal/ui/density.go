@@ -21,6 +21,11 @@ func BuildDensityChunks(brd *core.Board, chunkSize int) []DensityChunk {
 	return buildDensityChunksInto(nil, brd, chunkSize, RenderModeNormal)
 }
 
+// BuildModeDensityChunks is BuildDensityChunks with bots colored as in mode.
+func BuildModeDensityChunks(brd *core.Board, chunkSize int, mode RenderMode) []DensityChunk {
+	return buildDensityChunksInto(nil, brd, chunkSize, mode)
+}
+
 func buildDensityChunksInto(out []DensityChunk, brd *core.Board, chunkSize int, mode RenderMode) []DensityChunk {
 	out = out[:0]
 	if brd == nil {
@@ -2,19 +2,22 @@ package ui
 
 import (
 	"golab/internal/core"
+	"golab/internal/util"
 	"testing"
 )
 
 func TestRenderModeLabels(t *testing.T) {
 	cases := map[RenderMode]string{
-		RenderModeNormal:    "Normal",
-		RenderModeGenome:    "Genome",
-		RenderModeHealth:    "Health",
-		RenderModeInventory: "Inventory",
-		RenderModeColony:    "Colony",
-		RenderModeTask:      "Task",
-		RenderModeBiome:     "Biome",
-		RenderModePheromone: "Pheromone",
+		RenderModeNormal:        "Normal",
+		RenderModeGenome:        "Genome",
+		RenderModeHealth:        "Health",
+		RenderModeInventory:     "Inventory",
+		RenderModeColony:        "Colony",
+		RenderModeTask:          "Task",
+		RenderModeBiome:         "Biome",
+		RenderModePheromone:     "Pheromone",
+		RenderModeHeatDeaths:    "Heat: deaths",
+		RenderModeHeatOccupancy: "Heat: occupancy",
 	}
 	for mode, want := range cases {
 		if got := mode.Label(); got != want {
@@ -44,6 +47,15 @@ func TestCycleBiomeRenderModeMarksAllCellsDirty(t *testing.T) {
 	}
 	assertAllCellsDirty(t, brd)
 
+	for mode := RenderModeHeatBirths; mode <= RenderModeHeatOccupancy; mode++ {
+		brd.PullPatch()
+		cycleRenderMode()
+		if ctrlState.RenderMode != mode {
+			t.Fatalf("render mode = %s, want %s", ctrlState.RenderMode.Label(), mode.Label())
+		}
+		assertAllCellsDirty(t, brd)
+	}
+
 	brd.PullPatch()
 	cycleRenderMode()
 	if ctrlState.RenderMode != RenderModeNormal {
@@ -314,3 +326,30 @@ func seedBenchmarkDensityBots(tb testing.TB, brd *core.Board, target int) {
 		tb.Fatalf("seeded %d density bots, want %d", seeded, target)
 	}
 }
+
+func TestHeatRenderModesShowCumulativeLayers(t *testing.T) {
+	board := core.NewBoard()
+	board.EnableHeat()
+	hot := util.NewPos(3, 3)
+	board.AddHeat(core.HeatKills, util.Idx(hot), 4)
+
+	for _, mode := range RenderModes() {
+		layer, ok := mode.HeatLayer()
+		if !ok {
+			continue
+		}
+		if !mode.fullBoardMode() {
+			t.Fatalf("%s should redraw the full board", mode.Label())
+		}
+		color := CellColor(board, hot, mode)
+		if layer == core.HeatKills && color != heatColor(1) {
+			t.Fatalf("kill heat color = %v, want hottest ramp color", color)
+		}
+		if layer != core.HeatKills && color == heatColor(1) {
+			t.Fatalf("%s drew kill heat", mode.Label())
+		}
+	}
+	if _, ok := RenderModePheromone.HeatLayer(); ok {
+		t.Fatal("pheromone mode reported a heat layer")
+	}
+}
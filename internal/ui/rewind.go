@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	scrubBarHeight = 14
+	scrubPanelGap  = 14
+	scrubMinWidth  = 120
+)
+
+// scrubRect is the rewind bar in HUD coordinates, y growing downward. It
+// spans from the oldest retained snapshot on the left to the current tick on
+// the right.
+type scrubRect struct {
+	x, y, w, h float32
+}
+
+// scrubLayout puts the bar along the bottom edge, left of the minimap.
+func scrubLayout(winW, winH float32) scrubRect {
+	m := minimapLayout(winW, winH)
+	x := float32(minimapMargin + 16)
+	right := m.x - 8 - scrubPanelGap - 16
+	return scrubRect{
+		x: x,
+		y: winH - minimapMargin - 8 - scrubBarHeight,
+		w: max(scrubMinWidth, right-x),
+		h: scrubBarHeight,
+	}
+}
+
+func (s scrubRect) contains(x, y float64) bool {
+	return float32(x) >= s.x && float32(x) < s.x+s.w && float32(y) >= s.y && float32(y) < s.y+s.h
+}
+
+// tickAt returns the newest retained tick at or before the tick under x,
+// clamped to the oldest one. ticks must be sorted and non-empty.
+func (s scrubRect) tickAt(x float64, ticks []int, current int) int {
+	oldest := ticks[0]
+	span := max(1, current-oldest)
+	frac := min(max((float32(x)-s.x)/s.w, 0), 1)
+	at := oldest + int(frac*float32(span))
+	i := sort.SearchInts(ticks, at+1) - 1
+	return ticks[max(i, 0)]
+}
+
+func (s scrubRect) xOf(tick int, ticks []int, current int) float32 {
+	oldest := ticks[0]
+	span := max(1, current-oldest)
+	return s.x + float32(tick-oldest)/float32(span)*s.w
+}
+
+func rewindTicks() []int {
+	if godActions == nil {
+		return nil
+	}
+	return godActions.RewindTicks()
+}
+
+func currentTick() int {
+	if gameState == nil {
+		return 0
+	}
+	return gameState.LogicTick
+}
+
+// scrubTo previews the retained tick under a bar point while dragging.
+func scrubTo(s scrubRect, x, y float64, dragging bool) bool {
+	if !dragging && !s.contains(x, y) {
+		return false
+	}
+	ticks := rewindTicks()
+	if len(ticks) == 0 {
+		ctrlState.LastGodMessage = "No snapshots yet"
+		return true
+	}
+	ctrlState.ScrubDragging = true
+	ctrlState.ScrubTick = s.tickAt(x, ticks, currentTick())
+	return true
+}
+
+func requestRewind(tick int) {
+	ctrlState.RewindRequested = true
+	ctrlState.RewindTarget = tick
+	ctrlState.LastGodMessage = fmt.Sprintf("Rewind queued to tick %d", tick)
+}
+
+// stepRewindBack queues a rewind to the newest snapshot before the current
+// tick.
+func stepRewindBack() {
+	ticks := rewindTicks()
+	if len(ticks) == 0 {
+		ctrlState.LastGodMessage = "No snapshots yet"
+		return
+	}
+	i := sort.SearchInts(ticks, currentTick()) - 1
+	if i < 0 {
+		ctrlState.LastGodMessage = fmt.Sprintf("Oldest snapshot is tick %d", ticks[0])
+		return
+	}
+	requestRewind(ticks[i])
+}
+
+func ConsumeRewindRequest() (int, bool) {
+	if !ctrlState.RewindRequested {
+		return 0, false
+	}
+	ctrlState.RewindRequested = false
+	return ctrlState.RewindTarget, true
+}
+
+// MarkRewindComplete drops UI state that points into the replaced board.
+// Bot follow survives: bot IDs are kept across a rewind.
+func MarkRewindComplete(tick int) {
+	ctrlState.LastClickIdx = -1
+	ctrlState.MousePainting = false
+	ctrlState.InspectLines = nil
+	if ctrlState.Follow.Kind == followColony {
+		stopFollow("")
+	}
+	ctrlState.ColonyCycle = 0
+	ctrlState.LastGodMessage = fmt.Sprintf("Rewound to tick %d", tick)
+	dragging = false
+}
+
+func drawScrubBar(winH float32, s scrubRect) {
+	drawPanel(winH, s.x-16, s.y-minimapHeader-8, s.w+32, s.h+minimapHeader+16, "REWIND", hudOrange)
+	drawRect(winH, s.x, s.y, s.w, s.h, hudDim)
+
+	ticks := rewindTicks()
+	if len(ticks) == 0 {
+		drawText(SmallFont, s.x+80, s.y-minimapHeader, hudMuted, "no snapshots yet")
+		return
+	}
+	current := currentTick()
+	for _, tick := range ticks {
+		drawRect(winH, s.xOf(tick, ticks, current), s.y, 2, s.h, hudMuted)
+	}
+	drawRect(winH, s.x+s.w-3, s.y-2, 3, s.h+4, hudGreen)
+	label := fmt.Sprintf(", back  %d..%d", ticks[0], current)
+	if ctrlState.ScrubDragging {
+		drawRect(winH, s.xOf(ctrlState.ScrubTick, ticks, current)-1, s.y-3, 4, s.h+6, hudOrange)
+		label = fmt.Sprintf("release to rewind to tick %d", ctrlState.ScrubTick)
+	}
+	drawText(SmallFont, s.x+80, s.y-minimapHeader, hudMuted, "%s", label)
+}
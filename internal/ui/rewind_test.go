@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"golab/internal/config"
+	"golab/internal/core"
+	"testing"
+)
+
+type rewindActions struct {
+	GodActions
+	ticks []int
+}
+
+func (a rewindActions) RewindTicks() []int { return a.ticks }
+
+func TestScrubBarMapsToRetainedTicksAndQueuesRewinds(t *testing.T) {
+	resetCameraForTest(t)
+	prevActions, prevState := godActions, gameState
+	t.Cleanup(func() { godActions, gameState = prevActions, prevState })
+	godActions = rewindActions{ticks: []int{100, 200, 300, 400}}
+	gameState = &config.GameState{LogicTick: 500}
+
+	s := scrubLayout(1600, 900)
+	m := minimapLayout(1600, 900)
+	if s.x+s.w >= m.x-8 || s.y+s.h > 900-minimapMargin {
+		t.Fatalf("scrub bar %+v overlaps the minimap %+v", s, m)
+	}
+	for _, tc := range []struct {
+		frac float32
+		want int
+	}{{0, 100}, {0.2, 100}, {0.26, 200}, {0.74, 300}, {0.76, 400}, {1, 400}} {
+		if got := s.tickAt(float64(s.x+tc.frac*s.w), godActions.RewindTicks(), 500); got != tc.want {
+			t.Fatalf("tickAt(%.2f) = %d, want %d", tc.frac, got, tc.want)
+		}
+	}
+
+	y := float64(s.y + 1)
+	if scrubTo(s, float64(s.x-1), y, false) {
+		t.Fatal("press left of the bar started a scrub")
+	}
+	if !scrubTo(s, float64(s.x+s.w/2), y, false) || !ctrlState.ScrubDragging || ctrlState.ScrubTick != 300 {
+		t.Fatalf("scrub press = dragging %v tick %d, want tick 300", ctrlState.ScrubDragging, ctrlState.ScrubTick)
+	}
+	scrubTo(s, float64(s.x-50), y-40, true)
+	if ctrlState.ScrubTick != 100 {
+		t.Fatalf("dragging past the left edge = tick %d, want the oldest snapshot", ctrlState.ScrubTick)
+	}
+
+	stepRewindBack()
+	if tick, ok := ConsumeRewindRequest(); !ok || tick != 400 {
+		t.Fatalf("step back from 500 = %d %v, want 400", tick, ok)
+	}
+	if _, ok := ConsumeRewindRequest(); ok {
+		t.Fatal("rewind request was consumed twice")
+	}
+	gameState.LogicTick = 400
+	stepRewindBack()
+	if tick, _ := ConsumeRewindRequest(); tick != 300 {
+		t.Fatalf("step back from a snapshot tick = %d, want the one before it", tick)
+	}
+	gameState.LogicTick = 100
+	stepRewindBack()
+	if _, ok := ConsumeRewindRequest(); ok || ctrlState.LastGodMessage != "Oldest snapshot is tick 100" {
+		t.Fatalf("step back past the oldest snapshot queued a rewind: %q", ctrlState.LastGodMessage)
+	}
+
+	colony := core.NewColony(core.Position{R: 10, C: 10})
+	ctrlState.Follow = cameraFollow{Kind: followColony, Colony: &colony}
+	MarkRewindComplete(300)
+	if ctrlState.Follow.Kind != followNone || ctrlState.LastGodMessage != "Rewound to tick 300" {
+		t.Fatalf("after rewind follow = %+v message %q", ctrlState.Follow, ctrlState.LastGodMessage)
+	}
+	ctrlState.Follow = cameraFollow{Kind: followBot, Bot: 7}
+	MarkRewindComplete(300)
+	if ctrlState.Follow.Kind != followBot {
+		t.Fatal("rewind dropped a bot follow; bot IDs survive rewinds")
+	}
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"golab/internal/config"
+	"golab/internal/core"
+
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// SplitPane is one side of the split-screen window.
+type SplitPane struct {
+	Label   string
+	Board   *core.Board
+	State   *config.GameState
+	Actions GodActions
+}
+
+// paneLayer holds the board state of the split pane that is not active. The
+// active pane lives in the package globals, so drawing and controls work on
+// either side unchanged once the two are swapped.
+type paneLayer struct {
+	board        *core.Board
+	state        *config.GameState
+	actions      GodActions
+	vboStatic    uint32
+	vboDynamic   uint32
+	vertsStat    []v
+	vertsDyn     []v
+	dynVertCount int
+	densityMode  bool
+	hovered      int
+}
+
+var split struct {
+	on     bool
+	active int
+	labels [2]string
+	other  paneLayer
+}
+
+// SetSplitPanes shows left and right side by side under one camera. The
+// controls act on the pane under the cursor. Call it again whenever a reset or
+// rewind replaces a board.
+func SetSplitPanes(left, right SplitPane) {
+	if !split.on {
+		split.on = true
+		split.other.hovered = -1
+		withSplitPane(1, initDynamicLayer)
+	}
+	for i, pane := range [2]SplitPane{left, right} {
+		split.labels[i] = pane.Label
+		withSplitPane(i, func() {
+			SetBoard(pane.Board)
+			SetGameState(pane.State)
+			SetGodActions(pane.Actions)
+			BuildStaticLayer(pane.Board)
+		})
+	}
+}
+
+// DrawSplit draws both panes, each in its half of the window, and the HUD of
+// the active one.
+func DrawSplit() {
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	updateFollow()
+	pxW, pxH := Window.GetFramebufferSize()
+	half := int32(pxW / 2)
+	for i := range 2 {
+		withSplitPane(i, func() {
+			gl.Viewport(int32(i)*half, 0, half, int32(pxH))
+			ApplyCamera()
+			drawBoard(brd)
+		})
+	}
+	gl.Viewport(0, 0, int32(pxW), int32(pxH))
+
+	drawOverlay()
+	winW, winH := AppWindow.GetSize()
+	beginHUD(winW, winH)
+	drawSplitFrame(float32(winW), float32(winH))
+	endHUD()
+	Window.SwapBuffers()
+	glfw.PollEvents()
+}
+
+// drawSplitFrame marks the divider, outlines the active pane and labels each
+// side with its run, live bots and tick.
+func drawSplitFrame(winW, winH float32) {
+	half := float32(int(winW) / 2)
+	drawRect(winH, half-1, 0, 2, winH, hudDim)
+	drawRectBorder(winH, float32(split.active)*half+1, 1, half-2, winH-2, hudOrange)
+	for i := range 2 {
+		withSplitPane(i, func() {
+			x := float32(i)*half + 16
+			drawText(SmallFont, x, winH-72, hudText, "%s  live %d  tick %d",
+				split.labels[i], brd.ActiveBotCount(), currentTick())
+		})
+	}
+}
+
+// withSplitPane runs fn with pane i in the package globals.
+func withSplitPane(i int, fn func()) {
+	if i == split.active {
+		fn()
+		return
+	}
+	swapSplitPane()
+	defer swapSplitPane()
+	fn()
+}
+
+// focusSplitPane moves the controls to pane i, dropping the hover, click and
+// follow state that belongs to the other board.
+func focusSplitPane(i int) {
+	if !split.on || i == split.active {
+		return
+	}
+	if ctrlState.HoveredIdx >= 0 {
+		brd.MarkDirty(ctrlState.HoveredIdx)
+		ctrlState.HoveredIdx = -1
+	}
+	ctrlState.LastClickIdx = -1
+	ctrlState.InspectLines = nil
+	stopFollow("")
+	swapSplitPane()
+}
+
+func swapSplitPane() {
+	o := &split.other
+	brd, o.board = o.board, brd
+	gameState, o.state = o.state, gameState
+	godActions, o.actions = o.actions, godActions
+	vboStatic, o.vboStatic = o.vboStatic, vboStatic
+	vboDynamic, o.vboDynamic = o.vboDynamic, vboDynamic
+	vertsStat, o.vertsStat = o.vertsStat, vertsStat
+	vertsDyn, o.vertsDyn = o.vertsDyn, vertsDyn
+	dynVertCount, o.dynVertCount = o.dynVertCount, dynVertCount
+	lastDensityMode, o.densityMode = o.densityMode, lastDensityMode
+	ctrlState.HoveredIdx, o.hovered = o.hovered, ctrlState.HoveredIdx
+	split.active = 1 - split.active
+}
+
+// splitPaneAt returns the pane under window x and x within that pane.
+func splitPaneAt(winW int, x float64) (int, float64) {
+	half := float64(winW / 2)
+	if x < half {
+		return 0, x
+	}
+	return 1, x - half
+}
+
+// boardViewWidth is the window width one board is drawn across.
+func boardViewWidth(winW int) int {
+	if split.on {
+		return winW / 2
+	}
+	return winW
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"golab/internal/config"
+	"golab/internal/core"
+	"testing"
+)
+
+func TestSplitPaneFocusSwapsBoardsAndMapsCursor(t *testing.T) {
+	t.Cleanup(func() {
+		split.on, split.active, split.other = false, 0, paneLayer{}
+		brd, gameState = nil, nil
+		ctrlState = ControlState{}
+	})
+	left, right := core.NewBoard(), core.NewBoard()
+	leftState, rightState := &config.GameState{LogicTick: 3}, &config.GameState{LogicTick: 4}
+	brd, gameState = left, leftState
+	split.on = true
+	split.other = paneLayer{board: right, state: rightState, hovered: -1}
+	ctrlState.HoveredIdx = 42
+
+	if pane, x := splitPaneAt(1600, 799); pane != 0 || x != 799 {
+		t.Fatalf("splitPaneAt(799) = %d, %v, want left pane at 799", pane, x)
+	}
+	if pane, x := splitPaneAt(1600, 900); pane != 1 || x != 100 {
+		t.Fatalf("splitPaneAt(900) = %d, %v, want right pane at 100", pane, x)
+	}
+	if got := boardViewWidth(1600); got != 800 {
+		t.Fatalf("boardViewWidth = %d, want half the window", got)
+	}
+
+	left.MarkClean(42)
+	focusSplitPane(1)
+	if brd != right || gameState != rightState || split.active != 1 {
+		t.Fatal("focusing the right pane left the left board active")
+	}
+	if ctrlState.HoveredIdx != -1 || !left.DirtyBitmap()[42] {
+		t.Fatal("left hover was not cleared and redrawn when focus moved")
+	}
+
+	var drawn []int
+	for i := range 2 {
+		withSplitPane(i, func() { drawn = append(drawn, currentTick()) })
+	}
+	if drawn[0] != 3 || drawn[1] != 4 || brd != right {
+		t.Fatalf("withSplitPane saw ticks %v and left board active = %v, want [3 4] with right active", drawn, brd == left)
+	}
+}
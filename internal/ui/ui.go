@@ -2,11 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"golab/internal/assets"
 	"golab/internal/config"
 	"golab/internal/core"
 	"golab/internal/util"
 	"image"
-	"os"
+	"strings"
 
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -105,7 +106,7 @@ func BuildStaticLayer(brd *core.Board) {
 	vertsStat = make([]v, maxVerts)
 	statPos := 0
 
-	for idx, occ := range *brd.GetGrid() {
+	for idx, occ := range brd.Occupants() {
 		pos := core.Position{R: idx / core.Cols, C: idx % core.Cols}
 		writeQuad(vertsDyn, idx*vPerQuad, pos, clrDefault, uvEmpty)
 		col, uv := pickSprite(occ, idx)
@@ -136,7 +137,7 @@ func writeRectQuad(buf []v, base int, x, y, w, h float32, col [3]float32, uv [4]
 func mayVanish(o core.Occupant) bool {
 	switch o.(type) {
 	case *core.Bot, core.Food, core.Resource, core.Poison,
-		core.Organics, core.Farm, core.Controller, *core.Controller, core.Depot, *core.Depot, core.Spawner, core.Mine, core.ColonyFlag:
+		core.Organics, core.Farm, core.Controller, core.Depot, core.Spawner, core.Mine, core.ColonyFlag:
 		return true
 	default:
 		return false
@@ -209,23 +210,22 @@ func PrepareUi() {
 	gl.Enable(gl.BLEND)
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 
-	const fontPath = "/usr/share/fonts/truetype/msttcorefonts/Arial.ttf"
-	Font = LoadFont(fontPath, 20)
-	SmallFont = LoadFont(fontPath, 16)
-	const path = "/home/alice/projects/golab/assests/sprites/"
-	BotTexture = loadTexture(path + "bot.jpg")
-	atlasTex = loadTexture(path + "atlas.png")
-
-	vertsDyn = make([]v, maxVerts)
-	for idx := range maxCells {
-		p := core.Position{R: idx / core.Cols, C: idx % core.Cols}
-		writeQuad(vertsDyn, idx*vPerQuad, p, clrDefault, uvEmpty)
+	Font = LoadFont(assets.Font, 20)
+	SmallFont = LoadFont(assets.Font, 16)
+	botImg, err := assets.Image(assets.Bot)
+	if err != nil {
+		panic(err)
 	}
-	dynVertCount = len(vertsDyn)
+	BotTexture = loadTexture(botImg)
+	atlas, tileSize, err := assets.LoadAtlas()
+	if err != nil {
+		panic(err)
+	}
+	// The UV table spans exactly assets.AtlasTiles tiles; extra pack tiles
+	// are left out of the texture.
+	atlasTex = loadTexture(atlas.SubImage(image.Rect(0, 0, assets.AtlasTiles*tileSize, tileSize)))
 
-	gl.GenBuffers(1, &vboDynamic)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vboDynamic)
-	gl.BufferData(gl.ARRAY_BUFFER, len(vertsDyn)*int(stride), gl.Ptr(vertsDyn), gl.DYNAMIC_DRAW)
+	initDynamicLayer()
 
 	vertsDensity = make([]v, maxDensityChunks*vPerQuad)
 	densityChunks = make([]DensityChunk, 0, maxDensityChunks)
@@ -245,18 +245,22 @@ func PrepareUi() {
 	Window = window
 }
 
-func loadTexture(filename string) uint32 {
-	file, err := os.Open(filename)
-	if err != nil {
-		panic(err)
+// initDynamicLayer allocates the per-cell vertices and buffer a board's
+// changing cells are drawn into.
+func initDynamicLayer() {
+	vertsDyn = make([]v, maxVerts)
+	for idx := range maxCells {
+		p := core.Position{R: idx / core.Cols, C: idx % core.Cols}
+		writeQuad(vertsDyn, idx*vPerQuad, p, clrDefault, uvEmpty)
 	}
-	defer file.Close()
+	dynVertCount = len(vertsDyn)
 
-	img, _, err := image.Decode(file)
-	if err != nil {
-		panic(err)
-	}
+	gl.GenBuffers(1, &vboDynamic)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vboDynamic)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertsDyn)*int(stride), gl.Ptr(vertsDyn), gl.DYNAMIC_DRAW)
+}
 
+func loadTexture(img image.Image) uint32 {
 	bounds := img.Bounds()
 	w, h := bounds.Dx(), bounds.Dy()
 
@@ -319,8 +323,9 @@ func textAtWorld(wx, wy float32, s string) {
 	gl.MatrixMode(gl.MODELVIEW)
 }
 
+// LoadFont loads the TrueType font asset name at size.
 func LoadFont(name string, size int) *gltext.Font {
-	f, err := os.Open(name)
+	f, err := assets.Open(name)
 	if err != nil {
 		panic(err)
 	}
@@ -369,16 +374,38 @@ func pickSprite(o core.Occupant, idx int) (color [3]float32, uv [4]float32) {
 			return util.YellowColor(), uvBot
 		}
 	}
-	if ctrlState.RenderMode == RenderModePheromone && brd != nil {
-		color, uv = pheromoneSprite(brd.PheromoneAt(pos))
-		if brd.IsFrozen(pos) {
+	return cellSprite(brd, o, pos, ctrlState.RenderMode)
+}
+
+// CellColor returns the color pos is drawn with in mode, without hover or tool
+// highlights, so viewers outside the GL window can match it.
+func CellColor(board *core.Board, pos core.Position, mode RenderMode) [3]float32 {
+	color, _ := cellSprite(board, board.At(pos), pos, mode)
+	return color
+}
+
+func RenderModes() []RenderMode {
+	modes := make([]RenderMode, 0, renderModeCount)
+	for mode := RenderModeNormal; mode < renderModeCount; mode++ {
+		modes = append(modes, mode)
+	}
+	return modes
+}
+
+func cellSprite(board *core.Board, o core.Occupant, pos core.Position, mode RenderMode) (color [3]float32, uv [4]float32) {
+	if mode == RenderModePheromone && board != nil {
+		color, uv = pheromoneSprite(board.PheromoneAt(pos))
+		if board.IsFrozen(pos) {
 			return frozenTint(color), uvLight
 		}
 		return color, uv
 	}
+	if layer, ok := mode.HeatLayer(); ok && board != nil {
+		return heatSprite(board.HeatIntensity(layer, pos))
+	}
 	switch o := o.(type) {
 	case *core.Bot:
-		color, uv = botRenderColor(o), uvBot
+		color, uv = botModeColor(o, mode), uvBot
 	case core.Food:
 		color, uv = [3]float32{1, 0, 0.8}, uvFood
 	case core.Water:
@@ -391,12 +418,8 @@ func pickSprite(o core.Occupant, idx int) (color [3]float32, uv [4]float32) {
 		color, uv = clrLight, uvWall
 	case core.Controller:
 		color, uv = clrLight, uvChest
-	case *core.Controller:
-		color, uv = clrLight, uvChest
 	case core.Depot:
 		color, uv = [3]float32{0.20, 0.95, 0.85}, uvChest
-	case *core.Depot:
-		color, uv = [3]float32{0.20, 0.95, 0.85}, uvChest
 	case core.Mine:
 		color, uv = clrLight, uvSpawner
 	case core.Resource:
@@ -412,30 +435,34 @@ func pickSprite(o core.Occupant, idx int) (color [3]float32, uv [4]float32) {
 	default:
 		color, uv = clrDefault, uvEmpty
 	}
-	if ctrlState.RenderMode == RenderModeColony && brd != nil {
-		color, uv = colonySprite(o, pos, color, uv)
+	if mode == RenderModeColony && board != nil {
+		color, uv = colonySprite(board, o, pos, color, uv)
 	}
-	if ctrlState.RenderMode == RenderModeBiome && brd != nil {
-		color, uv = biomeSprite(o, brd.BiomeAt(pos), color, uv)
+	if mode == RenderModeBiome && board != nil {
+		color, uv = biomeSprite(o, board.BiomeAt(pos), color, uv)
 	}
-	if brd != nil && brd.IsFrozen(pos) {
+	if board != nil && board.IsFrozen(pos) {
 		return frozenTint(color), uvLight
 	}
 	return color, uv
 }
 
 func botRenderColor(bot *core.Bot) [3]float32 {
+	return botModeColor(bot, ctrlState.RenderMode)
+}
+
+func botModeColor(bot *core.Bot, mode RenderMode) [3]float32 {
 	if bot == nil {
 		return clrDefault
 	}
-	if ctrlState.RenderMode == RenderModeNormal {
+	if mode == RenderModeNormal {
 		if bot.IsSelected {
 			return util.YellowColor()
 		}
 		return colonyBotColor(bot, bot.Color)
 	}
 
-	switch ctrlState.RenderMode {
+	switch mode {
 	case RenderModeGenome:
 		return genomeColor(bot.Genome)
 	case RenderModeHealth:
@@ -456,7 +483,7 @@ func botRenderColor(bot *core.Bot) [3]float32 {
 	}
 }
 
-func colonySprite(o core.Occupant, pos core.Position, color [3]float32, uv [4]float32) ([3]float32, [4]float32) {
+func colonySprite(board *core.Board, o core.Occupant, pos core.Position, color [3]float32, uv [4]float32) ([3]float32, [4]float32) {
 	switch v := o.(type) {
 	case *core.Bot:
 		return colonyModeBotColor(v), uvBot
@@ -464,25 +491,17 @@ func colonySprite(o core.Occupant, pos core.Position, color [3]float32, uv [4]fl
 		return [3]float32{0.02, 0.04, 0.10}, uvLight
 	case core.Controller:
 		return colonyStructureColor(v.Colony, color), uvChest
-	case *core.Controller:
-		if v != nil {
-			return colonyStructureColor(v.Colony, color), uvChest
-		}
 	case core.Depot:
 		return colonyStructureColor(v.Colony, color), uvChest
-	case *core.Depot:
-		if v != nil {
-			return colonyStructureColor(v.Colony, color), uvChest
-		}
 	case core.Farm:
 		return colonyStructureColor(colonyForOwnedCell(v.Colony, v.Owner), color), uvFarm
 	case core.Spawner:
 		return colonyStructureColor(colonyForOwner(v.Owner), color), uvSpawner
 	case core.ColonyFlag:
-		return colonyStructureColor(brd.PheromoneHomeOwnerAt(pos), color), uvFlag
+		return colonyStructureColor(board.PheromoneHomeOwnerAt(pos), color), uvFlag
 	case nil:
-		if owner := brd.PheromoneHomeOwnerAt(pos); owner != nil && brd.PheromoneAt(pos).Home > 0 {
-			return colonyTissueColor(owner.Color, brd.PheromoneAt(pos).Home), uvLight
+		if owner := board.PheromoneHomeOwnerAt(pos); owner != nil && board.PheromoneAt(pos).Home > 0 {
+			return colonyTissueColor(owner.Color, board.PheromoneAt(pos).Home), uvLight
 		}
 		return clrDefault, uvEmpty
 	}
@@ -605,6 +624,27 @@ func pheromoneColor(values core.PheromoneValues) [3]float32 {
 	}
 }
 
+func heatSprite(intensity float32) ([3]float32, [4]float32) {
+	if intensity <= 0 {
+		return [3]float32{0.03, 0.035, 0.035}, uvDark
+	}
+	return heatColor(intensity), uvLight
+}
+
+// heatColor runs from deep purple through red and orange to pale yellow.
+func heatColor(intensity float32) [3]float32 {
+	stops := [...][3]float32{
+		{0.16, 0.04, 0.30},
+		{0.58, 0.10, 0.45},
+		{0.93, 0.30, 0.14},
+		{1.00, 0.72, 0.16},
+		{1.00, 1.00, 0.80},
+	}
+	scaled := clamp01(intensity) * float32(len(stops)-1)
+	i := min(int(scaled), len(stops)-2)
+	return lerpColor(stops[i], stops[i+1], scaled-float32(i))
+}
+
 func genomeColor(genome core.Genome) [3]float32 {
 	var hash uint32 = 2166136261
 	for i, gene := range genome.Matrix {
@@ -723,8 +763,16 @@ func idx(p Position) int {
 
 func DrawGrid(brd *core.Board, bots []*core.Bot) {
 	gl.Clear(gl.COLOR_BUFFER_BIT)
+	updateFollow()
 	ApplyCamera()
+	drawBoard(brd)
+	drawOverlay()
+	Window.SwapBuffers()
+	glfw.PollEvents()
+}
 
+// drawBoard uploads brd's dirty cells and draws it under the current camera.
+func drawBoard(brd *core.Board) {
 	densityMode := useDensityRendering()
 	if densityMode != lastDensityMode {
 		brd.MarkAllDirty()
@@ -821,10 +869,6 @@ func DrawGrid(brd *core.Board, bots []*core.Bot) {
 	} else {
 		densityVertCount = 0
 	}
-
-	drawOverlay()
-	Window.SwapBuffers()
-	glfw.PollEvents()
 }
 
 func useDensityRendering() bool {
@@ -832,7 +876,7 @@ func useDensityRendering() bool {
 		return false
 	}
 	winW, _ := AppWindow.GetSize()
-	cellPx := float32(winW) / float32(cols) * camScale
+	cellPx := float32(boardViewWidth(winW)) / float32(cols) * camScale
 	return camScale <= 0.85 || cellPx < 3.0
 }
 
@@ -886,6 +930,8 @@ func drawOverlay() {
 	drawSimPanel(float32(winH), layout.simX, layout.simY)
 	drawWorldPanel(float32(winH), layout.worldX, layout.worldY, layout.worldW)
 	drawGodPanel(float32(winH), layout.godX, layout.godY, layout.godW)
+	drawMinimap(float32(winH), minimapLayout(float32(winW), float32(winH)))
+	drawScrubBar(float32(winH), scrubLayout(float32(winW), float32(winH)))
 	endHUD()
 }
 
@@ -1023,6 +1069,7 @@ func drawWorldPanel(winH, x, y, w float32) {
 	drawText(Font, x+16, y+34, hudText, "Live %d", conf.LiveBots)
 	if gameState != nil {
 		drawText(SmallFont, x+176, y+38, hudMuted, "Tick %d  %.1f TPS", gameState.LogicTick, gameState.LogicTicksPerSecond)
+		drawText(SmallFont, x+176, y+8, hudMuted, "%s", trimOverlayText(tickPhaseSummary(gameState, 3), int((w-190)/8)))
 	}
 	drawText(SmallFont, x+16, y+62, hudMuted, "View %s  %s", ctrlState.RenderMode.Label(), densityModeLabel())
 	renderGameMasterOverlay(winH, x+16, y+88, w-32)
@@ -1167,6 +1214,19 @@ func renderGameMasterOverlay(winH, x, y, w float32) {
 	drawText(SmallFont, x, y+98, hudMuted, "%s", trimOverlayText(gm.LastThought, int(w/8)))
 }
 
+// tickPhaseSummary lists the n slowest tick phases, as "bots 3.1  environment
+// 0.8 ms".
+func tickPhaseSummary(state *config.GameState, n int) string {
+	var parts []string
+	for _, phase := range state.SlowestTickPhases(n) {
+		parts = append(parts, fmt.Sprintf("%s %.1f", phase.Name, phase.Millis))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "  ") + " ms"
+}
+
 func trimOverlayText(text string, maxLen int) string {
 	if len(text) <= maxLen {
 		return text
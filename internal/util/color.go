@@ -1,9 +1,7 @@
 package util
 
-import "math/rand"
-
 func RandomColor() [3]float32 {
-	return [3]float32{rand.Float32(), rand.Float32(), rand.Float32()}
+	return [3]float32{RandFloat32(), RandFloat32(), RandFloat32()}
 }
 func BlueColor() [3]float32 {
 	return [3]float32{0, 0, 1}
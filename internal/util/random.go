@@ -0,0 +1,111 @@
+package util
+
+import (
+	"math/rand"
+	"sync"
+
+	expRand "golang.org/x/exp/rand"
+)
+
+// Random is one simulation PRNG state: a math/rand stream for rules, bots and
+// genomes and an x/exp/rand stream for board placement. Seeded with s, it
+// draws exactly what the package-level math/rand and x/exp/rand functions
+// drew after rand.Seed(s) and expRand.Seed(s). Like theirs, its streams
+// take concurrent draws; which state is current is another matter, see
+// UseRandom.
+type Random struct {
+	math *rand.Rand
+	exp  *expRand.Rand
+}
+
+// NewRandom returns a PRNG state seeded with seed.
+func NewRandom(seed int64) *Random {
+	r := &Random{
+		math: rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)}),
+		exp:  expRand.New(&expRand.LockedSource{}),
+	}
+	r.exp.Seed(uint64(seed))
+	return r
+}
+
+// Seed restarts both streams from seed.
+func (r *Random) Seed(seed int64) {
+	r.math.Seed(seed)
+	r.exp.Seed(uint64(seed))
+}
+
+// random is the state the simulation draws from. Until something seeds it,
+// the math/rand stream starts from a random seed and the x/exp/rand stream
+// from 1, as the package-level functions do.
+var random = &Random{
+	math: rand.New(&lockedSource{src: rand.NewSource(rand.Int63()).(rand.Source64)}),
+	exp:  expRand.New(&expRand.LockedSource{}),
+}
+
+func init() {
+	random.exp.Seed(1)
+}
+
+// SeedRandom restarts the current PRNG state from seed.
+func SeedRandom(seed int64) {
+	random.Seed(seed)
+}
+
+// CurrentRandom returns the state the simulation currently draws from.
+func CurrentRandom() *Random {
+	return random
+}
+
+// UseRandom makes r the current PRNG state and returns the previous one, so
+// a process running several games can give each its own stream. The current
+// state is a process-wide global swapped without synchronization: games
+// stepped on different goroutines would draw from whichever state was swapped
+// in last, so games that own a Random must be stepped from one goroutine.
+func UseRandom(r *Random) *Random {
+	previous := random
+	random = r
+	return previous
+}
+
+// RandIntn is rand.Intn on the current math/rand stream.
+func RandIntn(n int) int {
+	return random.math.Intn(n)
+}
+
+// RandFloat32 is rand.Float32 on the current math/rand stream.
+func RandFloat32() float32 {
+	return random.math.Float32()
+}
+
+// BoardRandIntn is expRand.Intn on the current x/exp/rand stream.
+func BoardRandIntn(n int) int {
+	return random.exp.Intn(n)
+}
+
+// lockedSource guards a math/rand source like the package-level functions'
+// own source does; the parallel bot scheduler runs bots on several
+// goroutines.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	n := s.src.Int63()
+	s.mu.Unlock()
+	return n
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	n := s.src.Uint64()
+	s.mu.Unlock()
+	return n
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	s.src.Seed(seed)
+	s.mu.Unlock()
+}
@@ -2,7 +2,6 @@ package util
 
 import (
 	"math"
-	"math/rand"
 )
 
 const (
@@ -99,7 +98,7 @@ func (p Position) AddRowCol(dr, dc int) Position {
 }
 
 func RollChanceOf(total, percent int) bool {
-	return rand.Intn(total) < percent
+	return RandIntn(total) < percent
 }
 
 func PosOf(idx int) Position {
@@ -111,7 +110,7 @@ func Idx(p Position) int {
 }
 
 func RollChance(percent int) bool {
-	return rand.Intn(100) < percent
+	return RandIntn(100) < percent
 }
 
 var PosCross = [4][2]int{